@@ -761,6 +761,14 @@ func toStringFieldFormat(field parse.Field, pdu parse.PDUStruct) (string, []Code
 		}
 	}
 
+	if field.ToName != "" {
+		pkg, fn := splitQualified(field.ToName)
+		if pkg != "" {
+			return "%s", []Code{Qual(resolveImportPath(pkg, pdu.SourceFile), fn).Call(src)}
+		}
+		return "%s", []Code{Id(fn).Call(src)}
+	}
+
 	switch field.Kind {
 	case parse.FieldBool:
 		return "%t", []Code{src}
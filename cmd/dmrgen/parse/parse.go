@@ -76,6 +76,10 @@ type Field struct {
 	// NoEncode fields are decoded (e.g. for when: guard conditions) but skipped during encode
 	// because the sub-PDU's encode already writes the overlapping bits.
 	NoEncode bool
+
+	// ToName names the function ToString formats the field with, for a
+	// field decoded as its raw value: name:enums.FeatureSetIDToName
+	ToName string
 }
 
 // FECDirective describes a struct-level FEC pre-processing step.
@@ -364,6 +368,8 @@ func parseTag(fieldName, tag string, fieldType ast.Expr) (Field, error) {
 			default:
 				return f, fmt.Errorf("unknown semantic type: %q", semType)
 			}
+		case strings.HasPrefix(mod, "name:"):
+			f.ToName = strings.TrimPrefix(mod, "name:")
 		case strings.HasPrefix(mod, "from:"):
 			// Explicit FromInt function: from:enums.LCSSFromInt
 			f.EnumFromInt = strings.TrimPrefix(mod, "from:")
@@ -55,3 +55,20 @@ func CheckCRC9(bits []bit.Bit, mask uint16) bool {
 
 	return crc == expected
 }
+
+// Data type CRC-9 masks for confirmed data blocks (B.3.12, Table B.21).
+const (
+	CRC9MaskRate12Data = uint16(0x0F0)
+	CRC9MaskRate34Data = uint16(0x1FF)
+	CRC9MaskRate1Data  = uint16(0x10F)
+)
+
+// CalculateDataBlockCRC9 computes the masked CRC-9 of a confirmed data block.
+// M(x) is the block's user data octets (MSB first) followed by its 7-bit
+// Data Block Serial Number.
+func CalculateDataBlockCRC9(serialNumber uint8, data []byte, mask uint16) uint16 {
+	bits := make([]bit.Bit, 0, len(data)*8+7)
+	bits = append(bits, bit.UnpackBits(data)...)
+	bits = append(bits, bit.BitsFromUint8(serialNumber, 7)...)
+	return (CalculateCRC9(bits) ^ mask) & 0x1FF
+}
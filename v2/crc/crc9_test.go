@@ -129,3 +129,23 @@ func TestCRC9_Rate12_DataSize(t *testing.T) {
 		t.Errorf("CRC9 for Rate 1/2 size = 0x%03X, exceeds range", c)
 	}
 }
+
+func TestCalculateDataBlockCRC9_MaskAndSerial(t *testing.T) {
+	data := []byte{0x12, 0x34, 0x56, 0x78, 0x9A, 0xBC, 0xDE, 0xF0, 0x11, 0x22}
+
+	unmasked := crc.CalculateDataBlockCRC9(5, data, 0)
+	bits := append(bit.UnpackBits(data), bit.BitsFromUint8(5, 7)...)
+	if want := crc.CalculateCRC9(bits); unmasked != want {
+		t.Errorf("unmasked CRC = 0x%03X, want 0x%03X", unmasked, want)
+	}
+
+	for _, mask := range []uint16{crc.CRC9MaskRate12Data, crc.CRC9MaskRate34Data, crc.CRC9MaskRate1Data} {
+		if got := crc.CalculateDataBlockCRC9(5, data, mask); got != unmasked^mask {
+			t.Errorf("mask 0x%03X: CRC = 0x%03X, want 0x%03X", mask, got, unmasked^mask)
+		}
+	}
+
+	if crc.CalculateDataBlockCRC9(6, data, 0) == unmasked {
+		t.Error("serial number does not affect the CRC")
+	}
+}
@@ -0,0 +1,76 @@
+package enums
+
+// DataResponseType identifies a confirmed data response by the Class and
+// Type fields of its Response Packet Header (C_RHEAD), as Class<<3 | Type.
+// ETSI TS 102 361-1 — §9.3 Response Class, Type and Status
+type DataResponseType uint8
+
+const (
+	// DataResponseACK acknowledges every block of the packet. Status is
+	// the N(S) of the packet.
+	DataResponseACK DataResponseType = 0b00_001
+	// DataResponseNACKIllegalFormat rejects a packet with an illegal format.
+	DataResponseNACKIllegalFormat DataResponseType = 0b01_000
+	// DataResponseNACKPacketCRC rejects a packet whose CRC-32 failed.
+	DataResponseNACKPacketCRC DataResponseType = 0b01_001
+	// DataResponseNACKMemoryFull rejects a packet the receiver cannot store.
+	DataResponseNACKMemoryFull DataResponseType = 0b01_010
+	// DataResponseNACKFSNOutOfSequence rejects an out of sequence fragment.
+	DataResponseNACKFSNOutOfSequence DataResponseType = 0b01_011
+	// DataResponseNACKUndeliverable rejects an undeliverable packet.
+	DataResponseNACKUndeliverable DataResponseType = 0b01_100
+	// DataResponseNACKNSOutOfSequence rejects a packet whose N(S) is out of
+	// sequence.
+	DataResponseNACKNSOutOfSequence DataResponseType = 0b01_101
+	// DataResponseNACKInvalidUser rejects a packet from a user the system
+	// disallows.
+	DataResponseNACKInvalidUser DataResponseType = 0b01_110
+	// DataResponseSelectiveACK requests retransmission of the blocks marked
+	// in the response block bitmap.
+	DataResponseSelectiveACK DataResponseType = 0b10_000
+)
+
+// DataResponseTypeFromFields combines the Class and Type fields of a
+// C_RHEAD.
+func DataResponseTypeFromFields(class, typ uint8) DataResponseType {
+	return DataResponseType((class&0b11)<<3 | typ&0b111)
+}
+
+// Class returns the Response Class field.
+func (r DataResponseType) Class() uint8 {
+	return uint8(r>>3) & 0b11
+}
+
+// Type returns the Response Type field.
+func (r DataResponseType) Type() uint8 {
+	return uint8(r) & 0b111
+}
+
+// IsNACK reports whether the response rejects the packet.
+func (r DataResponseType) IsNACK() bool {
+	return r.Class() == 0b01
+}
+
+func DataResponseTypeToName(r DataResponseType) string {
+	switch r {
+	case DataResponseACK:
+		return "ACK"
+	case DataResponseNACKIllegalFormat:
+		return "NACK Illegal Format"
+	case DataResponseNACKPacketCRC:
+		return "NACK Packet CRC"
+	case DataResponseNACKMemoryFull:
+		return "NACK Memory Full"
+	case DataResponseNACKFSNOutOfSequence:
+		return "NACK FSN Out of Sequence"
+	case DataResponseNACKUndeliverable:
+		return "NACK Undeliverable"
+	case DataResponseNACKNSOutOfSequence:
+		return "NACK N(S) Out of Sequence"
+	case DataResponseNACKInvalidUser:
+		return "NACK Invalid User"
+	case DataResponseSelectiveACK:
+		return "Selective ACK"
+	}
+	return "Unknown"
+}
@@ -0,0 +1,45 @@
+package enums_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+)
+
+func TestDataResponseType_Fields(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		response enums.DataResponseType
+		class    uint8
+		typ      uint8
+		nack     bool
+		name     string
+	}{
+		{enums.DataResponseACK, 0b00, 0b001, false, "ACK"},
+		{enums.DataResponseNACKIllegalFormat, 0b01, 0b000, true, "NACK Illegal Format"},
+		{enums.DataResponseNACKPacketCRC, 0b01, 0b001, true, "NACK Packet CRC"},
+		{enums.DataResponseNACKMemoryFull, 0b01, 0b010, true, "NACK Memory Full"},
+		{enums.DataResponseNACKFSNOutOfSequence, 0b01, 0b011, true, "NACK FSN Out of Sequence"},
+		{enums.DataResponseNACKUndeliverable, 0b01, 0b100, true, "NACK Undeliverable"},
+		{enums.DataResponseNACKNSOutOfSequence, 0b01, 0b101, true, "NACK N(S) Out of Sequence"},
+		{enums.DataResponseNACKInvalidUser, 0b01, 0b110, true, "NACK Invalid User"},
+		{enums.DataResponseSelectiveACK, 0b10, 0b000, false, "Selective ACK"},
+	}
+	for _, tt := range tests {
+		if tt.response.Class() != tt.class || tt.response.Type() != tt.typ {
+			t.Errorf("%s: Class/Type = %02b/%03b, want %02b/%03b", tt.name, tt.response.Class(), tt.response.Type(), tt.class, tt.typ)
+		}
+		if got := enums.DataResponseTypeFromFields(tt.class, tt.typ); got != tt.response {
+			t.Errorf("DataResponseTypeFromFields(%d, %d) = %d, want %d", tt.class, tt.typ, got, tt.response)
+		}
+		if tt.response.IsNACK() != tt.nack {
+			t.Errorf("%s: IsNACK = %t", tt.name, tt.response.IsNACK())
+		}
+		if got := enums.DataResponseTypeToName(tt.response); got != tt.name {
+			t.Errorf("DataResponseTypeToName(%d) = %q, want %q", tt.response, got, tt.name)
+		}
+	}
+	if got := enums.DataResponseTypeToName(enums.DataResponseTypeFromFields(0b11, 0)); got != "Unknown" {
+		t.Errorf("reserved class name = %q", got)
+	}
+}
@@ -0,0 +1,92 @@
+package enums
+
+import "fmt"
+
+// DDFormat identifies the format of the user data of a defined short data
+// message, sent in the DD_HEAD DefinedData field.
+// ETSI TS 102 361-1 — Defined Data format (DD) information element
+type DDFormat uint8
+
+const (
+	DDFormatBinary            DDFormat = 0b000000
+	DDFormatBCD               DDFormat = 0b000001
+	DDFormat7BitCharacter     DDFormat = 0b000010
+	DDFormatISO8859_1         DDFormat = 0b000011
+	DDFormatISO8859_2         DDFormat = 0b000100
+	DDFormatISO8859_3         DDFormat = 0b000101
+	DDFormatISO8859_4         DDFormat = 0b000110
+	DDFormatISO8859_5         DDFormat = 0b000111
+	DDFormatISO8859_6         DDFormat = 0b001000
+	DDFormatISO8859_7         DDFormat = 0b001001
+	DDFormatISO8859_8         DDFormat = 0b001010
+	DDFormatISO8859_9         DDFormat = 0b001011
+	DDFormatISO8859_10        DDFormat = 0b001100
+	DDFormatISO8859_11        DDFormat = 0b001101
+	DDFormatISO8859_13        DDFormat = 0b001110
+	DDFormatISO8859_14        DDFormat = 0b001111
+	DDFormatISO8859_15        DDFormat = 0b010000
+	DDFormatISO8859_16        DDFormat = 0b010001
+	DDFormatUTF8              DDFormat = 0b010010
+	DDFormatUTF16             DDFormat = 0b010011
+	DDFormatUTF16BE           DDFormat = 0b010100
+	DDFormatUTF16LE           DDFormat = 0b010101
+	DDFormatUTF32             DDFormat = 0b010110
+	DDFormatUTF32BE           DDFormat = 0b010111
+	DDFormatUTF32LE           DDFormat = 0b011000
+	ddFormatLastISO8859Format          = DDFormatISO8859_16
+)
+
+// ISO8859Part returns the part of ISO/IEC 8859 used by an 8-bit character
+// format, or 0 for any other format.
+func (f DDFormat) ISO8859Part() int {
+	if f < DDFormatISO8859_1 || f > ddFormatLastISO8859Format {
+		return 0
+	}
+	part := int(f-DDFormatISO8859_1) + 1
+	// There is no part 12.
+	if part >= 12 {
+		part++
+	}
+	return part
+}
+
+// IsText reports whether the format carries characters.
+func (f DDFormat) IsText() bool {
+	return f >= DDFormat7BitCharacter && f <= DDFormatUTF32LE
+}
+
+func DDFormatToName(f DDFormat) string {
+	switch f {
+	case DDFormatBinary:
+		return "Binary"
+	case DDFormatBCD:
+		return "BCD"
+	case DDFormat7BitCharacter:
+		return "7-bit character"
+	case DDFormatUTF8:
+		return "Unicode UTF-8"
+	case DDFormatUTF16:
+		return "Unicode UTF-16"
+	case DDFormatUTF16BE:
+		return "Unicode UTF-16BE"
+	case DDFormatUTF16LE:
+		return "Unicode UTF-16LE"
+	case DDFormatUTF32:
+		return "Unicode UTF-32"
+	case DDFormatUTF32BE:
+		return "Unicode UTF-32BE"
+	case DDFormatUTF32LE:
+		return "Unicode UTF-32LE"
+	}
+	if part := f.ISO8859Part(); part != 0 {
+		return fmt.Sprintf("8-bit ISO 8859-%d", part)
+	}
+	return "Reserved"
+}
+
+func DDFormatFromInt(i int) (DDFormat, error) {
+	if i < 0 || i > 0b111111 {
+		return 0, fmt.Errorf("invalid DD format value: %d", i)
+	}
+	return DDFormat(i), nil
+}
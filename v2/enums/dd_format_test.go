@@ -0,0 +1,70 @@
+package enums_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+)
+
+func TestDDFormatToName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		format   enums.DDFormat
+		expected string
+	}{
+		{enums.DDFormatBinary, "Binary"},
+		{enums.DDFormatBCD, "BCD"},
+		{enums.DDFormat7BitCharacter, "7-bit character"},
+		{enums.DDFormatISO8859_1, "8-bit ISO 8859-1"},
+		{enums.DDFormatISO8859_11, "8-bit ISO 8859-11"},
+		{enums.DDFormatISO8859_13, "8-bit ISO 8859-13"},
+		{enums.DDFormatISO8859_16, "8-bit ISO 8859-16"},
+		{enums.DDFormatUTF8, "Unicode UTF-8"},
+		{enums.DDFormatUTF16LE, "Unicode UTF-16LE"},
+		{enums.DDFormatUTF32LE, "Unicode UTF-32LE"},
+		{enums.DDFormat(0b011001), "Reserved"},
+		{enums.DDFormat(0b111111), "Reserved"},
+	}
+	for _, tt := range tests {
+		if got := enums.DDFormatToName(tt.format); got != tt.expected {
+			t.Errorf("DDFormatToName(%d) = %q, want %q", tt.format, got, tt.expected)
+		}
+	}
+}
+
+func TestDDFormat_Classes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		format enums.DDFormat
+		part   int
+		text   bool
+	}{
+		{enums.DDFormatBinary, 0, false},
+		{enums.DDFormatBCD, 0, false},
+		{enums.DDFormat7BitCharacter, 0, true},
+		{enums.DDFormatISO8859_1, 1, true},
+		{enums.DDFormatISO8859_5, 5, true},
+		{enums.DDFormatISO8859_15, 15, true},
+		{enums.DDFormatUTF16, 0, true},
+		{enums.DDFormatUTF32LE, 0, true},
+		{enums.DDFormat(0b011001), 0, false},
+	}
+	for _, tt := range tests {
+		if got := tt.format.ISO8859Part(); got != tt.part {
+			t.Errorf("%s: ISO8859Part() = %d, want %d", enums.DDFormatToName(tt.format), got, tt.part)
+		}
+		if got := tt.format.IsText(); got != tt.text {
+			t.Errorf("%s: IsText() = %t, want %t", enums.DDFormatToName(tt.format), got, tt.text)
+		}
+	}
+}
+
+func TestDDFormatFromInt(t *testing.T) {
+	t.Parallel()
+	if f, err := enums.DDFormatFromInt(0b010101); err != nil || f != enums.DDFormatUTF16LE {
+		t.Errorf("DDFormatFromInt(21) = %d, %v", f, err)
+	}
+	if _, err := enums.DDFormatFromInt(64); err == nil {
+		t.Error("DDFormatFromInt(64) should return error")
+	}
+}
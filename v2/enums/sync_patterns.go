@@ -1,5 +1,7 @@
 package enums
 
+import "math/bits"
+
 // ETSI TS 102 361-1 - 9.1.1 Synchronization (SYNC) PDU - Table 9.2: SYNC patterns
 type SyncPattern int64
 
@@ -19,40 +21,97 @@ const (
 	EmbeddedSignallingPattern SyncPattern = -1
 )
 
-// SyncPatternFromBytes returns the SyncPattern that matches the given burst.
-func SyncPatternFromBytes(syncOrEmbeddedSignalling [6]byte) SyncPattern {
-	// Convert 6 bytes into an int64
-	var syncOrEmbeddedSignallingInt64 int64
-	for i := 0; i < 6; i++ {
-		syncOrEmbeddedSignallingInt64 |= int64(syncOrEmbeddedSignalling[i]) << (8 * (5 - i))
+// SyncPatternBits is the width of a SYNC pattern in bits.
+const SyncPatternBits = 48
+
+// SyncMaxUnambiguousBitErrors is the largest bit-error threshold that can be
+// passed to MatchSyncPattern without two Table 9.2 patterns competing for the
+// same received word. The minimum Hamming distance between any two patterns
+// is 10, so up to 4 errors always resolve to a single pattern.
+const SyncMaxUnambiguousBitErrors = 4
+
+// syncPatternCandidates lists every pattern of Table 9.2 in table order.
+// Ties in MatchSyncPattern resolve to the earlier entry.
+//
+//nolint:gochecknoglobals
+var syncPatternCandidates = [...]SyncPattern{
+	BsSourcedVoice,
+	BsSourcedData,
+	MsSourcedVoice,
+	MsSourcedData,
+	MsSourcedRcSync,
+	Tdma1Voice,
+	Tdma1Data,
+	Tdma2Voice,
+	Tdma2Data,
+	Reserved,
+}
+
+// SyncMatch is the result of scoring 48 received bits against every SYNC
+// pattern of Table 9.2.
+type SyncMatch struct {
+	// Pattern is the accepted SYNC pattern, or EmbeddedSignallingPattern when
+	// no candidate lies within the bit-error threshold.
+	Pattern SyncPattern
+	// Candidate is the closest SYNC pattern, regardless of the threshold.
+	Candidate SyncPattern
+	// Distance is the Hamming distance between the received bits and Candidate.
+	Distance int
+	// RunnerUp is the second-closest SYNC pattern.
+	RunnerUp SyncPattern
+	// RunnerUpDistance is the Hamming distance between the received bits and RunnerUp.
+	RunnerUpDistance int
+}
+
+// Matched returns true if a SYNC pattern was accepted.
+func (m SyncMatch) Matched() bool {
+	return m.Pattern != EmbeddedSignallingPattern
+}
+
+// MatchSyncPattern scores the 48 received SYNC bits against every pattern of
+// Table 9.2 and accepts the closest one if its Hamming distance is at most
+// maxBitErrors. A threshold of 0 requires an exact match. Thresholds above
+// SyncMaxUnambiguousBitErrors are permitted, but may accept a pattern that is
+// equally close to another one.
+func MatchSyncPattern(syncOrEmbeddedSignalling [6]byte, maxBitErrors int) SyncMatch {
+	received := syncBytesToInt64(syncOrEmbeddedSignalling)
+
+	match := SyncMatch{
+		Pattern:          EmbeddedSignallingPattern,
+		Candidate:        EmbeddedSignallingPattern,
+		Distance:         SyncPatternBits + 1,
+		RunnerUp:         EmbeddedSignallingPattern,
+		RunnerUpDistance: SyncPatternBits + 1,
+	}
+	for _, candidate := range syncPatternCandidates {
+		distance := bits.OnesCount64(uint64(received ^ int64(candidate))) //nolint:gosec // both operands are 48-bit non-negative values
+		switch {
+		case distance < match.Distance:
+			match.RunnerUp, match.RunnerUpDistance = match.Candidate, match.Distance
+			match.Candidate, match.Distance = candidate, distance
+		case distance < match.RunnerUpDistance:
+			match.RunnerUp, match.RunnerUpDistance = candidate, distance
+		}
 	}
 
-	switch SyncPattern(syncOrEmbeddedSignallingInt64) {
-	case BsSourcedVoice:
-		return BsSourcedVoice
-	case BsSourcedData:
-		return BsSourcedData
-	case MsSourcedVoice:
-		return MsSourcedVoice
-	case MsSourcedData:
-		return MsSourcedData
-	case MsSourcedRcSync:
-		return MsSourcedRcSync
-	case Tdma1Voice:
-		return Tdma1Voice
-	case Tdma1Data:
-		return Tdma1Data
-	case Tdma2Voice:
-		return Tdma2Voice
-	case Tdma2Data:
-		return Tdma2Data
-	case EmbeddedSignallingPattern:
-		return EmbeddedSignallingPattern
-	case Reserved:
-		return Reserved
-	default:
-		return EmbeddedSignallingPattern
+	if match.Distance <= maxBitErrors {
+		match.Pattern = match.Candidate
+	}
+	return match
+}
+
+// SyncPatternFromBytes returns the SyncPattern that exactly matches the given burst,
+// or EmbeddedSignallingPattern if there is no exact match.
+func SyncPatternFromBytes(syncOrEmbeddedSignalling [6]byte) SyncPattern {
+	return MatchSyncPattern(syncOrEmbeddedSignalling, 0).Pattern
+}
+
+func syncBytesToInt64(syncOrEmbeddedSignalling [6]byte) int64 {
+	var val int64
+	for i := 0; i < 6; i++ {
+		val |= int64(syncOrEmbeddedSignalling[i]) << (8 * (5 - i))
 	}
+	return val
 }
 
 // SyncPatternToName returns the name of the SyncPattern that matches the given burst.
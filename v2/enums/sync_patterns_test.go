@@ -95,3 +95,72 @@ func TestSyncPatternFromBytes_RoundTrip(t *testing.T) {
 		}
 	}
 }
+
+func syncPatternToBytes(p SyncPattern) [6]byte {
+	var syncBytes [6]byte
+	val := int64(p)
+	for i := 0; i < 6; i++ {
+		syncBytes[i] = byte(val >> (8 * (5 - i)))
+	}
+	return syncBytes
+}
+
+func TestMatchSyncPattern_ExactMatch(t *testing.T) {
+	for _, p := range []SyncPattern{BsSourcedVoice, BsSourcedData, MsSourcedVoice, MsSourcedData, MsSourcedRcSync, Tdma1Voice, Tdma1Data, Tdma2Voice, Tdma2Data, Reserved} {
+		m := MatchSyncPattern(syncPatternToBytes(p), 0)
+		if m.Pattern != p || m.Candidate != p {
+			t.Errorf("%s: got Pattern=%v Candidate=%v", SyncPatternToName(p), m.Pattern, m.Candidate)
+		}
+		if m.Distance != 0 {
+			t.Errorf("%s: Distance = %d, want 0", SyncPatternToName(p), m.Distance)
+		}
+		if m.RunnerUp == p || m.RunnerUp == EmbeddedSignallingPattern {
+			t.Errorf("%s: unexpected RunnerUp %v", SyncPatternToName(p), m.RunnerUp)
+		}
+		if m.RunnerUpDistance < 10 {
+			t.Errorf("%s: RunnerUpDistance = %d, want >= 10", SyncPatternToName(p), m.RunnerUpDistance)
+		}
+		if !m.Matched() {
+			t.Errorf("%s: Matched() = false", SyncPatternToName(p))
+		}
+	}
+}
+
+func TestMatchSyncPattern_BitErrorsWithinThreshold(t *testing.T) {
+	for errs := 1; errs <= SyncMaxUnambiguousBitErrors; errs++ {
+		received := syncPatternToBytes(SyncPattern(int64(BsSourcedData) ^ (int64(1)<<errs - 1)))
+		m := MatchSyncPattern(received, SyncMaxUnambiguousBitErrors)
+		if m.Pattern != BsSourcedData {
+			t.Errorf("%d errors: Pattern = %v, want BsSourcedData", errs, m.Pattern)
+		}
+		if m.Distance != errs {
+			t.Errorf("%d errors: Distance = %d", errs, m.Distance)
+		}
+	}
+}
+
+func TestMatchSyncPattern_BitErrorsBeyondThreshold(t *testing.T) {
+	// Three flipped bits against a threshold of two
+	received := syncPatternToBytes(SyncPattern(int64(MsSourcedVoice) ^ 0b10101))
+	m := MatchSyncPattern(received, 2)
+	if m.Matched() {
+		t.Errorf("Pattern = %v, want EmbeddedSignallingPattern", m.Pattern)
+	}
+	if m.Candidate != MsSourcedVoice {
+		t.Errorf("Candidate = %v, want MsSourcedVoice", m.Candidate)
+	}
+	if m.Distance != 3 {
+		t.Errorf("Distance = %d, want 3", m.Distance)
+	}
+}
+
+func TestMatchSyncPattern_UnrelatedBits(t *testing.T) {
+	var received [6]byte // all zeros, far from every pattern
+	m := MatchSyncPattern(received, SyncMaxUnambiguousBitErrors)
+	if m.Matched() {
+		t.Errorf("Pattern = %v, want EmbeddedSignallingPattern", m.Pattern)
+	}
+	if m.RunnerUpDistance < m.Distance {
+		t.Errorf("RunnerUpDistance %d < Distance %d", m.RunnerUpDistance, m.Distance)
+	}
+}
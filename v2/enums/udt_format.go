@@ -0,0 +1,70 @@
+package enums
+
+import "fmt"
+
+// UDTFormat identifies the format of the appended data of a Unified Data
+// Transport (UDT) message, sent in the UDT_HEAD UDT Format field.
+// ETSI TS 102 361-4 — UDT Format information element
+type UDTFormat uint8
+
+const (
+	UDTFormatBinary                UDTFormat = 0b0000
+	UDTFormatAddress               UDTFormat = 0b0001
+	UDTFormatBCD                   UDTFormat = 0b0010
+	UDTFormat7BitCharacter         UDTFormat = 0b0011
+	UDTFormat8BitCharacter         UDTFormat = 0b0100
+	UDTFormatNMEA                  UDTFormat = 0b0101
+	UDTFormatIPAddress             UDTFormat = 0b0110
+	UDTFormatUnicode               UDTFormat = 0b0111
+	UDTFormatManufacturerSpecific  UDTFormat = 0b1000
+	UDTFormatManufacturerSpecific2 UDTFormat = 0b1001
+	UDTFormatAddressAndUnicode     UDTFormat = 0b1010
+)
+
+// DDFormat returns the defined data format that encodes the characters of
+// a character format: 7-bit characters, ISO/IEC 8859-1 for 8-bit
+// characters and UTF-16BE for 16-bit Unicode. Other formats return false.
+func (f UDTFormat) DDFormat() (DDFormat, bool) {
+	switch f {
+	case UDTFormat7BitCharacter:
+		return DDFormat7BitCharacter, true
+	case UDTFormat8BitCharacter:
+		return DDFormatISO8859_1, true
+	case UDTFormatUnicode:
+		return DDFormatUTF16BE, true
+	}
+	return 0, false
+}
+
+func UDTFormatToName(f UDTFormat) string {
+	switch f {
+	case UDTFormatBinary:
+		return "Binary"
+	case UDTFormatAddress:
+		return "MS or talkgroup address"
+	case UDTFormatBCD:
+		return "4-bit BCD"
+	case UDTFormat7BitCharacter:
+		return "ISO 7-bit coded characters"
+	case UDTFormat8BitCharacter:
+		return "ISO 8-bit coded characters"
+	case UDTFormatNMEA:
+		return "NMEA location coded"
+	case UDTFormatIPAddress:
+		return "IP address"
+	case UDTFormatUnicode:
+		return "16-bit Unicode characters"
+	case UDTFormatManufacturerSpecific, UDTFormatManufacturerSpecific2:
+		return "Manufacturer Specific"
+	case UDTFormatAddressAndUnicode:
+		return "Address and 16-bit Unicode characters"
+	}
+	return "Reserved"
+}
+
+func UDTFormatFromInt(i int) (UDTFormat, error) {
+	if i < 0 || i > 0b1111 {
+		return 0, fmt.Errorf("invalid UDT format value: %d", i)
+	}
+	return UDTFormat(i), nil
+}
@@ -0,0 +1,62 @@
+package enums_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+)
+
+func TestUDTFormatToName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		format   enums.UDTFormat
+		expected string
+	}{
+		{enums.UDTFormatBinary, "Binary"},
+		{enums.UDTFormatBCD, "4-bit BCD"},
+		{enums.UDTFormat7BitCharacter, "ISO 7-bit coded characters"},
+		{enums.UDTFormat8BitCharacter, "ISO 8-bit coded characters"},
+		{enums.UDTFormatUnicode, "16-bit Unicode characters"},
+		{enums.UDTFormatManufacturerSpecific2, "Manufacturer Specific"},
+		{enums.UDTFormatAddressAndUnicode, "Address and 16-bit Unicode characters"},
+		{enums.UDTFormat(0b1011), "Reserved"},
+		{enums.UDTFormat(0b1111), "Reserved"},
+	}
+	for _, tt := range tests {
+		if got := enums.UDTFormatToName(tt.format); got != tt.expected {
+			t.Errorf("UDTFormatToName(%d) = %q, want %q", tt.format, got, tt.expected)
+		}
+	}
+}
+
+func TestUDTFormat_DDFormat(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		format enums.UDTFormat
+		dd     enums.DDFormat
+		text   bool
+	}{
+		{enums.UDTFormat7BitCharacter, enums.DDFormat7BitCharacter, true},
+		{enums.UDTFormat8BitCharacter, enums.DDFormatISO8859_1, true},
+		{enums.UDTFormatUnicode, enums.DDFormatUTF16BE, true},
+		{enums.UDTFormatBinary, 0, false},
+		{enums.UDTFormatBCD, 0, false},
+		{enums.UDTFormatAddressAndUnicode, 0, false},
+	}
+	for _, tt := range tests {
+		dd, ok := tt.format.DDFormat()
+		if dd != tt.dd || ok != tt.text {
+			t.Errorf("UDTFormat(%d).DDFormat() = %d, %t, want %d, %t", tt.format, dd, ok, tt.dd, tt.text)
+		}
+	}
+}
+
+func TestUDTFormatFromInt(t *testing.T) {
+	t.Parallel()
+	if _, err := enums.UDTFormatFromInt(0b1010); err != nil {
+		t.Errorf("UDTFormatFromInt(10) returned error: %v", err)
+	}
+	if _, err := enums.UDTFormatFromInt(16); err == nil {
+		t.Error("UDTFormatFromInt(16) should return error")
+	}
+}
@@ -8,7 +8,7 @@ var hamming15_11_syndrome_table = [16]int{-1, 11, 12, 8, 13, 5, 9, 3, 14, 0, 6,
 
 var hamming13_9_syndrome_table = [16]int{-1, 9, 10, 6, 11, 3, 7, 1, 12, -1, 4, -1, 8, 5, 2, 0}
 
-var hamming16_11_syndrome_table = [32]int{-1, 11, 12, -1, 13, -1, -1, 3, 14, -1, -1, 1, -1, 7, -1, -1, 15, -1, -1, 8, 10, -1, 9, -1, -1, 0, 6, -1, -1, 5, 4, 2}
+var hamming16_11_syndrome_table = [32]int{-1, 11, 12, -1, 13, -1, -1, 3, 14, -1, -1, 1, -1, 7, 4, -1, 15, -1, -1, 8, -1, 5, 9, -1, -1, 0, 6, -1, 10, -1, -1, 2}
 
 var hamming17_12_syndrome_table = [32]int{-1, 12, 13, -1, 14, 9, -1, 3, 15, -1, 10, 7, -1, -1, 4, -1, 16, 6, -1, -1, 11, -1, 8, 2, -1, -1, -1, 0, 5, -1, -1, 1}
 
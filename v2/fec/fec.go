@@ -33,6 +33,7 @@ func (b *BERCalculator) Add(result FECResult) {
 
 // AddBurst incorporates all FEC layers from a burst into the running BER calculation.
 func (b *BERCalculator) AddBurst(stats BurstFECStats) {
+	b.Add(stats.Sync)
 	b.Add(stats.SlotType)
 	b.Add(stats.EMB)
 	b.Add(stats.Payload)
@@ -67,6 +68,7 @@ func (b *BERCalculator) Reset() {
 
 // BurstFECStats holds per-layer FEC results for a single DMR burst.
 type BurstFECStats struct {
+	Sync     FECResult // SYNC pattern Hamming distance — 48 bits (SYNC bursts only)
 	SlotType FECResult // Golay(20,8,7) — 20 bits
 	EMB      FECResult // QR(16,7,6) — 16 bits (voice bursts only)
 	Payload  FECResult // BPTC/Trellis/Rate1 — 196/196/0 bits
@@ -78,8 +80,8 @@ type BurstFECStats struct {
 // Aggregate returns combined stats across all FEC layers in this burst.
 func (s BurstFECStats) Aggregate() FECResult {
 	return FECResult{
-		BitsChecked:     s.SlotType.BitsChecked + s.EMB.BitsChecked + s.Payload.BitsChecked + s.Voice.BitsChecked + s.PDU.BitsChecked + s.RC.BitsChecked,
-		ErrorsCorrected: s.SlotType.ErrorsCorrected + s.EMB.ErrorsCorrected + s.Payload.ErrorsCorrected + s.Voice.ErrorsCorrected + s.PDU.ErrorsCorrected + s.RC.ErrorsCorrected,
-		Uncorrectable:   s.SlotType.Uncorrectable || s.EMB.Uncorrectable || s.Payload.Uncorrectable || s.Voice.Uncorrectable || s.PDU.Uncorrectable || s.RC.Uncorrectable,
+		BitsChecked:     s.Sync.BitsChecked + s.SlotType.BitsChecked + s.EMB.BitsChecked + s.Payload.BitsChecked + s.Voice.BitsChecked + s.PDU.BitsChecked + s.RC.BitsChecked,
+		ErrorsCorrected: s.Sync.ErrorsCorrected + s.SlotType.ErrorsCorrected + s.EMB.ErrorsCorrected + s.Payload.ErrorsCorrected + s.Voice.ErrorsCorrected + s.PDU.ErrorsCorrected + s.RC.ErrorsCorrected,
+		Uncorrectable:   s.Sync.Uncorrectable || s.SlotType.Uncorrectable || s.EMB.Uncorrectable || s.Payload.Uncorrectable || s.Voice.Uncorrectable || s.PDU.Uncorrectable || s.RC.Uncorrectable,
 	}
 }
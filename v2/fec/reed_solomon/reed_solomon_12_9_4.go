@@ -140,23 +140,20 @@ func Encode(data []byte) ([]byte, error) {
 	codeword := make([]byte, RS_12_9_DATASIZE+RS_12_9_CHECKSUMSIZE)
 	copy(codeword, data)
 
-	// Calculate checksum bytes using the generator polynomial
-	// RS(12,9) systematic encoding: divide by generator polynomial
+	// Systematic encoding: the parity is the remainder of data(x)·x³ divided
+	// by the generator polynomial g(x) = (x - α)(x - α²)(x - α³)
+	// = x³ + 0x0E·x² + 0x38·x + 0x40, with α = 2 over GF(2⁸) mod 0x11D.
+	// parity[0] holds the x⁰ coefficient and is transmitted last.
+	var parity [RS_12_9_CHECKSUMSIZE]byte
 	for i := 0; i < RS_12_9_DATASIZE; i++ {
-		feedback := codeword[i] ^ codeword[RS_12_9_DATASIZE]
-		if feedback != 0 {
-			// Generator polynomial coefficients for RS(12,9,4) over GF(256)
-			// g(x) = (x - α)(x - α²)(x - α³) = x³ + g2·x² + g1·x + g0
-			// For DMR: α = 2, so roots are 2, 4, 8
-			codeword[RS_12_9_DATASIZE] ^= ReedSolomon1294GaloisMul(feedback, 0x40)   // g2
-			codeword[RS_12_9_DATASIZE+1] ^= ReedSolomon1294GaloisMul(feedback, 0x78) // g1
-			codeword[RS_12_9_DATASIZE+2] ^= ReedSolomon1294GaloisMul(feedback, 0x40) // g0 (shifted in next iteration)
-		}
-		// Shift
-		codeword[RS_12_9_DATASIZE] = codeword[RS_12_9_DATASIZE+1]
-		codeword[RS_12_9_DATASIZE+1] = codeword[RS_12_9_DATASIZE+2]
-		codeword[RS_12_9_DATASIZE+2] = feedback
+		feedback := codeword[i] ^ parity[2]
+		parity[2] = parity[1] ^ ReedSolomon1294GaloisMul(feedback, 0x0E)
+		parity[1] = parity[0] ^ ReedSolomon1294GaloisMul(feedback, 0x38)
+		parity[0] = ReedSolomon1294GaloisMul(feedback, 0x40)
 	}
+	codeword[RS_12_9_DATASIZE] = parity[2]
+	codeword[RS_12_9_DATASIZE+1] = parity[1]
+	codeword[RS_12_9_DATASIZE+2] = parity[0]
 
 	return codeword, nil
 }
@@ -259,7 +256,9 @@ func ReedSolomon1294GaloisMul(a, b uint8) uint8 {
 	if a == 0 || b == 0 {
 		return 0
 	}
-	return galois_exp_table[(galois_log_table[a]+galois_log_table[b])%255]
+	// Sum the logs as ints: adding the byte values would wrap at 256
+	// before the mod-255 reduction.
+	return galois_exp_table[(int(galois_log_table[a])+int(galois_log_table[b]))%255]
 }
 
 // Multiply by z (shift right by 1).
@@ -204,3 +204,29 @@ func TestReedSolomon1294CheckSyndromeWithErrors(t *testing.T) {
 		t.Error("Corrupted codeword should have non-zero syndrome")
 	}
 }
+
+func TestReedSolomon1294GaloisMulLogWraparound(t *testing.T) {
+	// Reference shift-and-add multiply modulo x⁸ + x⁴ + x³ + x² + 1.
+	slowMul := func(a, b byte) byte {
+		var p byte
+		for b != 0 {
+			if b&1 != 0 {
+				p ^= a
+			}
+			carry := a & 0x80
+			a <<= 1
+			if carry != 0 {
+				a ^= 0x1D
+			}
+			b >>= 1
+		}
+		return p
+	}
+	for a := 1; a < 256; a++ {
+		for _, b := range []byte{0x02, 0x0E, 0x38, 0x40, 0x8F, 0xFF} {
+			if got, want := ReedSolomon1294GaloisMul(byte(a), b), slowMul(byte(a), b); got != want {
+				t.Fatalf("GaloisMul(0x%02X, 0x%02X) = 0x%02X, want 0x%02X", a, b, got, want)
+			}
+		}
+	}
+}
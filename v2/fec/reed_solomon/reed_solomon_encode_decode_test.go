@@ -191,3 +191,20 @@ func BenchmarkEncode(b *testing.B) {
 		_, _ = Encode(data)
 	}
 }
+
+func TestEncodeKnownVector(t *testing.T) {
+	// Voice LC header (UU_V_Ch_Usr) captured over the air; its parity was
+	// transmitted XORed with the 0x96 data type mask.
+	data := []byte{0x03, 0x00, 0x00, 0x00, 0x27, 0x06, 0x30, 0xB4, 0x3C}
+	want := []byte{0x5D ^ 0x96, 0xCF ^ 0x96, 0xC1 ^ 0x96}
+
+	codeword, err := Encode(data)
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	for i, w := range want {
+		if codeword[RS_12_9_DATASIZE+i] != w {
+			t.Errorf("parity[%d] = 0x%02X, want 0x%02X", i, codeword[RS_12_9_DATASIZE+i], w)
+		}
+	}
+}
@@ -0,0 +1,299 @@
+// Package hytera decodes and encodes Hytera text messages, sent between
+// radios as defined short data and between radios and applications with
+// the Text Message Protocol (TMP) over UDP.
+package hytera
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/utf16text"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// Type identifies a message or its acknowledgement.
+type Type uint8
+
+const (
+	// TypeText is a text message.
+	TypeText Type = iota
+	// TypeAck acknowledges the text message with the same request ID.
+	TypeAck
+)
+
+// Result is the result code of an acknowledgement.
+type Result uint8
+
+// ResultOK acknowledges a message delivered; other values are failure
+// codes, carried as received.
+const ResultOK Result = 0x00
+
+// Defined short data layout. Hytera radios declare the user data as BCD,
+// but it holds two header octets, zero in every message seen, followed by
+// the UTF-16LE text and a NUL terminator.
+const shortDataHeaderOctets = 2
+
+// Text Message Protocol layout: a header octet, a 16-bit opcode and a
+// 16-bit payload length, the payload, then a checksum and an end octet.
+// The payload starts with the request ID and the destination and source
+// addresses.
+const (
+	tmpHeader         = 0x09
+	tmpHeaderReliable = 0x80
+	tmpEnd            = 0x03
+
+	tmpOpcodePrivateMessage = 0x00A1
+	tmpOpcodePrivateAck     = 0x00A2
+	tmpOpcodeGroupMessage   = 0x00B1
+	tmpOpcodeGroupAck       = 0x00B2
+
+	tmpPrefixOctets  = 5
+	tmpTrailerOctets = 2
+	tmpIDOctets      = 12
+	tmpResultOctets  = 1
+	tmpMaxPayload    = 0xFFFF
+
+	// tmpChecksumOffset is added to the one's complement of the octet sum.
+	tmpChecksumOffset = 0x33
+
+	// idMask selects the radio ID or talkgroup in the low 24 bits of a
+	// TMP address.
+	idMask = 0xFFFFFF
+)
+
+// TMPNetwork is the first octet of the addresses written by EncodeTMP.
+// Hytera addresses radios and talkgroups as 10.x.y.z, with the ID in the
+// low 24 bits.
+const TMPNetwork = 10
+
+// Message is a text message or acknowledgement.
+type Message struct {
+	Type Type
+	// Source is the sender's radio ID and Destination the radio ID or
+	// talkgroup it is sent to.
+	Source      int
+	Destination int
+	// Group reports a message addressed to a talkgroup.
+	Group bool
+	// AckRequired asks the destination to acknowledge the message.
+	AckRequired bool
+	// RequestID matches a TMP acknowledgement to its message. It is not
+	// carried in defined short data.
+	RequestID uint32
+	// Result is the result code of an acknowledgement.
+	Result Result
+	// Text is the message text, empty for an acknowledgement.
+	Text string
+}
+
+// ToString returns a string representation of the message.
+func (m *Message) ToString() string {
+	return fmt.Sprintf("Message{ Type: %d, Source: %d, Destination: %d, Group: %t, AckRequired: %t, RequestID: %d, Result: %d, Text: %q }",
+		m.Type, m.Source, m.Destination, m.Group, m.AckRequired, m.RequestID, m.Result, m.Text)
+}
+
+// Ack returns the TMP acknowledgement of the message, sent back to its
+// source with result.
+func (m *Message) Ack(result Result) *Message {
+	return &Message{
+		Type:        TypeAck,
+		Source:      m.Destination,
+		Destination: m.Source,
+		Group:       m.Group,
+		RequestID:   m.RequestID,
+		Result:      result,
+	}
+}
+
+// DecodeShortData returns the text message carried by defined short data
+// between Hytera radios. The header's DD format is not checked; Hytera
+// radios declare BCD. Errors are an *elements.PDUError wrapping
+// elements.ErrInvalidLength for data too short for the header or not a
+// whole number of octets, or elements.ErrInvalidEncoding for text that is
+// not whole UTF-16 code units.
+func DecodeShortData(d *layer2.DefinedShortData) (*Message, error) {
+	if d.BitLength%8 != 0 || len(d.Data) != d.BitLength/8 || len(d.Data) < shortDataHeaderOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Data", Err: elements.ErrInvalidLength}
+	}
+	text, err := decodeUTF16LE(d.Data[shortDataHeaderOctets:])
+	if err != nil {
+		return nil, err
+	}
+	return &Message{
+		Type:        TypeText,
+		Source:      d.Source,
+		Destination: d.Destination,
+		Group:       d.Group,
+		AckRequired: d.ResponseRequested,
+		Text:        text,
+	}, nil
+}
+
+// ShortData returns the defined short data that carries a text message
+// between Hytera radios. An acknowledgement returns an
+// *elements.PDUError wrapping elements.ErrDataTypeMismatch; the short
+// data form is acknowledged with Response instead.
+func (m *Message) ShortData() (*layer2.DefinedShortData, error) {
+	if m.Type != TypeText {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Type", Err: elements.ErrDataTypeMismatch}
+	}
+	data := make([]byte, shortDataHeaderOctets, shortDataHeaderOctets+2*len(m.Text)+2)
+	data = append(utf16text.Append(data, m.Text, binary.LittleEndian), 0, 0)
+	return &layer2.DefinedShortData{
+		SAP:               pdu.ServiceAccessPointIDShortData,
+		Source:            m.Source,
+		Destination:       m.Destination,
+		Group:             m.Group,
+		ResponseRequested: m.AckRequired,
+		FullMessage:       true,
+		Format:            enums.DDFormatBCD,
+		Data:              data,
+		BitLength:         8 * len(data),
+	}, nil
+}
+
+// Response returns the short data confirmed response that acknowledges a
+// message received as defined short data, or nil for a message that does
+// not ask for one. Only individual messages are acknowledged.
+func (m *Message) Response() *layer2.DataResponse {
+	if m.Type != TypeText || !m.AckRequired || m.Group {
+		return nil
+	}
+	return &layer2.DataResponse{
+		SAP:         pdu.ServiceAccessPointIDShortData,
+		Source:      m.Destination,
+		Destination: m.Source,
+		Type:        enums.DataResponseACK,
+	}
+}
+
+// DecodeTMP returns the message or acknowledgement carried by a Text
+// Message Protocol UDP payload.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrDataTypeMismatch
+// for another Hytera protocol, elements.ErrInvalidLength for a truncated
+// packet, elements.ErrCRCMismatch for a bad checksum,
+// elements.ErrNotImplemented for another opcode, or
+// elements.ErrInvalidEncoding for a missing end octet or text that is not
+// whole UTF-16 code units.
+func DecodeTMP(payload []byte) (*Message, error) {
+	if len(payload) < tmpPrefixOctets+tmpTrailerOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	if payload[0]&^tmpHeaderReliable != tmpHeader {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Header", Err: elements.ErrDataTypeMismatch}
+	}
+	n := int(binary.BigEndian.Uint16(payload[3:]))
+	if tmpPrefixOctets+n+tmpTrailerOctets != len(payload) {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	if payload[len(payload)-1] != tmpEnd {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "End", Err: elements.ErrInvalidEncoding}
+	}
+	if tmpChecksum(payload[1:tmpPrefixOctets+n]) != payload[tmpPrefixOctets+n] {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Checksum", Err: elements.ErrCRCMismatch}
+	}
+
+	m := &Message{AckRequired: payload[0]&tmpHeaderReliable != 0}
+	switch binary.BigEndian.Uint16(payload[1:]) {
+	case tmpOpcodePrivateMessage:
+		m.Type = TypeText
+	case tmpOpcodeGroupMessage:
+		m.Type, m.Group = TypeText, true
+	case tmpOpcodePrivateAck:
+		m.Type = TypeAck
+	case tmpOpcodeGroupAck:
+		m.Type, m.Group = TypeAck, true
+	default:
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Opcode", Err: elements.ErrNotImplemented}
+	}
+
+	data := payload[tmpPrefixOctets : tmpPrefixOctets+n]
+	if len(data) < tmpIDOctets || m.Type == TypeAck && len(data) < tmpIDOctets+tmpResultOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Payload", Err: elements.ErrInvalidLength}
+	}
+	m.RequestID = binary.BigEndian.Uint32(data[0:])
+	m.Destination = int(binary.BigEndian.Uint32(data[4:]) & idMask)
+	m.Source = int(binary.BigEndian.Uint32(data[8:]) & idMask)
+	data = data[tmpIDOctets:]
+
+	if m.Type == TypeAck {
+		m.Result = Result(data[0])
+		return m, nil
+	}
+	text, err := decodeUTF16LE(data)
+	if err != nil {
+		return nil, err
+	}
+	m.Text = text
+	return m, nil
+}
+
+// EncodeTMP returns the Text Message Protocol UDP payload that carries the
+// message, with addresses on TMPNetwork. AckRequired sets the reliable
+// flag of the header. Text too long for one packet returns an
+// *elements.PDUError wrapping elements.ErrInvalidLength.
+func (m *Message) EncodeTMP() ([]byte, error) {
+	var opcode uint16
+	switch {
+	case m.Type == TypeAck && m.Group:
+		opcode = tmpOpcodeGroupAck
+	case m.Type == TypeAck:
+		opcode = tmpOpcodePrivateAck
+	case m.Group:
+		opcode = tmpOpcodeGroupMessage
+	default:
+		opcode = tmpOpcodePrivateMessage
+	}
+
+	data := make([]byte, 0, tmpIDOctets+2*len(m.Text))
+	data = binary.BigEndian.AppendUint32(data, m.RequestID)
+	data = appendTMPAddress(data, m.Destination)
+	data = appendTMPAddress(data, m.Source)
+	if m.Type == TypeAck {
+		data = append(data, byte(m.Result))
+	} else {
+		data = utf16text.Append(data, m.Text, binary.LittleEndian)
+	}
+	if len(data) > tmpMaxPayload {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Text", Err: elements.ErrInvalidLength}
+	}
+
+	header := byte(tmpHeader)
+	if m.AckRequired {
+		header |= tmpHeaderReliable
+	}
+	payload := make([]byte, 0, tmpPrefixOctets+len(data)+tmpTrailerOctets)
+	payload = append(payload, header)
+	payload = binary.BigEndian.AppendUint16(payload, opcode)
+	payload = binary.BigEndian.AppendUint16(payload, uint16(len(data))) //nolint:gosec // checked against tmpMaxPayload
+	payload = append(payload, data...)
+	return append(payload, tmpChecksum(payload[1:]), tmpEnd), nil
+}
+
+// tmpChecksum returns the checksum of the opcode, length and payload of a
+// TMP packet: the one's complement of their octet sum, plus 0x33.
+func tmpChecksum(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return ^sum + tmpChecksumOffset
+}
+
+// appendTMPAddress appends the TMP address of a radio ID or talkgroup.
+func appendTMPAddress(data []byte, id int) []byte {
+	return binary.BigEndian.AppendUint32(data, TMPNetwork<<24|uint32(id&idMask)) //nolint:gosec // masked to 24 bits
+}
+
+// decodeUTF16LE returns UTF-16LE text without its NUL terminator.
+func decodeUTF16LE(data []byte) (string, error) {
+	text, ok := utf16text.DecodeTerminated(data, binary.LittleEndian)
+	if !ok {
+		return "", &elements.PDUError{Layer: elements.LayerApplication, Field: "Text", Err: elements.ErrInvalidEncoding}
+	}
+	return text, nil
+}
@@ -0,0 +1,274 @@
+package hytera_test
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/hytera"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// The Hytera capture is five preamble CSBKs, then a DD_HEAD and three
+// Rate 1/2 blocks carrying "TEST KI5VMF" from 3191868 to 9990.
+func TestDecodeShortData_Capture(t *testing.T) {
+	t.Parallel()
+	data, err := os.ReadFile("../layer2/testdata/h-sms.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raws [][33]byte
+	var header *layer2.Burst
+	var blocks []*layer2.Burst
+	for i := 0; i+33 <= len(data); i += 33 {
+		var raw [33]byte
+		copy(raw[:], data[i:])
+		b, err := layer2.NewBurstFromBytes(raw)
+		if err != nil {
+			t.Fatalf("burst %d: %v", i/33, err)
+		}
+		switch b.Data.(type) {
+		case *pdu.DataHeader:
+			header = b
+		case *pdu.Rate12Data:
+			blocks = append(blocks, b)
+		default:
+			continue
+		}
+		raws = append(raws, raw)
+	}
+	if header == nil {
+		t.Fatal("no DD_HEAD")
+	}
+	h, ok := header.Data.(*pdu.DataHeader)
+	if !ok || h.DefinedDataHeader == nil {
+		t.Fatalf("header is %T, want a DD_HEAD", header.Data)
+	}
+	d, err := layer2.DecodeDefinedShortData(h.DefinedDataHeader, blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := hytera.DecodeShortData(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &hytera.Message{Type: hytera.TypeText, Source: 3191868, Destination: 9990, Text: "TEST KI5VMF"}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("got %s\nwant %s", m.ToString(), want.ToString())
+	}
+	if r := m.Response(); r != nil {
+		t.Errorf("Response = %s, want nil for a message that does not ask for one", r.ToString())
+	}
+
+	out, err := m.ShortData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bursts, err := out.Bursts(header.SyncPattern, header.SlotType.ColorCode, elements.DataTypeRate12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bursts) != len(raws) {
+		t.Fatalf("encoded %d bursts, want %d", len(bursts), len(raws))
+	}
+	for i := range bursts {
+		got, err := bursts[i].Encode()
+		if err != nil {
+			t.Fatalf("burst %d: %v", i, err)
+		}
+		if got != raws[i] {
+			t.Errorf("burst %d = % X\nwant      % X", i, got, raws[i])
+		}
+	}
+}
+
+func TestMessage_Response(t *testing.T) {
+	t.Parallel()
+	m := &hytera.Message{Type: hytera.TypeText, Source: 3120001, Destination: 3120002, AckRequired: true, Text: "hi"}
+	want := &layer2.DataResponse{
+		SAP:         pdu.ServiceAccessPointIDShortData,
+		Source:      3120002,
+		Destination: 3120001,
+		Type:        enums.DataResponseACK,
+	}
+	if r := m.Response(); !reflect.DeepEqual(r, want) {
+		t.Errorf("Response = %v, want %s", r, want.ToString())
+	}
+
+	d, err := m.ShortData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.ResponseRequested {
+		t.Error("ShortData does not request a response")
+	}
+	got, err := hytera.DecodeShortData(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("got %s\nwant %s", got.ToString(), m.ToString())
+	}
+
+	m.Group = true
+	if r := m.Response(); r != nil {
+		t.Errorf("group Response = %s, want nil", r.ToString())
+	}
+	_, err = m.Ack(hytera.ResultOK).ShortData()
+	testutil.AssertPDUError(t, err, elements.ErrDataTypeMismatch, elements.LayerApplication, "Type")
+}
+
+func TestDecodeShortData_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		data     []byte
+		bits     int
+		sentinel error
+		field    string
+	}{
+		{"short", []byte{0x00}, 8, elements.ErrInvalidLength, "Data"},
+		{"partial octet", []byte{0x00, 0x00, 0x40}, 18, elements.ErrInvalidLength, "Data"},
+		{"odd text", []byte{0x00, 0x00, 0x41}, 24, elements.ErrInvalidEncoding, "Text"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := hytera.DecodeShortData(&layer2.DefinedShortData{Data: tt.data, BitLength: tt.bits})
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerApplication, tt.field)
+		})
+	}
+}
+
+func TestEncodeTMP_Vector(t *testing.T) {
+	t.Parallel()
+	m := &hytera.Message{
+		Type: hytera.TypeText, Source: 3120001, Destination: 3120002,
+		AckRequired: true, RequestID: 1, Text: "hi",
+	}
+	// The checksum is ^(sum of 00 A1 through 69 00) + 0x33: the octets sum
+	// to 0x42E, ^0x2E is 0xD1 and 0xD1 + 0x33 is 0x04.
+	want := []byte{
+		0x89, 0x00, 0xA1, 0x00, 0x10,
+		0x00, 0x00, 0x00, 0x01,
+		0x0A, 0x2F, 0x9B, 0x82,
+		0x0A, 0x2F, 0x9B, 0x81,
+		0x68, 0x00, 0x69, 0x00,
+		0x04, 0x03,
+	}
+	got, err := m.EncodeTMP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("EncodeTMP = % X\nwant        % X", got, want)
+	}
+
+	ack, err := m.Ack(hytera.ResultOK).EncodeTMP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantAck := []byte{
+		0x09, 0x00, 0xA2, 0x00, 0x0D,
+		0x00, 0x00, 0x00, 0x01,
+		0x0A, 0x2F, 0x9B, 0x81,
+		0x0A, 0x2F, 0x9B, 0x82,
+		0x00,
+		0xD7, 0x03,
+	}
+	if !bytes.Equal(ack, wantAck) {
+		t.Errorf("ack EncodeTMP = % X\nwant            % X", ack, wantAck)
+	}
+}
+
+func TestTMP_RoundTrip(t *testing.T) {
+	t.Parallel()
+	private := &hytera.Message{
+		Type: hytera.TypeText, Source: 3120001, Destination: 3120002,
+		AckRequired: true, RequestID: 0xDEADBEEF, Text: "Grüße 📡",
+	}
+	group := &hytera.Message{Type: hytera.TypeText, Source: 3120001, Destination: 91, Group: true, RequestID: 7, Text: "net check"}
+	tests := []struct {
+		name string
+		msg  *hytera.Message
+	}{
+		{"private", private},
+		{"group", group},
+		{"empty", &hytera.Message{Type: hytera.TypeText, Source: 1, Destination: 2}},
+		{"private ack", private.Ack(hytera.ResultOK)},
+		{"group ack", group.Ack(hytera.ResultOK)},
+		{"failed ack", private.Ack(0x01)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			payload, err := tt.msg.EncodeTMP()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := hytera.DecodeTMP(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.msg) {
+				t.Errorf("got %s\nwant %s", got.ToString(), tt.msg.ToString())
+			}
+		})
+	}
+}
+
+func TestDecodeTMP_Errors(t *testing.T) {
+	t.Parallel()
+	valid, err := (&hytera.Message{Type: hytera.TypeText, Source: 1, Destination: 2, Text: "hi"}).EncodeTMP()
+	if err != nil {
+		t.Fatal(err)
+	}
+	modify := func(f func(p []byte) []byte) []byte {
+		return f(append([]byte{}, valid...))
+	}
+	// fixChecksum recomputes the checksum of a modified packet.
+	fixChecksum := func(p []byte) []byte {
+		var sum byte
+		for _, b := range p[1 : len(p)-2] {
+			sum += b
+		}
+		p[len(p)-2] = ^sum + 0x33
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		payload  []byte
+		sentinel error
+		field    string
+	}{
+		{"short", valid[:6], elements.ErrInvalidLength, "Length"},
+		{"other protocol", modify(func(p []byte) []byte { p[0] = 0x08; return p }), elements.ErrDataTypeMismatch, "Header"},
+		{"length", modify(func(p []byte) []byte { return p[:len(p)-1] }), elements.ErrInvalidLength, "Length"},
+		{"end", modify(func(p []byte) []byte { p[len(p)-1] = 0; return p }), elements.ErrInvalidEncoding, "End"},
+		{"checksum", modify(func(p []byte) []byte { p[len(p)-2]++; return p }), elements.ErrCRCMismatch, "Checksum"},
+		{"opcode", modify(func(p []byte) []byte { p[2] = 0xC1; return fixChecksum(p) }), elements.ErrNotImplemented, "Opcode"},
+		{"odd text", modify(func(p []byte) []byte {
+			p = append(p[:len(p)-3], 0, 0x03)
+			p[4]--
+			return fixChecksum(p)
+		}), elements.ErrInvalidEncoding, "Text"},
+		{"no addresses", fixChecksum([]byte{0x09, 0x00, 0xA1, 0x00, 0x04, 0, 0, 0, 1, 0, 0x03}), elements.ErrInvalidLength, "Payload"},
+		{"ack without result", fixChecksum([]byte{
+			0x09, 0x00, 0xA2, 0x00, 0x0C, 0, 0, 0, 1, 0x0A, 0, 0, 1, 0x0A, 0, 0, 2, 0, 0x03,
+		}), elements.ErrInvalidLength, "Payload"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := hytera.DecodeTMP(tt.payload)
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerApplication, tt.field)
+		})
+	}
+}
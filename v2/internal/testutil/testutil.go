@@ -0,0 +1,25 @@
+// Package testutil holds assertions shared by the tests of this module.
+package testutil
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+)
+
+// AssertPDUError fails the test unless err is an *elements.PDUError for
+// field at layer wrapping sentinel.
+func AssertPDUError(t testing.TB, err, sentinel error, layer, field string) {
+	t.Helper()
+	if !errors.Is(err, sentinel) {
+		t.Fatalf("err = %v, want errors.Is(%v)", err, sentinel)
+	}
+	var pduErr *elements.PDUError
+	if !errors.As(err, &pduErr) {
+		t.Fatalf("err = %T, want *elements.PDUError", err)
+	}
+	if pduErr.Layer != layer || pduErr.Field != field {
+		t.Errorf("PDUError = {%s, %s}, want {%s, %s}", pduErr.Layer, pduErr.Field, layer, field)
+	}
+}
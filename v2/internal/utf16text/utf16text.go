@@ -0,0 +1,37 @@
+// Package utf16text decodes and encodes text as UTF-16 in either byte
+// order, for the text messages of this module.
+package utf16text
+
+import (
+	"encoding/binary"
+	"strings"
+	"unicode/utf16"
+)
+
+// Decode returns the text of UTF-16 data in byte order order. ok is false
+// if data is not a whole number of code units.
+func Decode(data []byte, order binary.ByteOrder) (text string, ok bool) {
+	if len(data)%2 != 0 {
+		return "", false
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units)), true
+}
+
+// DecodeTerminated is Decode without the NUL characters radios send after
+// the text.
+func DecodeTerminated(data []byte, order binary.ByteOrder) (text string, ok bool) {
+	text, ok = Decode(data, order)
+	return strings.TrimRight(text, "\x00"), ok
+}
+
+// Append appends text to data as UTF-16 in byte order order.
+func Append(data []byte, text string, order binary.AppendByteOrder) []byte {
+	for _, u := range utf16.Encode([]rune(text)) {
+		data = order.AppendUint16(data, u)
+	}
+	return data
+}
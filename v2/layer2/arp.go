@@ -0,0 +1,247 @@
+package layer2
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/constants"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// Address Resolution Protocol (SAP 5)
+//
+// A radio that knows only the IPv4 address of a destination broadcasts an
+// ARP request for it; the radio with that address, or a gateway on its
+// behalf, replies with its DMR ID. Both ends learn the sender's binding
+// from every ARP message they receive.
+
+// defaultARPTimeout is how long an ARPResolver keeps a binding when none
+// is configured.
+const defaultARPTimeout = 20 * time.Minute
+
+// ARPMessage is an ARP request or reply binding IPv4 addresses to DMR IDs.
+type ARPMessage struct {
+	// Operation is pdu.ARPOperationRequest or pdu.ARPOperationReply.
+	Operation uint16
+	// HardwareType is carried as received. A reply uses the request's.
+	HardwareType uint16
+	// SenderLLID and SenderAddress bind the sender's DMR ID to its IPv4
+	// address.
+	SenderLLID    int
+	SenderAddress netip.Addr
+	// TargetLLID and TargetAddress are the binding asked for, with
+	// TargetLLID zero in a request.
+	TargetLLID    int
+	TargetAddress netip.Addr
+}
+
+// ToString returns a string representation of the message.
+func (m *ARPMessage) ToString() string {
+	return fmt.Sprintf("ARPMessage{ Operation: %d, HardwareType: %d, SenderLLID: %d, SenderAddress: %s, TargetLLID: %d, TargetAddress: %s }",
+		m.Operation, m.HardwareType, m.SenderLLID, m.SenderAddress, m.TargetLLID, m.TargetAddress)
+}
+
+// DecodeARP returns the ARP message carried by a message sent with SAP
+// AddressResolutionProtocol.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrDataTypeMismatch
+// for another SAP, elements.ErrInvalidLength for a truncated packet, or
+// elements.ErrNotImplemented for protocol addresses other than IPv4 or
+// hardware addresses other than 3-octet DMR IDs.
+func DecodeARP(msg *PacketData) (*ARPMessage, error) {
+	if msg.SAP != pdu.ServiceAccessPointIDAddressResolutionProtocol {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "SAP", Err: elements.ErrDataTypeMismatch}
+	}
+	if len(msg.Payload) < pdu.ARPOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "ARPPacket", Err: elements.ErrInvalidLength}
+	}
+	var bits [pdu.ARPOctets * 8]bit.Bit
+	copy(bits[:], bit.UnpackBits(msg.Payload[:pdu.ARPOctets]))
+	p, _ := pdu.DecodeARPPacket(bits)
+	switch {
+	case p.ProtocolType != pdu.ARPProtocolTypeIPv4 || p.ProtocolAddressLength != pdu.ARPProtocolAddressLengthIPv4:
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "ProtocolType", Err: elements.ErrNotImplemented}
+	case p.HardwareAddressLength != pdu.ARPHardwareAddressLengthDMR:
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "HardwareAddressLength", Err: elements.ErrNotImplemented}
+	}
+	return &ARPMessage{
+		Operation:     p.Operation,
+		HardwareType:  p.HardwareType,
+		SenderLLID:    p.SenderHardwareAddress,
+		SenderAddress: arpAddress(p.SenderProtocolAddress),
+		TargetLLID:    p.TargetHardwareAddress,
+		TargetAddress: arpAddress(p.TargetProtocolAddress),
+	}, nil
+}
+
+// Message returns the message for SAP AddressResolutionProtocol that
+// carries the ARP message from SenderLLID to destination.
+func (m *ARPMessage) Message(destination RadioAddr) *PacketData {
+	bits := pdu.EncodeARPPacket(&pdu.ARPPacket{
+		HardwareType:          m.HardwareType,
+		ProtocolType:          pdu.ARPProtocolTypeIPv4,
+		HardwareAddressLength: pdu.ARPHardwareAddressLengthDMR,
+		ProtocolAddressLength: pdu.ARPProtocolAddressLengthIPv4,
+		Operation:             m.Operation,
+		SenderHardwareAddress: m.SenderLLID & llidMask,
+		SenderProtocolAddress: arpProtocolAddress(m.SenderAddress),
+		TargetHardwareAddress: m.TargetLLID & llidMask,
+		TargetProtocolAddress: arpProtocolAddress(m.TargetAddress),
+	})
+	return &PacketData{
+		SAP:         pdu.ServiceAccessPointIDAddressResolutionProtocol,
+		Source:      m.SenderLLID,
+		Destination: destination.LLID,
+		Group:       destination.Group,
+		Payload:     bit.PackBits(bits[:]),
+	}
+}
+
+// arpAddress returns the IPv4 address of an ARP protocol address.
+func arpAddress(a uint32) netip.Addr {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], a)
+	return netip.AddrFrom4(b)
+}
+
+// arpProtocolAddress returns the ARP protocol address of an IPv4 address,
+// or zero for an invalid or IPv6 address.
+func arpProtocolAddress(a netip.Addr) uint32 {
+	if !a.Is4() {
+		return 0
+	}
+	b := a.As4()
+	return binary.BigEndian.Uint32(b[:])
+}
+
+// arpEntry is a binding held by an ARPResolver.
+type arpEntry struct {
+	llid    int
+	expires time.Time
+}
+
+// ARPResolver keeps the bindings of IPv4 addresses to DMR IDs learned from
+// ARP, and answers requests for its own address and, through Proxy, for
+// the radios a gateway registers. Its methods may be called from several
+// goroutines; set the fields before first use. The zero value is ready to
+// use.
+type ARPResolver struct {
+	// LLID and Address are the station's own binding, sent in its
+	// requests and replies.
+	LLID    int
+	Address netip.Addr
+	// HardwareType is sent in requests.
+	HardwareType uint16
+	// Timeout is how long a learned binding is kept. Zero uses 20 minutes.
+	Timeout time.Duration
+	// RequestDestination is the radio or talkgroup requests are sent to.
+	// The zero value uses the all MS ID, constants.AllMSID.
+	RequestDestination RadioAddr
+	// Proxy, when set, answers requests for addresses other than Address,
+	// returning the DMR ID to reply with and true for an address the
+	// station answers for. It is called without the resolver locked, so
+	// it may call Lookup and Add.
+	Proxy func(addr netip.Addr) (int, bool)
+
+	mu      sync.Mutex
+	entries map[netip.Addr]arpEntry
+}
+
+// Lookup returns the DMR ID bound to an address at time now.
+func (r *ARPResolver) Lookup(addr netip.Addr, now time.Time) (int, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.entries[addr]
+	if !ok || !now.Before(e.expires) {
+		return 0, false
+	}
+	return e.llid, true
+}
+
+// Add binds an address to a DMR ID at time now, for Timeout.
+func (r *ARPResolver) Add(addr netip.Addr, llid int, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.add(addr, llid, now)
+}
+
+// add binds an address to a DMR ID; the caller holds mu.
+func (r *ARPResolver) add(addr netip.Addr, llid int, now time.Time) {
+	if !addr.IsValid() || addr.IsUnspecified() {
+		return
+	}
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = defaultARPTimeout
+	}
+	if r.entries == nil {
+		r.entries = make(map[netip.Addr]arpEntry)
+	}
+	r.entries[addr] = arpEntry{llid: llid, expires: now.Add(timeout)}
+}
+
+// Expire forgets the bindings that have timed out at time now.
+func (r *ARPResolver) Expire(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for addr, e := range r.entries {
+		if !now.Before(e.expires) {
+			delete(r.entries, addr)
+		}
+	}
+}
+
+// Request returns the message asking for the DMR ID of an address.
+func (r *ARPResolver) Request(addr netip.Addr) *PacketData {
+	to := r.RequestDestination
+	if to == (RadioAddr{}) {
+		to = RadioAddr{LLID: constants.AllMSID, Group: true}
+	}
+	m := ARPMessage{
+		Operation:     pdu.ARPOperationRequest,
+		HardwareType:  r.HardwareType,
+		SenderLLID:    r.LLID,
+		SenderAddress: r.Address,
+		TargetAddress: addr,
+	}
+	return m.Message(to)
+}
+
+// Receive handles a message sent with SAP AddressResolutionProtocol at
+// time now. It learns the sender's binding from a request or reply, and
+// returns the reply to send to a request for Address or an address Proxy
+// answers for. Errors are as for DecodeARP.
+func (r *ARPResolver) Receive(msg *PacketData, now time.Time) (*PacketData, error) {
+	m, err := DecodeARP(msg)
+	if err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	r.add(m.SenderAddress, m.SenderLLID, now)
+	r.mu.Unlock()
+	if m.Operation != pdu.ARPOperationRequest {
+		return nil, nil
+	}
+
+	llid, ok := r.LLID, r.Address.IsValid() && m.TargetAddress == r.Address
+	if !ok && r.Proxy != nil {
+		llid, ok = r.Proxy(m.TargetAddress)
+	}
+	if !ok {
+		return nil, nil
+	}
+	reply := ARPMessage{
+		Operation:     pdu.ARPOperationReply,
+		HardwareType:  m.HardwareType,
+		SenderLLID:    llid,
+		SenderAddress: m.TargetAddress,
+		TargetLLID:    m.SenderLLID,
+		TargetAddress: m.SenderAddress,
+	}
+	return reply.Message(RadioAddr{LLID: m.SenderLLID}), nil
+}
@@ -0,0 +1,205 @@
+package layer2_test
+
+import (
+	"bytes"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/constants"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+func arpMessage(payload []byte) *layer2.PacketData {
+	return &layer2.PacketData{SAP: pdu.ServiceAccessPointIDAddressResolutionProtocol, Source: 3120001, Destination: constants.AllMSID, Group: true, Payload: payload}
+}
+
+func TestDecodeARP_Vector(t *testing.T) {
+	t.Parallel()
+	// A request from 3120001 at 10.0.0.1 for 10.0.0.2.
+	payload := []byte{
+		0x00, 0x01, 0x08, 0x00, 0x03, 0x04, 0x00, 0x01,
+		0x2F, 0x9B, 0x81, 0x0A, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x0A, 0x00, 0x00, 0x02,
+	}
+	m, err := layer2.DecodeARP(arpMessage(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := layer2.ARPMessage{
+		Operation:     pdu.ARPOperationRequest,
+		HardwareType:  1,
+		SenderLLID:    3120001,
+		SenderAddress: netip.MustParseAddr("10.0.0.1"),
+		TargetAddress: netip.MustParseAddr("10.0.0.2"),
+	}
+	if *m != want {
+		t.Fatalf("DecodeARP = %s", m.ToString())
+	}
+
+	msg := m.Message(layer2.RadioAddr{LLID: constants.AllMSID, Group: true})
+	if !bytes.Equal(msg.Payload, payload) || msg.SAP != pdu.ServiceAccessPointIDAddressResolutionProtocol ||
+		msg.Source != 3120001 || msg.Destination != constants.AllMSID || !msg.Group {
+		t.Errorf("Message = %s", msg.ToString())
+	}
+}
+
+func TestDecodeARP_Errors(t *testing.T) {
+	t.Parallel()
+	valid := (&layer2.ARPMessage{Operation: pdu.ARPOperationRequest}).Message(layer2.RadioAddr{}).Payload
+	with := func(offset int, v byte) []byte {
+		p := bytes.Clone(valid)
+		p[offset] = v
+		return p
+	}
+	tests := []struct {
+		name  string
+		msg   *layer2.PacketData
+		err   error
+		field string
+	}{
+		{"SAP", &layer2.PacketData{SAP: pdu.ServiceAccessPointIDIPBasedPacketData, Payload: valid}, elements.ErrDataTypeMismatch, "SAP"},
+		{"short", arpMessage(valid[:21]), elements.ErrInvalidLength, "ARPPacket"},
+		{"protocol type", arpMessage(with(2, 0x86)), elements.ErrNotImplemented, "ProtocolType"},
+		{"protocol length", arpMessage(with(5, 16)), elements.ErrNotImplemented, "ProtocolType"},
+		{"hardware length", arpMessage(with(4, 6)), elements.ErrNotImplemented, "HardwareAddressLength"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := layer2.DecodeARP(tt.msg)
+			testutil.AssertPDUError(t, err, tt.err, elements.LayerPacket, tt.field)
+		})
+	}
+}
+
+func TestARPResolver(t *testing.T) {
+	t.Parallel()
+	own, proxied, other := netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("10.0.0.3"), netip.MustParseAddr("10.0.0.4")
+	r := layer2.ARPResolver{
+		LLID:    3120002,
+		Address: own,
+		Timeout: time.Minute,
+		Proxy: func(addr netip.Addr) (int, bool) {
+			return 3120003, addr == proxied
+		},
+	}
+	requester := layer2.ARPResolver{LLID: 3120001, Address: netip.MustParseAddr("10.0.0.1"), HardwareType: 1}
+	now := time.Now()
+
+	for _, tt := range []struct {
+		target netip.Addr
+		llid   int
+	}{{own, 3120002}, {proxied, 3120003}, {other, 0}} {
+		req := requester.Request(tt.target)
+		if req.Destination != constants.AllMSID || !req.Group {
+			t.Errorf("request to %d, group %t", req.Destination, req.Group)
+		}
+		reply, err := r.Receive(req, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.llid == 0 {
+			if reply != nil {
+				t.Errorf("%s: unexpected reply %s", tt.target, reply.ToString())
+			}
+			continue
+		}
+		if reply == nil || reply.Destination != 3120001 || reply.Group {
+			t.Fatalf("%s: reply = %v", tt.target, reply)
+		}
+		m, err := layer2.DecodeARP(reply)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := layer2.ARPMessage{
+			Operation:     pdu.ARPOperationReply,
+			HardwareType:  1,
+			SenderLLID:    tt.llid,
+			SenderAddress: tt.target,
+			TargetLLID:    3120001,
+			TargetAddress: requester.Address,
+		}
+		if *m != want {
+			t.Errorf("%s: reply = %s", tt.target, m.ToString())
+		}
+
+		// The requester learns the binding from the reply.
+		if _, err := requester.Receive(reply, now); err != nil {
+			t.Fatal(err)
+		}
+		if llid, ok := requester.Lookup(tt.target, now); !ok || llid != tt.llid {
+			t.Errorf("%s: Lookup = %d, %t", tt.target, llid, ok)
+		}
+	}
+
+	// The responder learned the requester from its requests, until the
+	// binding expires.
+	if llid, ok := r.Lookup(requester.Address, now.Add(59*time.Second)); !ok || llid != 3120001 {
+		t.Errorf("Lookup = %d, %t", llid, ok)
+	}
+	if _, ok := r.Lookup(requester.Address, now.Add(time.Minute)); ok {
+		t.Error("binding did not expire")
+	}
+	r.Add(other, 3120004, now.Add(2*time.Minute))
+	r.Expire(now.Add(2 * time.Minute))
+	if _, ok := r.Lookup(requester.Address, now); ok {
+		t.Error("Expire kept an expired binding")
+	}
+	if llid, ok := r.Lookup(other, now.Add(2*time.Minute)); !ok || llid != 3120004 {
+		t.Errorf("Lookup after Add = %d, %t", llid, ok)
+	}
+}
+
+// A gateway answers for the radios in its own cache.
+func TestARPResolver_ProxyLookup(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+	proxied := netip.MustParseAddr("10.0.0.3")
+	r := &layer2.ARPResolver{LLID: 3120002, Address: netip.MustParseAddr("10.0.0.2")}
+	r.Proxy = func(addr netip.Addr) (int, bool) {
+		return r.Lookup(addr, now)
+	}
+	r.Add(proxied, 3120003, now)
+
+	requester := layer2.ARPResolver{LLID: 3120001, Address: netip.MustParseAddr("10.0.0.1")}
+	reply, err := r.Receive(requester.Request(proxied), now)
+	if err != nil || reply == nil {
+		t.Fatalf("reply = %v, err = %v", reply, err)
+	}
+	m, err := layer2.DecodeARP(reply)
+	if err != nil || m.SenderLLID != 3120003 || m.SenderAddress != proxied {
+		t.Errorf("reply = %v, err = %v", m, err)
+	}
+}
+
+func TestIPConn_ARP(t *testing.T) {
+	t.Parallel()
+	a := &layer2.IPConn{LLID: 1, ARP: &layer2.ARPResolver{LLID: 1, Address: netip.MustParseAddr("10.0.0.1")}}
+	b := &layer2.IPConn{LLID: 2, ARP: &layer2.ARPResolver{LLID: 2, Address: netip.MustParseAddr("10.0.0.2")}}
+	saps := ipConnPair(t, a, b)
+	datagram := tcpDatagram(&tcpSegment{src: "10.0.0.1", dst: "10.0.0.2", flags: tcpSYN})
+
+	// The first write has no route: it sends a request, and b's reply
+	// resolves the address.
+	_, err := a.Write(datagram)
+	var pduErr *elements.PDUError
+	if !errors.As(err, &pduErr) || pduErr.Field != "DestinationAddress" {
+		t.Fatalf("Write = %v", err)
+	}
+	if len(*saps) != 1 || (*saps)[0] != pdu.ServiceAccessPointIDAddressResolutionProtocol {
+		t.Fatalf("SAPs = %v", *saps)
+	}
+
+	if _, err := a.Write(datagram); err != nil {
+		t.Fatal(err)
+	}
+	got, from := readDatagram(t, b)
+	if !bytes.Equal(got, datagram) || from != (layer2.RadioAddr{LLID: 1}) {
+		t.Errorf("datagram from %v:\n got % X\nwant % X", from, got, datagram)
+	}
+}
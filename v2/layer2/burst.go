@@ -7,6 +7,7 @@ import (
 	"github.com/USA-RedDragon/dmrgo/v2/enums"
 	"github.com/USA-RedDragon/dmrgo/v2/fec"
 	"github.com/USA-RedDragon/dmrgo/v2/fec/bptc"
+	"github.com/USA-RedDragon/dmrgo/v2/fec/reed_solomon"
 	trellis34 "github.com/USA-RedDragon/dmrgo/v2/fec/trellis"
 	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
 	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
@@ -15,6 +16,7 @@ import (
 // Burst represents a DMR burst.
 type Burst struct {
 	SyncPattern enums.SyncPattern
+	SyncMatch   enums.SyncMatch
 	VoiceBurst  enums.VoiceBurstType
 
 	VoiceData pdu.Vocoder
@@ -33,6 +35,7 @@ type Burst struct {
 	Data                  elements.Data
 	FEC                   fec.BurstFECStats
 	TrunkingMode          bool
+	SyncMaxBitErrors      int
 	fullLinkControl       *pdu.FullLinkControl
 	csbk                  *pdu.CSBK
 	dataHeader            *pdu.DataHeader
@@ -45,6 +48,13 @@ type Burst struct {
 	bitData               [264]bit.Bit
 	deinterleavedInfoBits [196]bit.Bit
 	deinterleavedInfoLen  int
+
+	// opaqueData is the decoded Data PDU when its typed form cannot hold
+	// what was received, so that Encode replays the captured bits instead.
+	// opaqueTyped is its typed encoding at decode, which tells Encode
+	// whether the caller has since edited it.
+	opaqueData  elements.Data
+	opaqueTyped [96]bit.Bit
 }
 
 // SetTrunkingMode sets whether the burst should be decoded as Tier III trunking.
@@ -53,6 +63,13 @@ func (b *Burst) SetTrunkingMode(mode bool) {
 	b.TrunkingMode = mode
 }
 
+// SetSyncMaxBitErrors sets the number of SYNC bit errors tolerated when
+// classifying a burst. Zero (the default) requires an exact SYNC match; see
+// enums.SyncMaxUnambiguousBitErrors for the largest unambiguous threshold.
+func (b *Burst) SetSyncMaxBitErrors(maxBitErrors int) {
+	b.SyncMaxBitErrors = maxBitErrors
+}
+
 // NewBurstFromBytes creates a new Burst from the given bytes.
 func NewBurstFromBytes(data [33]byte) (*Burst, error) {
 	burst := &Burst{}
@@ -63,11 +80,20 @@ func NewBurstFromBytes(data [33]byte) (*Burst, error) {
 // DecodeFromBytes populates the burst in place, enabling zero-allocation decoding when reusing a Burst.
 func (b *Burst) DecodeFromBytes(data [33]byte) error {
 	trunkingMode := b.TrunkingMode
+	syncMaxBitErrors := b.SyncMaxBitErrors
 	*b = Burst{}
 	b.TrunkingMode = trunkingMode
+	b.SyncMaxBitErrors = syncMaxBitErrors
 	b.bitData = bit.UnpackBytesToBits264(data)
 
-	b.SyncPattern = extractSyncPattern(b.bitData)
+	b.SyncMatch = extractSyncPattern(b.bitData, b.SyncMaxBitErrors)
+	b.SyncPattern = b.SyncMatch.Pattern
+	if b.SyncMatch.Matched() {
+		b.FEC.Sync = fec.FECResult{
+			BitsChecked:     enums.SyncPatternBits,
+			ErrorsCorrected: b.SyncMatch.Distance,
+		}
+	}
 	b.IsData = isDataSync(b.SyncPattern)
 	b.VoiceBurst, b.HasEmbeddedSignalling = classifyVoice(b.SyncPattern)
 
@@ -88,8 +114,12 @@ func (b *Burst) DecodeFromBytes(data [33]byte) error {
 
 	b.HasSlotType = b.IsData
 	if b.HasSlotType {
-		b.SlotType = parseSlotType(b.bitData)
-		b.FEC.SlotType = b.SlotType.FEC
+		b.SlotType, b.FEC.SlotType = parseSlotType(b.bitData)
+		if b.FEC.SlotType.Uncorrectable {
+			// The data type cannot be trusted, so neither can anything
+			// deinterleaved or decoded by it.
+			return &elements.PDUError{Layer: elements.LayerBurst, Field: "SlotType", Err: elements.ErrUncorrectableFEC}
+		}
 	}
 
 	if !b.IsData {
@@ -99,28 +129,37 @@ func (b *Burst) DecodeFromBytes(data [33]byte) error {
 	}
 
 	bBits := extractDataBits(b.bitData)
-	b.deinterleavedInfoLen, b.FEC.Payload = b.deinterleave(bBits, b.SlotType.DataType)
 	var err error
+	b.deinterleavedInfoLen, b.FEC.Payload, err = b.deinterleave(bBits, b.SlotType.DataType)
+	if err != nil {
+		return err
+	}
 	b.Data, err = b.extractData()
 	return err
 }
 
-func extractSyncPattern(bitData [264]bit.Bit) enums.SyncPattern {
+func extractSyncPattern(bitData [264]bit.Bit, maxBitErrors int) enums.SyncMatch {
 	syncBytes := [6]byte{}
 	for i := 0; i < 6; i++ {
 		for j := 0; j < 8; j++ {
 			syncBytes[i] |= byte(bitData[108+(i*8)+j]) << (7 - j) //nolint:gosec // max index: 108+5*8+7=155 < 264
 		}
 	}
-	return enums.SyncPatternFromBytes(syncBytes)
+	return enums.MatchSyncPattern(syncBytes, maxBitErrors)
 }
 
 func isDataSync(sync enums.SyncPattern) bool {
 	return sync == enums.Tdma1Data || sync == enums.Tdma2Data || sync == enums.MsSourcedData || sync == enums.BsSourcedData
 }
 
+func isVoiceSync(sync enums.SyncPattern) bool {
+	return sync == enums.Tdma2Voice || sync == enums.Tdma1Voice || sync == enums.MsSourcedVoice || sync == enums.BsSourcedVoice
+}
+
+// classifyVoice identifies burst A from its voice SYNC. Bursts B–F all carry
+// the same EMB pattern; use a VoiceSuperframeTracker to place them.
 func classifyVoice(sync enums.SyncPattern) (enums.VoiceBurstType, bool) {
-	if sync == enums.Tdma2Voice || sync == enums.Tdma1Voice || sync == enums.MsSourcedVoice || sync == enums.BsSourcedVoice {
+	if isVoiceSync(sync) {
 		return enums.VoiceBurstA, false
 	}
 	// MsSourcedRcSync (§6.4.1) uses the same burst layout as embedded
@@ -139,12 +178,11 @@ func parseEmbedded(bitData [264]bit.Bit) (pdu.EmbeddedSignalling, [32]bit.Bit) {
 	return embedded, embeddedData
 }
 
-func parseSlotType(bitData [264]bit.Bit) pdu.SlotType {
+func parseSlotType(bitData [264]bit.Bit) (pdu.SlotType, fec.FECResult) {
 	var slotBits [20]bit.Bit
 	copy(slotBits[:10], bitData[98:108])
 	copy(slotBits[10:], bitData[156:166])
-	st, _ := pdu.DecodeSlotType(slotBits)
-	return st
+	return pdu.DecodeSlotType(slotBits)
 }
 
 func parseVoiceBits(bitData [264]bit.Bit) pdu.Vocoder {
@@ -162,13 +200,13 @@ func extractDataBits(bitData [264]bit.Bit) [196]bit.Bit {
 	return bits
 }
 
-func (b *Burst) deinterleave(bits [196]bit.Bit, dataType elements.DataType) (int, fec.FECResult) {
+func (b *Burst) deinterleave(bits [196]bit.Bit, dataType elements.DataType) (int, fec.FECResult, error) {
 	switch dataType {
 	case elements.DataTypeRate34:
 		var t trellis34.Trellis34
 		decoded, result := t.Decode(bits)
 		copy(b.deinterleavedInfoBits[:], decoded[:])
-		return len(decoded), result
+		return len(decoded), result, nil
 	case elements.DataTypeRate1:
 		// Table B.10B: Transmit bit ordering for rate 1 coded data
 		for i := 0; i < 96; i++ {
@@ -177,7 +215,7 @@ func (b *Burst) deinterleave(bits [196]bit.Bit, dataType elements.DataType) (int
 		for i := 0; i < 96; i++ {
 			b.deinterleavedInfoBits[96+i] = bits[100+i]
 		}
-		return 196, fec.FECResult{}
+		return 192, fec.FECResult{}, nil
 	case elements.DataTypePIHeader,
 		elements.DataTypeVoiceLCHeader,
 		elements.DataTypeTerminatorWithLC,
@@ -191,12 +229,27 @@ func (b *Burst) deinterleave(bits [196]bit.Bit, dataType elements.DataType) (int
 		bptc19696 := bptc.BPTC19696{}
 		decoded, result := bptc19696.DeinterleaveDataBits(bits)
 		copy(b.deinterleavedInfoBits[:], decoded[:])
-		return len(decoded), result
+		return len(decoded), result, nil
 	case elements.DataTypeReserved:
-		panic(fmt.Sprintf("Unknown data type %v", dataType))
+		return 0, fec.FECResult{}, reservedDataTypeError()
 	default:
-		panic(fmt.Sprintf("Unhandled data type %v", dataType))
+		// Values 13-15 are reserved as well (Table 6.1).
+		return 0, fec.FECResult{}, reservedDataTypeError()
+	}
+}
+
+func reservedDataTypeError() error {
+	return &elements.PDUError{Layer: elements.LayerBurst, Field: "SlotType.DataType", Err: elements.ErrReservedDataType}
+}
+
+// pduCheckError reports a PDU whose integrity check failed. When the payload
+// FEC was already uncorrectable the failure is attributed to it rather than
+// to the PDU's own check.
+func (b *Burst) pduCheckError(field string, check error) error {
+	if b.FEC.Payload.Uncorrectable {
+		return &elements.PDUError{Layer: elements.LayerBurst, Field: "Payload", Err: elements.ErrUncorrectableFEC}
 	}
+	return &elements.PDUError{Layer: elements.LayerPDU, Field: field, Err: check}
 }
 
 // ToString returns a string representation of the burst.
@@ -228,7 +281,7 @@ func (b *Burst) ToString() string {
 
 func (b *Burst) extractData() (elements.Data, error) {
 	if !b.HasSlotType || b.SlotType.DataType == elements.DataTypeReserved {
-		return nil, fmt.Errorf("burst does not have valid slot type for data extraction")
+		return nil, reservedDataTypeError()
 	}
 
 	dt := b.SlotType.DataType
@@ -243,22 +296,40 @@ func (b *Burst) extractData() (elements.Data, error) {
 		b.csbk = &decoded
 		b.FEC.PDU = fecResult
 		if fecResult.Uncorrectable {
-			return nil, fmt.Errorf("failed to decode CSBK from bits")
+			return nil, b.pduCheckError("CSBK", elements.ErrCRCMismatch)
+		}
+		if !decoded.CSBKOpcode.IsKnown() {
+			b.opaqueData = b.csbk
+			b.opaqueTyped = pdu.EncodeCSBK(b.csbk)
+			if decoded.FID == byte(enums.StandardizedFID) {
+				return b.csbk, &elements.PDUError{Layer: elements.LayerPDU, Field: "CSBK.CSBKOpcode", Err: elements.ErrUnknownOpcode}
+			}
 		}
 		return b.csbk, nil
 	case elements.DataTypeVoiceLCHeader, elements.DataTypeTerminatorWithLC:
 		var sizedBits [96]bit.Bit
 		copy(sizedBits[:], infoBits[:96])
+		pdu.ApplyFLCParityMask(&sizedBits, dt)
 		decoded, fecResult := pdu.DecodeFullLinkControl(sizedBits)
 		decoded.DataType = dt
 		b.fullLinkControl = &decoded
 		b.FEC.PDU = fecResult
 		if fecResult.Uncorrectable {
-			return nil, fmt.Errorf("failed to decode full link control from bits")
+			return nil, b.pduCheckError("FullLinkControl", elements.ErrCRCMismatch)
+		}
+		flco, fid := flcHeader(sizedBits)
+		_, flcoErr := enums.FLCOFromInt(int(flco))
+		_, fidErr := enums.FeatureSetIDFromInt(int(fid))
+		if flcoErr != nil || fidErr != nil {
+			b.opaqueData = b.fullLinkControl
+			b.opaqueTyped = pdu.EncodeFullLinkControl(b.fullLinkControl)
+			if fid == byte(enums.StandardizedFID) {
+				return b.fullLinkControl, &elements.PDUError{Layer: elements.LayerPDU, Field: "FullLinkControl.FLCO", Err: elements.ErrUnknownOpcode}
+			}
 		}
 		return b.fullLinkControl, nil
 	case elements.DataTypePIHeader:
-		return nil, fmt.Errorf("PI header parsing not implemented")
+		return nil, &elements.PDUError{Layer: elements.LayerPDU, Field: "PIHeader", Err: elements.ErrNotImplemented}
 	case elements.DataTypeDataHeader:
 		var sizedBits [96]bit.Bit
 		copy(sizedBits[:], infoBits[:96])
@@ -267,12 +338,12 @@ func (b *Burst) extractData() (elements.Data, error) {
 		b.dataHeader = &decoded
 		b.FEC.PDU = fecResult
 		if fecResult.Uncorrectable {
-			return nil, fmt.Errorf("failed to decode data header from bits: %v", b.dataHeader.ToString())
+			return nil, b.pduCheckError("DataHeader", elements.ErrCRCMismatch)
 		}
 		return b.dataHeader, nil
 	case elements.DataTypeRate34:
-		var sizedBits [96]bit.Bit
-		copy(sizedBits[:], infoBits[:96])
+		var sizedBits [144]bit.Bit
+		copy(sizedBits[:], infoBits[:144])
 		rt, _ := pdu.DecodeRate34Data(sizedBits)
 		rt.DataType = dt
 		b.threeQuarterRateData = &rt
@@ -285,8 +356,8 @@ func (b *Burst) extractData() (elements.Data, error) {
 		b.halfRateData = &rt
 		return b.halfRateData, nil
 	case elements.DataTypeRate1:
-		var sizedBits [96]bit.Bit
-		copy(sizedBits[:], infoBits[:96])
+		var sizedBits [192]bit.Bit
+		copy(sizedBits[:], infoBits[:192])
 		rt, _ := pdu.DecodeRate1Data(sizedBits)
 		rt.DataType = dt
 		b.fullRateData = &rt
@@ -299,7 +370,7 @@ func (b *Burst) extractData() (elements.Data, error) {
 		b.mbcHeader = &decoded
 		b.FEC.PDU = fecResult
 		if fecResult.Uncorrectable {
-			return nil, fmt.Errorf("failed to decode MBC header from bits")
+			return nil, b.pduCheckError("MBCHeader", elements.ErrCRCMismatch)
 		}
 		return b.mbcHeader, nil
 	case elements.DataTypeMBCContinuation:
@@ -321,23 +392,41 @@ func (b *Burst) extractData() (elements.Data, error) {
 		b.usbd = &decoded
 		b.FEC.PDU = fecResult
 		if fecResult.Uncorrectable {
-			return nil, fmt.Errorf("failed to decode USBD from bits")
+			return nil, b.pduCheckError("UnifiedSingleBlockData", elements.ErrCRCMismatch)
 		}
 		return b.usbd, nil
 	case elements.DataTypeReserved:
-		return nil, fmt.Errorf("reserved data type parsing not implemented")
+		return nil, reservedDataTypeError()
 	default:
-		return nil, fmt.Errorf("unhandled data type %v", b.SlotType.DataType)
+		return nil, reservedDataTypeError()
 	}
 }
 
-// Encode returns the encoded bytes of the burst.
-func (b *Burst) Encode() [33]byte {
+// flcHeader returns the FLCO and FID of a Full LC codeword whose checksum
+// passed. The generated decoder folds unknown FLCO and FID values into their
+// defaults, so the corrected codeword is re-read.
+func flcHeader(bits [96]bit.Bit) (flco, fid byte) {
+	rs := reedsolomon.Decode(bit.PackBits(bits[:]))
+	return rs.Data[0] & 0x3F, rs.Data[1]
+}
+
+// Encode returns the encoded bytes of the burst. It fails with a *PDUError
+// wrapping ErrReservedDataType when a data burst's slot type cannot be encoded.
+//
+// A CSBK or Full LC decoded with an opcode, FLCO or FID that has no typed form
+// is re-sent from the bits captured at decode. Its typed fields cannot carry
+// the vendor payload, so editing them fails with a *PDUError wrapping
+// ErrNotImplemented rather than dropping either the edit or the payload.
+// Replacing Data with a new PDU encodes that PDU as usual.
+func (b *Burst) Encode() ([33]byte, error) {
 	var bitData [264]bit.Bit
 
 	if b.IsData {
 		// Encode data payload
-		dataBits := b.encodeDataBits()
+		dataBits, err := b.encodeDataBits()
+		if err != nil {
+			return [33]byte{}, err
+		}
 		copy(bitData[:98], dataBits[:98])
 		copy(bitData[166:264], dataBits[98:196])
 
@@ -372,26 +461,30 @@ func (b *Burst) Encode() [33]byte {
 		}
 	}
 
-	return bit.PackBits264(bitData)
+	return bit.PackBits264(bitData), nil
 }
 
-func (b *Burst) encodeDataBits() [196]bit.Bit {
+func (b *Burst) encodeDataBits() ([196]bit.Bit, error) {
+	info, err := b.infoBits()
+	if err != nil {
+		return [196]bit.Bit{}, err
+	}
 	switch b.SlotType.DataType {
 	case elements.DataTypeRate34:
 		var t trellis34.Trellis34
 		var data [144]bit.Bit
-		copy(data[:], b.deinterleavedInfoBits[:144])
-		return t.Encode(data)
+		copy(data[:], info[:144])
+		return t.Encode(data), nil
 	case elements.DataTypeRate1:
 		var bits [196]bit.Bit
 		for i := 0; i < 96; i++ {
-			bits[i] = b.deinterleavedInfoBits[i]
+			bits[i] = info[i]
 		}
 		// bits[96..99] are reserved (zero)
 		for i := 0; i < 96; i++ {
-			bits[100+i] = b.deinterleavedInfoBits[96+i]
+			bits[100+i] = info[96+i]
 		}
-		return bits
+		return bits, nil
 	case elements.DataTypePIHeader,
 		elements.DataTypeVoiceLCHeader,
 		elements.DataTypeTerminatorWithLC,
@@ -404,12 +497,107 @@ func (b *Burst) encodeDataBits() [196]bit.Bit {
 		elements.DataTypeUnifiedSingleBlock:
 		// BPTC(196,96) types
 		var infoBits [96]bit.Bit
-		copy(infoBits[:], b.deinterleavedInfoBits[:96])
-		return bptc.Encode(infoBits)
+		copy(infoBits[:], info[:96])
+		return bptc.Encode(infoBits), nil
+	case elements.DataTypeReserved:
+		return [196]bit.Bit{}, reservedDataTypeError()
+	default:
+		return [196]bit.Bit{}, reservedDataTypeError()
+	}
+}
+
+// infoBits regenerates the information bits from the typed Data PDU. Bursts
+// without one (a PI header, or a PDU that failed its CRC), or whose PDU has
+// no typed form for its opcode, FLCO or FID, fall back to the bits captured
+// when the burst was decoded.
+func (b *Burst) infoBits() ([196]bit.Bit, error) {
+	if b.Data != nil && b.Data == b.opaqueData {
+		return b.deinterleavedInfoBits, b.checkOpaqueUnchanged()
+	}
+	var info [196]bit.Bit
+	var n int
+	switch data := b.Data.(type) {
+	case nil:
+		return b.deinterleavedInfoBits, nil
+	case *pdu.CSBK:
+		bits := pdu.EncodeCSBK(data)
+		n = copy(info[:], bits[:])
+	case *pdu.FullLinkControl:
+		bits := pdu.EncodeFullLinkControl(data)
+		pdu.ApplyFLCParityMask(&bits, b.SlotType.DataType)
+		n = copy(info[:], bits[:])
+	case *pdu.DataHeader:
+		bits := pdu.EncodeDataHeader(data)
+		n = copy(info[:], bits[:])
+	case *pdu.MBCHeader:
+		bits := pdu.EncodeMBCHeader(data)
+		n = copy(info[:], bits[:])
+	case *pdu.MBCContinuation:
+		bits := pdu.EncodeMBCContinuation(data)
+		n = copy(info[:], bits[:])
+	case *pdu.UnifiedSingleBlockData:
+		bits := pdu.EncodeUnifiedSingleBlockData(data)
+		n = copy(info[:], bits[:])
+	case *pdu.Rate12Data:
+		bits := pdu.EncodeRate12Data(data)
+		n = copy(info[:], bits[:])
+	case *pdu.Rate34Data:
+		bits := pdu.EncodeRate34Data(data)
+		n = copy(info[:], bits[:])
+	case *pdu.Rate1Data:
+		bits := pdu.EncodeRate1Data(data)
+		n = copy(info[:], bits[:])
+	case *pdu.PRFill:
+		n = copy(info[:], pdu.PRFillBits[:])
+	default:
+		return info, &elements.PDUError{Layer: elements.LayerPDU, Field: "Data", Err: elements.ErrDataTypeMismatch}
+	}
+	if n != infoBitsLen(b.SlotType.DataType) {
+		return info, &elements.PDUError{Layer: elements.LayerPDU, Field: "Data", Err: elements.ErrDataTypeMismatch}
+	}
+	return info, nil
+}
+
+// checkOpaqueUnchanged reports an edit to an opaque PDU's typed fields, which
+// the captured bits being replayed would not carry.
+func (b *Burst) checkOpaqueUnchanged() error {
+	var typed [96]bit.Bit
+	field := "CSBK"
+	switch data := b.opaqueData.(type) {
+	case *pdu.CSBK:
+		typed = pdu.EncodeCSBK(data)
+	case *pdu.FullLinkControl:
+		typed = pdu.EncodeFullLinkControl(data)
+		field = "FullLinkControl"
+	}
+	if typed != b.opaqueTyped {
+		return &elements.PDUError{Layer: elements.LayerPDU, Field: field, Err: elements.ErrNotImplemented}
+	}
+	return nil
+}
+
+// infoBitsLen returns the number of information bits carried by a data type.
+func infoBitsLen(dt elements.DataType) int {
+	switch dt {
+	case elements.DataTypeRate34:
+		return 144
+	case elements.DataTypeRate1:
+		return 192
+	case elements.DataTypePIHeader,
+		elements.DataTypeVoiceLCHeader,
+		elements.DataTypeTerminatorWithLC,
+		elements.DataTypeCSBK,
+		elements.DataTypeMBCHeader,
+		elements.DataTypeMBCContinuation,
+		elements.DataTypeDataHeader,
+		elements.DataTypeRate12,
+		elements.DataTypeIdle,
+		elements.DataTypeUnifiedSingleBlock:
+		return 96
 	case elements.DataTypeReserved:
-		panic(fmt.Sprintf("cannot encode reserved data type %v", b.SlotType.DataType))
+		return 0
 	default:
-		panic(fmt.Sprintf("unhandled data type %v", b.SlotType.DataType))
+		return 0
 	}
 }
 
@@ -0,0 +1,270 @@
+package layer2_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+func encodeAndDecode(t *testing.T, burst *layer2.Burst) *layer2.Burst {
+	t.Helper()
+	encoded, err := burst.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	decoded, err := layer2.NewBurstFromBytes(encoded)
+	if err != nil {
+		t.Fatalf("NewBurstFromBytes: %v", err)
+	}
+	return decoded
+}
+
+// firstDataBurst returns the first burst in file carrying the given data type.
+func firstDataBurst(t *testing.T, file string, dt elements.DataType) *layer2.Burst {
+	t.Helper()
+	for _, raw := range loadBursts(t, file) {
+		burst, err := layer2.NewBurstFromBytes(raw)
+		if err == nil && burst.IsData && burst.SlotType.DataType == dt {
+			return burst
+		}
+	}
+	t.Fatalf("no %s burst in %s", elements.DataTypeToName(dt), file)
+	return nil
+}
+
+func TestBurst_Encode_MatchesCapturedData(t *testing.T) {
+	t.Parallel()
+	files := []string{
+		"testdata/parrot_kerchunk.bin",
+		"testdata/voice.bin",
+		"testdata/m-sms.bin",
+		"testdata/h-sms.bin",
+		"testdata/d-sms.bin",
+	}
+	for _, file := range files {
+		for i, raw := range loadBursts(t, file) {
+			burst, err := layer2.NewBurstFromBytes(raw)
+			if err != nil {
+				t.Fatalf("%s burst %d: %v", file, i, err)
+			}
+			if !burst.IsData || burst.FEC.Aggregate().ErrorsCorrected != 0 {
+				continue
+			}
+			encoded, err := burst.Encode()
+			if err != nil {
+				t.Fatalf("%s burst %d: Encode: %v", file, i, err)
+			}
+			if encoded != raw {
+				t.Errorf("%s burst %d (%s) not reproduced from %T:\n got %x\nwant %x",
+					file, i, elements.DataTypeToName(burst.SlotType.DataType), burst.Data, encoded, raw)
+			}
+		}
+	}
+}
+
+func TestBurst_Encode_FromModifiedCSBK(t *testing.T) {
+	t.Parallel()
+	burst := firstDataBurst(t, "testdata/m-sms.bin", elements.DataTypeCSBK)
+	csbk, ok := burst.Data.(*pdu.CSBK)
+	if !ok || csbk.PreamblePDU == nil {
+		t.Fatalf("Data = %s, want a preamble CSBK", burst.Data.ToString())
+	}
+	csbk.PreamblePDU.CSBKBlocksToFollow = 42
+
+	got, ok := encodeAndDecode(t, burst).Data.(*pdu.CSBK)
+	if !ok || got.PreamblePDU == nil {
+		t.Fatalf("re-decoded Data is not a preamble CSBK")
+	}
+	if got.PreamblePDU.CSBKBlocksToFollow != 42 {
+		t.Errorf("CSBKBlocksToFollow = %d, want 42", got.PreamblePDU.CSBKBlocksToFollow)
+	}
+}
+
+func TestBurst_Encode_FromModifiedFullLinkControl(t *testing.T) {
+	t.Parallel()
+	for _, dt := range []elements.DataType{elements.DataTypeVoiceLCHeader, elements.DataTypeTerminatorWithLC} {
+		burst := firstDataBurst(t, "testdata/voice.bin", dt)
+		flc, ok := burst.Data.(*pdu.FullLinkControl)
+		if !ok || flc.UnitToUnit == nil {
+			t.Fatalf("Data = %s, want a unit-to-unit FLC", burst.Data.ToString())
+		}
+		flc.UnitToUnit.SourceAddress = 3120001
+
+		got, ok := encodeAndDecode(t, burst).Data.(*pdu.FullLinkControl)
+		if !ok || got.UnitToUnit == nil {
+			t.Fatalf("re-decoded Data is not a unit-to-unit FLC")
+		}
+		if got.UnitToUnit.SourceAddress != 3120001 {
+			t.Errorf("%s: SourceAddress = %d, want 3120001", elements.DataTypeToName(dt), got.UnitToUnit.SourceAddress)
+		}
+	}
+}
+
+func TestBurst_Encode_FromModifiedDataHeader(t *testing.T) {
+	t.Parallel()
+	burst := firstDataBurst(t, "testdata/d-sms.bin", elements.DataTypeDataHeader)
+	header, ok := burst.Data.(*pdu.DataHeader)
+	if !ok || header.UnconfirmedDataHeader == nil {
+		t.Fatalf("Data = %s, want an unconfirmed data header", burst.Data.ToString())
+	}
+	header.UnconfirmedDataHeader.BlocksToFollow = 9
+
+	got, ok := encodeAndDecode(t, burst).Data.(*pdu.DataHeader)
+	if !ok || got.UnconfirmedDataHeader == nil {
+		t.Fatalf("re-decoded Data is not an unconfirmed data header")
+	}
+	if got.UnconfirmedDataHeader.BlocksToFollow != 9 {
+		t.Errorf("BlocksToFollow = %d, want 9", got.UnconfirmedDataHeader.BlocksToFollow)
+	}
+}
+
+func TestBurst_Encode_ReplaysOpaquePDUs(t *testing.T) {
+	t.Parallel()
+	flc := func(fid enums.FeatureSetID, flco enums.FLCO) [12]byte {
+		return infoBitsToBytes(pdu.EncodeFullLinkControl(&pdu.FullLinkControl{
+			FLCO:         flco,
+			FeatureSetID: fid,
+			GroupVoice:   &pdu.FLCGroupVoice{GroupAddress: 0x010203, SourceAddress: 0x040506},
+		}))
+	}
+	tests := []struct {
+		name string
+		info [12]byte
+		dt   elements.DataType
+	}{
+		{
+			// A Motorola CSBK with opcode 0x3E, which has no typed form.
+			"vendor CSBK",
+			[12]byte{0xBE, 0x10, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x0F, 0x53},
+			elements.DataTypeCSBK,
+		},
+		{"vendor FLC", flc(enums.MotorolaLtd, 0x3F), elements.DataTypeVoiceLCHeader},
+		{"unknown FLCO", flc(enums.StandardizedFID, 0x3F), elements.DataTypeTerminatorWithLC},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			raw := layer2.BuildLCDataBurst(tt.info, tt.dt, 1)
+			// An unknown FID 0 opcode is reported, but the burst is kept.
+			burst, _ := layer2.NewBurstFromBytes(raw)
+			if burst.Data == nil {
+				t.Fatal("Data = nil, want the decoded PDU")
+			}
+			encoded, err := burst.Encode()
+			if err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			if encoded != raw {
+				t.Errorf("Encode = %x\nwant     %x", encoded, raw)
+			}
+		})
+	}
+}
+
+func TestBurst_Encode_EditedOpaquePDU(t *testing.T) {
+	t.Parallel()
+	// A Motorola CSBK with opcode 0x3E, which has no typed form.
+	info := [12]byte{0xBE, 0x10, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x0F, 0x53}
+	burst, err := layer2.NewBurstFromBytes(layer2.BuildLCDataBurst(info, elements.DataTypeCSBK, 1))
+	if err != nil {
+		t.Fatalf("NewBurstFromBytes: %v", err)
+	}
+	csbk, ok := burst.Data.(*pdu.CSBK)
+	if !ok {
+		t.Fatalf("Data = %T, want *pdu.CSBK", burst.Data)
+	}
+	csbk.ProtectFlag = !csbk.ProtectFlag
+
+	// The typed fields cannot carry the vendor payload, so the edit is
+	// reported rather than dropped.
+	_, err = burst.Encode()
+	testutil.AssertPDUError(t, err, elements.ErrNotImplemented, elements.LayerPDU, "CSBK")
+
+	csbk.ProtectFlag = !csbk.ProtectFlag
+	if _, err := burst.Encode(); err != nil {
+		t.Errorf("Encode after undoing the edit: %v", err)
+	}
+}
+
+func TestBurst_Encode_FromConstructedData(t *testing.T) {
+	t.Parallel()
+	var payload [76]bit.Bit
+	payload[0], payload[75] = 1, 1
+	var mbcData [64]bit.Bit
+	mbcData[10] = 1
+
+	tests := []struct {
+		name  string
+		data  elements.Data
+		dt    elements.DataType
+		check func(elements.Data) bool
+	}{
+		{"USBD", &pdu.UnifiedSingleBlockData{ServiceType: pdu.ServiceTypeManufacturerSpecific1, Payload: payload}, elements.DataTypeUnifiedSingleBlock,
+			func(d elements.Data) bool {
+				u, ok := d.(*pdu.UnifiedSingleBlockData)
+				return ok && u.ServiceType == pdu.ServiceTypeManufacturerSpecific1 && u.Payload == payload
+			}},
+		{"MBCHeader", &pdu.MBCHeader{CSBKOpcode: pdu.CSBKAckvitation, Data: mbcData}, elements.DataTypeMBCHeader,
+			func(d elements.Data) bool {
+				m, ok := d.(*pdu.MBCHeader)
+				return ok && m.CSBKOpcode == pdu.CSBKAckvitation && m.Data == mbcData
+			}},
+		{"Rate12", &pdu.Rate12Data{Data: [12]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}}, elements.DataTypeRate12,
+			func(d elements.Data) bool {
+				r, ok := d.(*pdu.Rate12Data)
+				return ok && r.Data == [12]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+			}},
+		{"Rate34", &pdu.Rate34Data{Data: [18]byte{17: 0xA5}}, elements.DataTypeRate34,
+			func(d elements.Data) bool {
+				r, ok := d.(*pdu.Rate34Data)
+				return ok && r.Data == [18]byte{17: 0xA5}
+			}},
+		{"Rate1", &pdu.Rate1Data{Data: [24]byte{23: 0x5A}}, elements.DataTypeRate1,
+			func(d elements.Data) bool {
+				r, ok := d.(*pdu.Rate1Data)
+				return ok && r.Data == [24]byte{23: 0x5A}
+			}},
+		{"Idle", &pdu.PRFill{}, elements.DataTypeIdle,
+			func(d elements.Data) bool {
+				_, ok := d.(*pdu.PRFill)
+				return ok
+			}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			burst := &layer2.Burst{
+				SyncPattern: enums.BsSourcedData,
+				IsData:      true,
+				HasSlotType: true,
+				SlotType:    pdu.SlotType{ColorCode: 7, DataType: tt.dt},
+				Data:        tt.data,
+			}
+			got := encodeAndDecode(t, burst)
+			if got.SlotType.DataType != tt.dt || got.SlotType.ColorCode != 7 {
+				t.Errorf("SlotType = %s", got.SlotType.ToString())
+			}
+			if !tt.check(got.Data) {
+				t.Errorf("Data = %s", got.Data.ToString())
+			}
+		})
+	}
+}
+
+func TestBurst_Encode_DataTypeMismatch(t *testing.T) {
+	t.Parallel()
+	burst := &layer2.Burst{
+		SyncPattern: enums.BsSourcedData,
+		IsData:      true,
+		HasSlotType: true,
+		SlotType:    pdu.SlotType{DataType: elements.DataTypeCSBK},
+		Data:        &pdu.Rate34Data{},
+	}
+	_, err := burst.Encode()
+	testutil.AssertPDUError(t, err, elements.ErrDataTypeMismatch, elements.LayerPDU, "Data")
+}
@@ -0,0 +1,118 @@
+package layer2_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+func infoBitsToBytes(bits [96]bit.Bit) [12]byte {
+	var out [12]byte
+	copy(out[:], bit.PackBits(bits[:]))
+	return out
+}
+
+func TestBurst_Decode_ReservedDataType(t *testing.T) {
+	t.Parallel()
+	raw := layer2.BuildLCDataBurst([12]byte{}, elements.DataTypeReserved, 1)
+
+	burst, err := layer2.NewBurstFromBytes(raw)
+	testutil.AssertPDUError(t, err, elements.ErrReservedDataType, elements.LayerBurst, "SlotType.DataType")
+	if burst.Data != nil {
+		t.Errorf("Data = %v, want nil", burst.Data)
+	}
+}
+
+func TestBurst_Encode_ReservedDataType(t *testing.T) {
+	t.Parallel()
+	for _, dt := range []elements.DataType{elements.DataTypeReserved, 13, 15} {
+		burst := &layer2.Burst{
+			SyncPattern: enums.BsSourcedData,
+			IsData:      true,
+			HasSlotType: true,
+			SlotType:    pdu.SlotType{DataType: dt},
+		}
+		_, err := burst.Encode()
+		testutil.AssertPDUError(t, err, elements.ErrReservedDataType, elements.LayerBurst, "SlotType.DataType")
+	}
+}
+
+func TestBurst_Decode_CSBKCRCMismatch(t *testing.T) {
+	t.Parallel()
+	csbk := pdu.CSBK{
+		LastBlock:   true,
+		CSBKOpcode:  pdu.CSBKPreamblePDU,
+		PreamblePDU: &pdu.PreamblePDU{},
+	}
+	bits := pdu.EncodeCSBK(&csbk)
+	bits[95] ^= 1
+
+	burst, err := layer2.NewBurstFromBytes(layer2.BuildLCDataBurst(infoBitsToBytes(bits), elements.DataTypeCSBK, 1))
+	testutil.AssertPDUError(t, err, elements.ErrCRCMismatch, elements.LayerPDU, "CSBK")
+	if !burst.FEC.PDU.Uncorrectable {
+		t.Error("FEC.PDU.Uncorrectable = false, want true")
+	}
+}
+
+func TestBurst_Decode_SlotTypeUncorrectable(t *testing.T) {
+	t.Parallel()
+	raw := layer2.BuildLCDataBurst([12]byte{}, elements.DataTypeCSBK, 1)
+	// Four errors in the first Golay(20,8) bits of the slot type (burst
+	// bits 98-101) are beyond its correction capability.
+	raw[12] ^= 0b00111100
+
+	burst, err := layer2.NewBurstFromBytes(raw)
+	testutil.AssertPDUError(t, err, elements.ErrUncorrectableFEC, elements.LayerBurst, "SlotType")
+	if !burst.FEC.SlotType.Uncorrectable {
+		t.Error("FEC.SlotType.Uncorrectable = false, want true")
+	}
+	if burst.Data != nil {
+		t.Errorf("Data = %v, want nil", burst.Data)
+	}
+}
+
+func TestBurst_Decode_CSBKUnknownOpcode(t *testing.T) {
+	t.Parallel()
+	const unknown = pdu.CSBKOpcode(0b00111111)
+	if unknown.IsKnown() {
+		t.Fatalf("opcode %08b unexpectedly known", byte(unknown))
+	}
+
+	csbk := pdu.CSBK{LastBlock: true, CSBKOpcode: unknown}
+	bits := pdu.EncodeCSBK(&csbk)
+	burst, err := layer2.NewBurstFromBytes(layer2.BuildLCDataBurst(infoBitsToBytes(bits), elements.DataTypeCSBK, 1))
+	testutil.AssertPDUError(t, err, elements.ErrUnknownOpcode, elements.LayerPDU, "CSBK.CSBKOpcode")
+	if burst.Data == nil {
+		t.Error("Data = nil, want the decoded CSBK for inspection")
+	}
+
+	// Manufacturer-specific opcodes are not ours to judge.
+	csbk.FID = byte(enums.MotorolaLtd)
+	bits = pdu.EncodeCSBK(&csbk)
+	if _, err := layer2.NewBurstFromBytes(layer2.BuildLCDataBurst(infoBitsToBytes(bits), elements.DataTypeCSBK, 1)); err != nil {
+		t.Errorf("vendor CSBK: unexpected error %v", err)
+	}
+}
+
+func TestBurst_Decode_FLCUnknownOpcode(t *testing.T) {
+	t.Parallel()
+	flc := pdu.FullLinkControl{FLCO: enums.FLCO(0b111110)}
+	bits := pdu.EncodeFullLinkControl(&flc)
+
+	burst, err := layer2.NewBurstFromBytes(layer2.BuildLCDataBurst(infoBitsToBytes(bits), elements.DataTypeVoiceLCHeader, 1))
+	testutil.AssertPDUError(t, err, elements.ErrUnknownOpcode, elements.LayerPDU, "FullLinkControl.FLCO")
+	if burst.Data == nil {
+		t.Error("Data = nil, want the decoded FLC for inspection")
+	}
+}
+
+func TestBurst_Decode_PIHeaderNotImplemented(t *testing.T) {
+	t.Parallel()
+	_, err := layer2.NewBurstFromBytes(layer2.BuildLCDataBurst([12]byte{}, elements.DataTypePIHeader, 1))
+	testutil.AssertPDUError(t, err, elements.ErrNotImplemented, elements.LayerPDU, "PIHeader")
+}
@@ -0,0 +1,92 @@
+package layer2_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	trellis34 "github.com/USA-RedDragon/dmrgo/v2/fec/trellis"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// buildDataBurst assembles a BS-sourced data burst around 196 coded payload bits.
+func buildDataBurst(coded [196]bit.Bit, dataType elements.DataType) [33]byte {
+	var bits [264]bit.Bit
+	copy(bits[:98], coded[:98])
+	copy(bits[166:], coded[98:])
+	slot := pdu.EncodeSlotType(&pdu.SlotType{ColorCode: 1, DataType: dataType})
+	copy(bits[98:108], slot[:10])
+	copy(bits[156:166], slot[10:])
+	copy(bits[108:156], bit.UnpackBits([]byte{0xDF, 0xF5, 0x7D, 0x75, 0xDF, 0x5D}))
+	return bit.PackBits264(bits)
+}
+
+func TestBurst_Rate34_FullWidth(t *testing.T) {
+	t.Parallel()
+	var want [18]byte
+	for i := range want {
+		want[i] = byte(0x11 * (i + 1))
+	}
+	info := pdu.EncodeRate34Data(&pdu.Rate34Data{Data: want})
+	var trellis trellis34.Trellis34
+	raw := buildDataBurst(trellis.Encode(info), elements.DataTypeRate34)
+
+	burst, err := layer2.NewBurstFromBytes(raw)
+	if err != nil {
+		t.Fatalf("NewBurstFromBytes: %v", err)
+	}
+	if burst.SyncPattern != enums.BsSourcedData {
+		t.Fatalf("SyncPattern = %v, want BsSourcedData", burst.SyncPattern)
+	}
+	rt, ok := burst.Data.(*pdu.Rate34Data)
+	if !ok {
+		t.Fatalf("Data = %T, want *pdu.Rate34Data", burst.Data)
+	}
+	if rt.Data != want {
+		t.Errorf("Data = %x, want %x", rt.Data, want)
+	}
+
+	encoded, err := burst.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if encoded != raw {
+		t.Errorf("Encode mismatch:\n got %x\nwant %x", encoded, raw)
+	}
+}
+
+func TestBurst_Rate1_FullWidth(t *testing.T) {
+	t.Parallel()
+	var want [24]byte
+	for i := range want {
+		want[i] = byte(0xF0 - i)
+	}
+	info := pdu.EncodeRate1Data(&pdu.Rate1Data{Data: want})
+	// Table B.10B: 96 info bits, 4 reserved bits, 96 info bits
+	var coded [196]bit.Bit
+	copy(coded[:96], info[:96])
+	copy(coded[100:], info[96:])
+	raw := buildDataBurst(coded, elements.DataTypeRate1)
+
+	burst, err := layer2.NewBurstFromBytes(raw)
+	if err != nil {
+		t.Fatalf("NewBurstFromBytes: %v", err)
+	}
+	rt, ok := burst.Data.(*pdu.Rate1Data)
+	if !ok {
+		t.Fatalf("Data = %T, want *pdu.Rate1Data", burst.Data)
+	}
+	if rt.Data != want {
+		t.Errorf("Data = %x, want %x", rt.Data, want)
+	}
+
+	encoded, err := burst.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if encoded != raw {
+		t.Errorf("Encode mismatch:\n got %x\nwant %x", encoded, raw)
+	}
+}
@@ -0,0 +1,90 @@
+package layer2_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+)
+
+// corruptSync flips the given bit offsets within the 48-bit SYNC field.
+func corruptSync(raw [33]byte, offsets ...int) [33]byte {
+	for _, off := range offsets {
+		idx := layer2.SyncStart + off
+		raw[idx/8] ^= 1 << (7 - idx%8)
+	}
+	return raw
+}
+
+func TestBurst_SyncBitErrors_ExactMatchByDefault(t *testing.T) {
+	t.Parallel()
+	raw := corruptSync(layer2.BuildLCDataBurst([12]byte{}, elements.DataTypeVoiceLCHeader, 1), 3)
+
+	burst, _ := layer2.NewBurstFromBytes(raw)
+	if burst.SyncPattern != enums.EmbeddedSignallingPattern {
+		t.Errorf("SyncPattern = %v, want EmbeddedSignallingPattern without a threshold", burst.SyncPattern)
+	}
+	if burst.SyncMatch.Candidate != enums.BsSourcedData || burst.SyncMatch.Distance != 1 {
+		t.Errorf("SyncMatch = %+v, want Candidate=BsSourcedData Distance=1", burst.SyncMatch)
+	}
+}
+
+func TestBurst_SyncBitErrors_WithinThreshold(t *testing.T) {
+	t.Parallel()
+	raw := corruptSync(layer2.BuildLCDataBurst([12]byte{}, elements.DataTypeCSBK, 1), 0, 17, 40)
+
+	var burst layer2.Burst
+	burst.SetSyncMaxBitErrors(enums.SyncMaxUnambiguousBitErrors)
+	// The all-zero CSBK fails its CRC; only the classification matters here.
+	_ = burst.DecodeFromBytes(raw)
+	if burst.SyncPattern != enums.BsSourcedData {
+		t.Fatalf("SyncPattern = %v, want BsSourcedData", burst.SyncPattern)
+	}
+	if !burst.IsData || !burst.HasSlotType || burst.SlotType.DataType != elements.DataTypeCSBK {
+		t.Errorf("burst not classified as CSBK data: %s", burst.ToString())
+	}
+	if burst.FEC.Sync.BitsChecked != enums.SyncPatternBits {
+		t.Errorf("FEC.Sync.BitsChecked = %d, want %d", burst.FEC.Sync.BitsChecked, enums.SyncPatternBits)
+	}
+	if burst.FEC.Sync.ErrorsCorrected != 3 {
+		t.Errorf("FEC.Sync.ErrorsCorrected = %d, want 3", burst.FEC.Sync.ErrorsCorrected)
+	}
+	if burst.FEC.Aggregate().ErrorsCorrected < 3 {
+		t.Error("Aggregate should include SYNC errors")
+	}
+
+	// Threshold survives reuse of the Burst
+	_ = burst.DecodeFromBytes(raw)
+	if burst.SyncPattern != enums.BsSourcedData {
+		t.Errorf("threshold lost on reuse: SyncPattern = %v", burst.SyncPattern)
+	}
+	if burst.SyncMaxBitErrors != enums.SyncMaxUnambiguousBitErrors {
+		t.Errorf("SyncMaxBitErrors = %d after reuse", burst.SyncMaxBitErrors)
+	}
+
+	// Re-encoding restores the clean SYNC pattern
+	encoded, err := burst.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	clean, _ := layer2.NewBurstFromBytes(encoded)
+	if clean.SyncPattern != enums.BsSourcedData || clean.FEC.Sync.ErrorsCorrected != 0 {
+		t.Errorf("re-encoded burst SYNC not clean: %+v", clean.SyncMatch)
+	}
+}
+
+func TestBurst_SyncBitErrors_BeyondThreshold(t *testing.T) {
+	t.Parallel()
+	raw := corruptSync(layer2.BuildLCDataBurst([12]byte{}, elements.DataTypeVoiceLCHeader, 1), 0, 1, 2)
+
+	var burst layer2.Burst
+	burst.SetSyncMaxBitErrors(2)
+	_ = burst.DecodeFromBytes(raw)
+	if burst.SyncPattern != enums.EmbeddedSignallingPattern {
+		t.Errorf("SyncPattern = %v, want EmbeddedSignallingPattern", burst.SyncPattern)
+	}
+	if burst.FEC.Sync.BitsChecked != 0 {
+		t.Errorf("FEC.Sync.BitsChecked = %d, want 0 for unmatched SYNC", burst.FEC.Sync.BitsChecked)
+	}
+}
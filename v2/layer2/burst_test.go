@@ -49,7 +49,10 @@ func TestBurst_Encode(t *testing.T) {
 				}
 				t.Logf("burst: %v", burst.ToString())
 
-				encoded := burst.Encode()
+				encoded, err := burst.Encode()
+				if err != nil {
+					t.Fatalf("Encode failed for burst %d: %v", i, err)
+				}
 
 				// Verify stability: Encode(Decode(encoded)) == encoded
 				// This handles cases where the input file has invalid parity/FEC bits (captured data)
@@ -58,7 +61,10 @@ func TestBurst_Encode(t *testing.T) {
 				if err != nil {
 					t.Fatalf("NewBurstFromBytes failed for burst %d: %v", i, err)
 				}
-				encoded2 := burst2.Encode()
+				encoded2, err := burst2.Encode()
+				if err != nil {
+					t.Fatalf("Encode failed for burst %d: %v", i, err)
+				}
 
 				if !bytes.Equal(encoded[:], encoded2[:]) {
 					t.Errorf("Burst %d stability mismatch:\nfirst  %x\nsecond %x\nSync: %v\nVoice: %v\nHasEmbSig: %v\nCorrected Errors: %d\nUncorrectable: %v",
@@ -117,7 +123,9 @@ func benchmarkEncode(b *testing.B, file string) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = decodedBursts[i%len(decodedBursts)].Encode()
+		if _, err := decodedBursts[i%len(decodedBursts)].Encode(); err != nil {
+			b.Fatalf("Encode failed: %v", err)
+		}
 	}
 }
 
@@ -0,0 +1,112 @@
+package layer2
+
+import (
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/fec/bptc"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// ETSI TS 102 361-1 §6.3 and §7.1.4 — CACH generation on the BS outbound
+// channel
+//
+// Every outbound frame starts with a CACH. The TACT TC bit alternates between
+// the two TDMA channels, and each Short LC is sent over four consecutive
+// CACHs with LCSS first, continuation, continuation and last. When no Short
+// LC is queued the generator sends the Short LC Null message.
+
+// CACHGenerator produces the CACH for each successive BS outbound frame.
+// The zero value is ready to use; its first CACH has TC for timeslot 1 and
+// both timeslots idle.
+type CACHGenerator struct {
+	queue     []pdu.ShortLC
+	fragments [ShortLCFragments][CACHPayloadBits]bit.Bit
+	// fragment is the index of the next fragment of the current Short LC
+	// to send; ShortLCFragments when none is in progress.
+	fragment int
+	started  bool
+	slot2    bool
+	busy     [2]bool
+}
+
+// Enqueue adds Short LC messages to be sent, in order, after the current
+// one.
+func (g *CACHGenerator) Enqueue(slc ...pdu.ShortLC) {
+	g.queue = append(g.queue, slc...)
+}
+
+// Pending returns the number of queued Short LC messages that have not yet
+// started to be sent.
+func (g *CACHGenerator) Pending() int {
+	return len(g.queue)
+}
+
+// SetBusy sets the activity state of timeslot (1 or 2), which is sent in
+// the TACT AT bit of the CACHs whose TC identifies that timeslot. Other
+// timeslot values are ignored.
+func (g *CACHGenerator) SetBusy(timeslot int, busy bool) {
+	if timeslot == 1 || timeslot == 2 {
+		g.busy[timeslot-1] = busy
+	}
+}
+
+// Timeslot returns the timeslot (1 or 2) that the next CACH will identify
+// in its TC bit.
+func (g *CACHGenerator) Timeslot() int {
+	if g.slot2 {
+		return 2
+	}
+	return 1
+}
+
+// NextCACH returns the CACH for the next outbound frame and advances the
+// generator. A new Short LC is taken from the queue, or the Null message
+// sent, each time the previous one has been fully sent.
+func (g *CACHGenerator) NextCACH() CACH {
+	if !g.started || g.fragment >= ShortLCFragments {
+		g.load()
+	}
+
+	var lcss enums.LCSS
+	switch g.fragment {
+	case 0:
+		lcss = enums.FirstFragmentLC
+	case ShortLCFragments - 1:
+		lcss = enums.LastFragmentLCorCSBK
+	default:
+		lcss = enums.ContinuationFragmentLCorCSBK
+	}
+
+	c := CACH{
+		TACT: pdu.TACT{
+			AccessType:  g.busy[g.Timeslot()-1],
+			TDMAChannel: g.slot2,
+			LCSS:        lcss,
+		},
+		Payload: g.fragments[g.fragment],
+	}
+	g.fragment++
+	g.slot2 = !g.slot2
+	return c
+}
+
+// Next returns the interleaved 24-bit CACH field for the next outbound
+// frame in the order it is sent on air, first bit first, as it starts a
+// frame from Frame.Encode.
+func (g *CACHGenerator) Next() [CACHBits]bit.Bit {
+	c := g.NextCACH()
+	return cachAirBits(&c)
+}
+
+// load takes the next Short LC from the queue, or the Null message, and
+// splits it into its BPTC fragments.
+func (g *CACHGenerator) load() {
+	slc := pdu.ShortLC{SLCO: enums.SLCONullMessage, NullMessage: &pdu.ShortLCNullMessage{}}
+	if len(g.queue) > 0 {
+		slc = g.queue[0]
+		g.queue = g.queue[1:]
+	}
+	g.fragments = bptc.EncodeCACHBPTC(pdu.EncodeShortLC(&slc))
+	g.fragment = 0
+	g.started = true
+}
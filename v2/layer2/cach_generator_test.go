@@ -0,0 +1,115 @@
+package layer2_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// frameCACH decodes a CACH field sent first on air, as the start of a frame.
+func frameCACH(bits [layer2.CACHBits]bit.Bit) layer2.CACH {
+	var data [layer2.FrameBytes]byte
+	copy(data[:], bit.PackBits(bits[:]))
+	// The empty burst after the CACH is of no interest.
+	f, _ := layer2.NewFrameFromBytes(data)
+	return f.CACH
+}
+
+func TestCACHGenerator_ShortLCSequence(t *testing.T) {
+	t.Parallel()
+	var g layer2.CACHGenerator
+	g.Enqueue(*testActivityUpdate(3), *testActivityUpdate(4))
+	if g.Pending() != 2 {
+		t.Fatalf("Pending = %d, want 2", g.Pending())
+	}
+
+	wantLCSS := []enums.LCSS{
+		enums.FirstFragmentLC,
+		enums.ContinuationFragmentLCorCSBK,
+		enums.ContinuationFragmentLCorCSBK,
+		enums.LastFragmentLCorCSBK,
+	}
+	var a layer2.ShortLCAssembler
+	var decoded []pdu.ShortLC
+	for i := range 3 * layer2.ShortLCFragments {
+		c := frameCACH(g.Next())
+		if c.FEC.Uncorrectable || c.FEC.ErrorsCorrected != 0 {
+			t.Fatalf("CACH %d: TACT FEC = %+v", i, c.FEC)
+		}
+		if c.TACT.LCSS != wantLCSS[i%layer2.ShortLCFragments] {
+			t.Errorf("CACH %d: LCSS = %s", i, enums.LCSSToName(c.TACT.LCSS))
+		}
+		if a.AddCACH(&c) {
+			slc, result := a.Complete()
+			if result.Uncorrectable {
+				t.Fatalf("CACH %d: Short LC uncorrectable", i)
+			}
+			decoded = append(decoded, slc)
+		}
+	}
+
+	if len(decoded) != 3 || a.Discarded() != 0 {
+		t.Fatalf("decoded %d Short LCs with %d discarded, want 3 and 0", len(decoded), a.Discarded())
+	}
+	for i, ts1 := range []enums.ActivityID{3, 4} {
+		if decoded[i].ActivityUpdate == nil || decoded[i].ActivityUpdate.TS1ActivityID != ts1 {
+			t.Errorf("Short LC %d = %s", i, decoded[i].ToString())
+		}
+	}
+	if decoded[2].SLCO != enums.SLCONullMessage {
+		t.Errorf("idle Short LC = %s, want the Null message", decoded[2].ToString())
+	}
+	if g.Pending() != 0 {
+		t.Errorf("Pending = %d, want 0", g.Pending())
+	}
+}
+
+func TestCACHGenerator_TimeslotAndActivity(t *testing.T) {
+	t.Parallel()
+	var g layer2.CACHGenerator
+	g.SetBusy(2, true)
+	g.SetBusy(3, true)
+
+	for i := range 6 {
+		wantSlot := 1 + i%2
+		if g.Timeslot() != wantSlot {
+			t.Errorf("CACH %d: Timeslot = %d, want %d", i, g.Timeslot(), wantSlot)
+		}
+		if i == 4 {
+			g.SetBusy(1, true)
+			g.SetBusy(2, false)
+		}
+		c := g.NextCACH()
+		wantBusy := (wantSlot == 2) != (i >= 4)
+		if c.TACT.TDMAChannel != (wantSlot == 2) || c.TACT.AccessType != wantBusy {
+			t.Errorf("CACH %d: TACT = %s, want slot %d busy=%t", i, c.TACT.ToString(), wantSlot, wantBusy)
+		}
+	}
+}
+
+func TestCACHGenerator_TransmitOrder(t *testing.T) {
+	t.Parallel()
+	var bitsGen, cachGen layer2.CACHGenerator
+	for _, g := range []*layer2.CACHGenerator{&bitsGen, &cachGen} {
+		g.SetBusy(1, true)
+		g.Enqueue(*testActivityUpdate(3))
+	}
+	for i := range layer2.ShortLCFragments {
+		bits := bitsGen.Next()
+		f := layer2.Frame{CACH: cachGen.NextCACH(), Burst: layer2.Burst{SyncPattern: enums.BsSourcedVoice}}
+		frame, err := f.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := bit.PackBits(bits[:]); [3]byte(got) != [3]byte(frame[:3]) {
+			t.Errorf("CACH %d: Next = %x, want %x as sent by Frame.Encode", i, got, frame[:3])
+		}
+		// The AT bit of the busy timeslot 1 is sent first.
+		if i%2 == 0 && bits[0] != 1 {
+			t.Errorf("CACH %d: first bit = %d, want AT = 1", i, bits[0])
+		}
+	}
+}
@@ -0,0 +1,620 @@
+package layer2
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/constants"
+	"github.com/USA-RedDragon/dmrgo/v2/crc"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// ETSI TS 102 361-1 §8.3 / ETSI TS 102 361-3 §5.4 — Confirmed data delivery
+//
+// Each block of a confirmed packet starts with a 7-bit Data Block Serial
+// Number (DBSN) and a CRC-9 over the block's data and DBSN (B.3.10). The
+// receiver answers each transmission with a Response Packet Header
+// (C_RHEAD):
+//   - ACK when every block and the message CRC are good
+//   - Selective ACK, followed by a Rate 1/2 block holding a bitmap of the
+//     blocks received, when blocks are missing or failed their CRC-9
+//   - NACK when the packet is rejected
+//
+// The sender retransmits the header, with the same N(S), and only the blocks
+// that were not acknowledged until the packet is acknowledged or the retry
+// limit is reached. N(S) counts packets modulo 8; the S flag tells the
+// receiver to resynchronise on the N(S) of the packet.
+//
+// In the selective ACK bitmap bit 7 of octet 0 is DBSN 0, and a set bit marks
+// a block received correctly. The bitmap is followed by its own message CRC.
+// Blocks beyond the SelectiveACKBlocks it covers are always retransmitted.
+
+const (
+	// SelectiveACKBlocks is the number of blocks covered by the selective
+	// ACK bitmap.
+	SelectiveACKBlocks = 64
+
+	// confirmedBlockHeaderOctets is the size of the DBSN and CRC-9 at the
+	// start of a confirmed data block.
+	confirmedBlockHeaderOctets = 2
+	// maxPacketBlocks is the largest BlocksToFollow a data header carries.
+	maxPacketBlocks = 127
+	// nsModulus is the modulus of the send sequence number N(S).
+	nsModulus = 8
+)
+
+// DataResponse is a confirmed data response (C_RHEAD), sent by the receiver
+// of a packet to its sender.
+type DataResponse struct {
+	// SAP is the service access point of the packet being answered.
+	SAP pdu.ServiceAccessPointID
+	// Source is the LLID of the station responding, the packet's
+	// destination; Destination is the packet's source.
+	Source      int
+	Destination int
+	// Type is the response class and type.
+	Type enums.DataResponseType
+	// Status is the N(S) of the packet being answered.
+	Status uint8
+	// Retransmit lists the DBSNs the sender must retransmit, for a
+	// selective ACK.
+	Retransmit []int
+}
+
+// ToString returns a string representation of the response.
+func (r *DataResponse) ToString() string {
+	return fmt.Sprintf("DataResponse{ SAP: %d, Source: %d, Destination: %d, Type: %s, Status: %d, Retransmit: %v }",
+		r.SAP, r.Source, r.Destination, enums.DataResponseTypeToName(r.Type), r.Status, r.Retransmit)
+}
+
+// Bursts returns the bursts that carry the response: the C_RHEAD and, for a
+// selective ACK, the bitmap block.
+func (r *DataResponse) Bursts(syncPattern enums.SyncPattern, colorCode int) []Burst {
+	h := &pdu.ResponsePacketHeader{
+		SAP:             uint8(r.SAP),
+		LLIDDestination: r.Destination,
+		LLIDSource:      r.Source,
+		ResponseClass:   r.Type.Class(),
+		ResponseType:    r.Type.Type(),
+		ResponseStatus:  r.Status,
+	}
+	if r.Type != enums.DataResponseSelectiveACK {
+		return []Burst{newDataBurst(syncPattern, colorCode, responseHeader(h))}
+	}
+
+	h.BlocksToFollow = 1
+	bitmap := make([]byte, SelectiveACKBlocks/8, SelectiveACKBlocks/8+PacketCRCOctets)
+	for i := range bitmap {
+		bitmap[i] = 0xFF
+	}
+	for _, dbsn := range r.Retransmit {
+		if dbsn >= 0 && dbsn < SelectiveACKBlocks {
+			bitmap[dbsn/8] &^= 0x80 >> (dbsn % 8)
+		}
+	}
+	return []Burst{
+		newDataBurst(syncPattern, colorCode, responseHeader(h)),
+		newDataBurst(syncPattern, colorCode, dataBlockData(elements.DataTypeRate12, appendPacketCRC(bitmap))),
+	}
+}
+
+// responseHeader wraps a C_RHEAD in its data header PDU.
+func responseHeader(h *pdu.ResponsePacketHeader) *pdu.DataHeader {
+	return &pdu.DataHeader{DataType: elements.DataTypeDataHeader, Format: pdu.FormatResponsePacket, ResponsePacketHeader: h}
+}
+
+// NACKError reports a packet rejected by its receiver with a NACK response.
+// It wraps elements.ErrNACK.
+type NACKError struct {
+	Type enums.DataResponseType
+}
+
+func (e *NACKError) Error() string {
+	return elements.LayerPacket + ": " + elements.ErrNACK.Error() + ": " + enums.DataResponseTypeToName(e.Type)
+}
+
+// Unwrap returns elements.ErrNACK.
+func (e *NACKError) Unwrap() error {
+	return elements.ErrNACK
+}
+
+// crc9Mask returns the CRC-9 mask for confirmed data blocks of dt (B.3.12).
+func crc9Mask(dt elements.DataType) uint16 {
+	switch dt {
+	case elements.DataTypeRate34:
+		return crc.CRC9MaskRate34Data
+	case elements.DataTypeRate1:
+		return crc.CRC9MaskRate1Data
+	default:
+		return crc.CRC9MaskRate12Data
+	}
+}
+
+// encodeConfirmedBlock returns the octets of a confirmed data block of dt:
+// the DBSN and CRC-9 followed by data.
+func encodeConfirmedBlock(dt elements.DataType, dbsn uint8, data []byte) []byte {
+	sum := crc.CalculateDataBlockCRC9(dbsn, data, crc9Mask(dt))
+	out := make([]byte, 0, confirmedBlockHeaderOctets+len(data))
+	out = append(out, dbsn<<1|byte(sum>>8), byte(sum))
+	return append(out, data...)
+}
+
+// decodeConfirmedBlock returns the DBSN and data of a confirmed data block,
+// and whether its CRC-9 is valid. ok is false for a burst that is not a data
+// block.
+func decodeConfirmedBlock(b *Burst) (dbsn uint8, data []byte, valid, ok bool) {
+	switch d := b.Data.(type) {
+	case *pdu.Rate12Data:
+		c := d.Confirmed()
+		return c.SerialNumber, c.Data[:], c.CRCValid(), true
+	case *pdu.Rate34Data:
+		c := d.Confirmed()
+		return c.SerialNumber, c.Data[:], c.CRCValid(), true
+	case *pdu.Rate1Data:
+		c := d.Confirmed()
+		return c.SerialNumber, c.Data[:], c.CRCValid(), true
+	}
+	_, ok = dataBlockOctets(b)
+	return 0, nil, false, ok
+}
+
+// ConfirmedDataReceiver runs the receiving side of confirmed packet data
+// delivery on a single timeslot. It collects the blocks of each C_HEAD
+// transmission by DBSN, answers every transmission with a DataResponse, and
+// returns each message once all of its packets have been received. Use one
+// receiver per timeslot; the zero value is ready to use.
+//
+// A transmission ends after BlocksToFollow data blocks, or earlier when any
+// other burst arrives. A packet that repeats the N(S) of the last packet
+// delivered without the S flag set is a duplicate: it is acknowledged again
+// but not delivered twice.
+type ConfirmedDataReceiver struct {
+	// Timeout bounds the time from the first header of a message to its
+	// last data block. Zero uses constants.TDataTxLmt.
+	Timeout time.Duration
+
+	header    *pdu.ConfirmedDataHeader
+	blocks    [][]byte
+	remaining int
+	duplicate bool
+	lastNS    uint8
+	delivered bool
+	message   *PacketData
+	nextFSN   uint8
+	started   time.Time
+}
+
+// Reset discards any partially received packet and message and forgets the
+// last N(S) delivered.
+func (r *ConfirmedDataReceiver) Reset() {
+	r.drop()
+	r.delivered = false
+}
+
+// drop discards the packet and message in progress.
+func (r *ConfirmedDataReceiver) drop() {
+	r.header = nil
+	r.blocks = nil
+	r.remaining = 0
+	r.duplicate = false
+	r.message = nil
+}
+
+// AddBurst feeds the next burst received on the slot at time now. It
+// returns the response to send when the burst ends a transmission, and the
+// message when the burst completes one.
+//
+// Errors report a packet or message that was dropped, as for
+// UnconfirmedDataAssembler.AddBurst. A packet that fails its message CRC is
+// also answered with a NACK.
+func (r *ConfirmedDataReceiver) AddBurst(b *Burst, now time.Time) (*DataResponse, *PacketData, error) {
+	expired := r.Expire(now)
+	resp, msg, err := r.add(b, now)
+	if expired != nil {
+		return resp, msg, expired
+	}
+	return resp, msg, err
+}
+
+// Expire drops the message in progress if it has been pending for longer
+// than the timeout at time now, and reports it with a *MissingBlocksError.
+func (r *ConfirmedDataReceiver) Expire(now time.Time) error {
+	if r.header == nil && r.message == nil {
+		return nil
+	}
+	timeout := r.Timeout
+	if timeout == 0 {
+		timeout = constants.TDataTxLmt
+	}
+	if now.Sub(r.started) <= timeout {
+		return nil
+	}
+	err := &MissingBlocksError{Expected: len(r.blocks), Received: r.receivedBlocks(), TimedOut: true}
+	r.drop()
+	return err
+}
+
+func (r *ConfirmedDataReceiver) add(b *Burst, now time.Time) (*DataResponse, *PacketData, error) {
+	if r.remaining > 0 {
+		dbsn, data, valid, ok := decodeConfirmedBlock(b)
+		if ok {
+			if valid && int(dbsn) < len(r.blocks) {
+				r.blocks[dbsn] = append([]byte(nil), data...)
+			}
+			r.remaining--
+			if r.remaining > 0 {
+				return nil, nil, nil
+			}
+			return r.endTransmission()
+		}
+		// The transmission was cut short; answer what was received.
+		resp, msg, err := r.endTransmission()
+		if header, isHeader := b.Data.(*pdu.DataHeader); isHeader && header.ConfirmedDataHeader != nil {
+			startErr := r.startTransmission(header.ConfirmedDataHeader, now)
+			if err == nil {
+				err = startErr
+			}
+		}
+		return resp, msg, err
+	}
+
+	if header, ok := b.Data.(*pdu.DataHeader); ok && header.ConfirmedDataHeader != nil {
+		return nil, nil, r.startTransmission(header.ConfirmedDataHeader, now)
+	}
+	return nil, nil, nil
+}
+
+// startTransmission handles a C_HEAD: a retransmission of the packet in
+// progress, a duplicate of the last packet delivered, or a new packet.
+func (r *ConfirmedDataReceiver) startTransmission(h *pdu.ConfirmedDataHeader, now time.Time) error {
+	if h.BlocksToFollow == 0 {
+		return &elements.PDUError{Layer: elements.LayerPacket, Field: "BlocksToFollow", Err: elements.ErrInvalidLength}
+	}
+
+	if r.header != nil && !r.duplicate && !h.ReSynchronizeFlag && h.SendSequenceNumber == r.header.SendSequenceNumber {
+		r.remaining = int(h.BlocksToFollow)
+		return nil
+	}
+
+	var err error
+	if r.header != nil && !r.duplicate {
+		err = &MissingBlocksError{Expected: len(r.blocks), Received: r.receivedBlocks()}
+		r.drop()
+	}
+
+	r.header = h
+	r.remaining = int(h.BlocksToFollow)
+	r.duplicate = !h.ReSynchronizeFlag && r.delivered && h.SendSequenceNumber == r.lastNS
+	r.blocks = nil
+	if !r.duplicate {
+		r.blocks = make([][]byte, h.BlocksToFollow)
+	}
+	if r.message == nil {
+		r.started = now
+	}
+	return err
+}
+
+// endTransmission answers the transmission that just ended and, when the
+// packet is complete, checks its message CRC and adds it to the message.
+func (r *ConfirmedDataReceiver) endTransmission() (*DataResponse, *PacketData, error) {
+	h := r.header
+	r.remaining = 0
+	if r.duplicate {
+		r.header = nil
+		r.duplicate = false
+		return r.respond(h, enums.DataResponseACK), nil, nil
+	}
+
+	var retransmit []int
+	var data []byte
+	for i, block := range r.blocks {
+		if block == nil {
+			retransmit = append(retransmit, i)
+		}
+		data = append(data, block...)
+	}
+	if len(retransmit) > 0 {
+		resp := r.respond(h, enums.DataResponseSelectiveACK)
+		resp.Retransmit = retransmit
+		return resp, nil, nil
+	}
+
+	r.header = nil
+	r.blocks = nil
+	payload, err := checkPacketCRC(data, int(h.PadOctetCount))
+	if err != nil {
+		return r.respond(h, enums.DataResponseNACKPacketCRC), nil, err
+	}
+
+	fsn := h.FragmentSequenceNumber
+	if h.FullMessageFlag {
+		if r.message != nil {
+			err = &elements.PDUError{Layer: elements.LayerPacket, Field: "FullMessageFlag", Err: elements.ErrFragmentSequence}
+		}
+		r.message = &PacketData{
+			SAP:               pdu.ServiceAccessPointID(h.SAP),
+			Source:            h.LLIDSource,
+			Destination:       h.LLIDDestination,
+			Group:             h.Group,
+			ResponseRequested: h.ResponseRequested,
+		}
+	} else if r.message == nil || fsn&fsnSequenceMask != r.nextFSN {
+		r.message = nil
+		return r.respond(h, enums.DataResponseNACKFSNOutOfSequence), nil,
+			&elements.PDUError{Layer: elements.LayerPacket, Field: "FragmentSequenceNumber", Err: elements.ErrFragmentSequence}
+	}
+
+	r.lastNS = h.SendSequenceNumber
+	r.delivered = true
+	msg := r.message
+	msg.FragmentSequenceNumbers = append(msg.FragmentSequenceNumbers, fsn)
+	msg.Payload = append(msg.Payload, payload...)
+	resp := r.respond(h, enums.DataResponseACK)
+	if fsn&fsnLastFragment != 0 || (h.FullMessageFlag && fsn == 0) {
+		r.message = nil
+		return resp, msg, err
+	}
+	r.nextFSN = (fsn + 1) & fsnSequenceMask
+	return resp, nil, err
+}
+
+// respond returns a response of type t to the packet with header h.
+func (r *ConfirmedDataReceiver) respond(h *pdu.ConfirmedDataHeader, t enums.DataResponseType) *DataResponse {
+	return &DataResponse{
+		SAP:         pdu.ServiceAccessPointID(h.SAP),
+		Source:      h.LLIDDestination,
+		Destination: h.LLIDSource,
+		Type:        t,
+		Status:      h.SendSequenceNumber,
+	}
+}
+
+// receivedBlocks returns the number of blocks of the packet in progress
+// received with a valid CRC-9.
+func (r *ConfirmedDataReceiver) receivedBlocks() int {
+	n := 0
+	for _, block := range r.blocks {
+		if block != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// burstPeriod is the time between the bursts of one timeslot.
+const burstPeriod = 60 * time.Millisecond
+
+// ConfirmedDataSender runs the sending side of confirmed packet data
+// delivery on a single timeslot: it encodes each packet, then retransmits
+// the blocks its receiver did not acknowledge until the packet is
+// acknowledged, rejected or the retry limit is reached. The zero value sends
+// Rate 3/4 blocks with the Annex A timers.
+type ConfirmedDataSender struct {
+	// SyncPattern and ColorCode are set on every burst sent.
+	SyncPattern enums.SyncPattern
+	ColorCode   int
+	// DataType is the rate of the data blocks: elements.DataTypeRate12,
+	// DataTypeRate34 or DataTypeRate1. Any other value uses Rate 3/4.
+	DataType elements.DataType
+	// RetryLimit is the number of times a packet is transmitted before it is
+	// given up. Zero uses constants.NRtryLmt.
+	RetryLimit int
+	// ResponseWait bounds the time from the end of a transmission to its
+	// response. Zero uses constants.TRspnsWait.
+	ResponseWait time.Duration
+
+	ns           uint8
+	synchronized bool
+	header       *pdu.ConfirmedDataHeader
+	blocks       []Burst
+	unacked      []int
+	attempts     int
+	deadline     time.Time
+	selective    *pdu.ResponsePacketHeader
+}
+
+// Busy reports whether a packet is waiting to be acknowledged.
+func (s *ConfirmedDataSender) Busy() bool {
+	return s.header != nil
+}
+
+// Reset abandons the packet in progress. The next packet is sent with the
+// S flag set so its receiver resynchronises on its N(S).
+func (s *ConfirmedDataSender) Reset() {
+	s.header = nil
+	s.blocks = nil
+	s.unacked = nil
+	s.selective = nil
+	s.synchronized = false
+}
+
+// Send returns the bursts of a confirmed packet carrying msg.Payload from
+// msg.Source to msg.Destination at time now, abandoning any packet in
+// progress. The packet is unfragmented and always requests a response.
+//
+// A payload too large for one packet returns an *elements.PDUError wrapping
+// elements.ErrInvalidLength.
+func (s *ConfirmedDataSender) Send(msg *PacketData, now time.Time) ([]Burst, error) {
+	packet := DataPacket{
+		SyncPattern:        s.SyncPattern,
+		ColorCode:          s.ColorCode,
+		SAP:                msg.SAP,
+		Source:             msg.Source,
+		Destination:        msg.Destination,
+		Group:              msg.Group,
+		FullMessage:        true,
+		Confirmed:          true,
+		SendSequenceNumber: s.ns,
+		// A packet that replaces one in progress reuses its N(S), so the
+		// receiver must not take it for a retransmission.
+		ReSynchronize: !s.synchronized || s.header != nil,
+		DataType:      s.dataType(),
+		Payload:       msg.Payload,
+	}
+	bursts, err := packet.Bursts()
+	if err != nil {
+		return nil, err
+	}
+	header, ok := bursts[0].Data.(*pdu.DataHeader)
+	if !ok {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "DataHeader", Err: elements.ErrDataTypeMismatch}
+	}
+
+	s.Reset()
+	s.header = header.ConfirmedDataHeader
+	s.synchronized = true
+	s.blocks = bursts[1:]
+	s.attempts = 0
+	return s.transmit(s.allBlocks(), now)
+}
+
+// AddBurst feeds a burst received on the slot at time now. When the burst
+// completes the response to the packet in progress it returns the bursts to
+// retransmit, or done once the packet has been acknowledged.
+//
+// A NACK returns a *NACKError, and a packet that reaches the retry limit an
+// *elements.PDUError wrapping elements.ErrRetryLimit; either ends the
+// packet. NACKs for a bad message CRC or an N(S) out of sequence are
+// answered by retransmitting the whole packet instead.
+func (s *ConfirmedDataSender) AddBurst(b *Burst, now time.Time) ([]Burst, bool, error) {
+	if s.header == nil {
+		return nil, false, nil
+	}
+
+	if h := s.selective; h != nil {
+		s.selective = nil
+		octets, ok := dataBlockOctets(b)
+		if !ok {
+			return nil, false, nil
+		}
+		// An unreadable bitmap is left to the response timer.
+		if bitmap, err := checkPacketCRC(octets, 0); err == nil {
+			bursts, err := s.transmit(s.notReceived(bitmap), now)
+			return bursts, false, err
+		}
+		return nil, false, nil
+	}
+
+	header, ok := b.Data.(*pdu.DataHeader)
+	if !ok || header.ResponsePacketHeader == nil {
+		return nil, false, nil
+	}
+	h := header.ResponsePacketHeader
+	if h.LLIDDestination != s.header.LLIDSource || h.LLIDSource != s.header.LLIDDestination || h.ResponseStatus != s.header.SendSequenceNumber {
+		return nil, false, nil
+	}
+
+	t := enums.DataResponseTypeFromFields(h.ResponseClass, h.ResponseType)
+	switch t {
+	case enums.DataResponseACK:
+		s.ns = (s.ns + 1) % nsModulus
+		s.header = nil
+		s.blocks = nil
+		s.unacked = nil
+		return nil, true, nil
+	case enums.DataResponseSelectiveACK:
+		if h.BlocksToFollow > 0 {
+			s.selective = h
+		}
+		return nil, false, nil
+	case enums.DataResponseNACKPacketCRC:
+		bursts, err := s.transmit(s.allBlocks(), now)
+		return bursts, false, err
+	case enums.DataResponseNACKNSOutOfSequence:
+		s.header.ReSynchronizeFlag = true
+		bursts, err := s.transmit(s.allBlocks(), now)
+		return bursts, false, err
+	case enums.DataResponseNACKIllegalFormat,
+		enums.DataResponseNACKMemoryFull,
+		enums.DataResponseNACKFSNOutOfSequence,
+		enums.DataResponseNACKUndeliverable,
+		enums.DataResponseNACKInvalidUser:
+		s.Reset()
+		return nil, false, &NACKError{Type: t}
+	}
+	return nil, false, nil
+}
+
+// Poll retransmits the unacknowledged blocks of the packet in progress if
+// its response has not arrived by time now. Call it periodically while
+// Busy.
+func (s *ConfirmedDataSender) Poll(now time.Time) ([]Burst, error) {
+	if s.header == nil || !now.After(s.deadline) {
+		return nil, nil
+	}
+	s.selective = nil
+	return s.transmit(s.unacked, now)
+}
+
+// transmit returns the header and the given blocks of the packet in
+// progress, and starts the response timer.
+func (s *ConfirmedDataSender) transmit(blocks []int, now time.Time) ([]Burst, error) {
+	limit := s.RetryLimit
+	if limit == 0 {
+		limit = constants.NRtryLmt
+	}
+	if s.attempts >= limit {
+		s.Reset()
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "RetryLimit", Err: elements.ErrRetryLimit}
+	}
+	s.attempts++
+
+	h := *s.header
+	h.BlocksToFollow = uint8(len(blocks)) //nolint:gosec // blocks <= maxPacketBlocks
+	// Only the first transmission of a packet asks the receiver to
+	// resynchronise; later ones add blocks to the packet it holds.
+	s.header.ReSynchronizeFlag = false
+
+	bursts := make([]Burst, 0, 1+len(blocks))
+	bursts = append(bursts, newDataBurst(s.SyncPattern, s.ColorCode,
+		&pdu.DataHeader{DataType: elements.DataTypeDataHeader, Format: pdu.FormatConfirmed, ConfirmedDataHeader: &h}))
+	for _, i := range blocks {
+		bursts = append(bursts, s.blocks[i])
+	}
+	s.unacked = blocks
+
+	wait := s.ResponseWait
+	if wait == 0 {
+		wait = constants.TRspnsWait
+	}
+	s.deadline = now.Add(time.Duration(len(bursts))*burstPeriod + wait)
+	return bursts, nil
+}
+
+// notReceived returns the DBSN of every block of the packet in progress not
+// marked as received in a selective ACK bitmap. If every block is marked, the
+// last transmission is repeated.
+func (s *ConfirmedDataSender) notReceived(bitmap []byte) []int {
+	var blocks []int
+	for i := range s.blocks {
+		if i >= len(bitmap)*8 || bitmap[i/8]&(0x80>>(i%8)) == 0 {
+			blocks = append(blocks, i)
+		}
+	}
+	if len(blocks) == 0 {
+		return s.unacked
+	}
+	return blocks
+}
+
+// allBlocks returns the DBSN of every block of the packet in progress.
+func (s *ConfirmedDataSender) allBlocks() []int {
+	all := make([]int, len(s.blocks))
+	for i := range all {
+		all[i] = i
+	}
+	return all
+}
+
+// dataType returns the rate of the data blocks.
+func (s *ConfirmedDataSender) dataType() elements.DataType {
+	switch s.DataType {
+	case elements.DataTypeRate12, elements.DataTypeRate1:
+		return s.DataType
+	default:
+		return elements.DataTypeRate34
+	}
+}
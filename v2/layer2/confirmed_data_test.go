@@ -0,0 +1,303 @@
+package layer2_test
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/crc"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// overAir encodes bursts and decodes them again, as a receiver would see
+// them.
+func overAir(t *testing.T, bursts []layer2.Burst) []*layer2.Burst {
+	t.Helper()
+	out := make([]*layer2.Burst, 0, len(bursts))
+	for i := range bursts {
+		raw, err := bursts[i].Encode()
+		if err != nil {
+			t.Fatalf("burst %d: Encode: %v", i, err)
+		}
+		b, err := layer2.NewBurstFromBytes(raw)
+		if err != nil {
+			t.Fatalf("burst %d: NewBurstFromBytes: %v", i, err)
+		}
+		out = append(out, b)
+	}
+	return out
+}
+
+// receive feeds bursts to the receiver and returns the last response, any
+// message and the first error.
+func receive(t *testing.T, r *layer2.ConfirmedDataReceiver, bursts []*layer2.Burst, now time.Time) (*layer2.DataResponse, *layer2.PacketData, error) {
+	t.Helper()
+	var resp *layer2.DataResponse
+	var msg *layer2.PacketData
+	var firstErr error
+	for _, b := range bursts {
+		rs, m, err := r.AddBurst(b, now)
+		if rs != nil {
+			resp = rs
+		}
+		if m != nil {
+			msg = m
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return resp, msg, firstErr
+}
+
+// respond passes a response over the air to the sender.
+func respond(t *testing.T, s *layer2.ConfirmedDataSender, resp *layer2.DataResponse, now time.Time) ([]layer2.Burst, bool, error) {
+	t.Helper()
+	var bursts []layer2.Burst
+	var done bool
+	var firstErr error
+	for _, b := range overAir(t, resp.Bursts(enums.BsSourcedData, 1)) {
+		out, d, err := s.AddBurst(b, now)
+		bursts = append(bursts, out...)
+		done = done || d
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return bursts, done, firstErr
+}
+
+func testMessage(payload []byte) *layer2.PacketData {
+	return &layer2.PacketData{
+		SAP:         pdu.ServiceAccessPointIDShortData,
+		Source:      3120001,
+		Destination: 3120002,
+		Payload:     payload,
+	}
+}
+
+func TestConfirmedData_RoundTrip(t *testing.T) {
+	t.Parallel()
+	payload := []byte("The quick brown fox jumps over the lazy dog, twice over for good measure.")
+	for _, dt := range []elements.DataType{elements.DataTypeRate12, elements.DataTypeRate34, elements.DataTypeRate1} {
+		t.Run(elements.DataTypeToName(dt), func(t *testing.T) {
+			t.Parallel()
+			s := layer2.ConfirmedDataSender{SyncPattern: enums.MsSourcedData, ColorCode: 1, DataType: dt}
+			var r layer2.ConfirmedDataReceiver
+			now := time.Unix(0, 0)
+
+			for ns := range uint8(3) {
+				bursts, err := s.Send(testMessage(payload), now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				header := bursts[0].Data.(*pdu.DataHeader).ConfirmedDataHeader
+				if header.SendSequenceNumber != ns || header.ReSynchronizeFlag != (ns == 0) || !header.ResponseRequested {
+					t.Errorf("packet %d header: %+v", ns, header)
+				}
+				if bursts[1].SlotType.DataType != dt {
+					t.Errorf("block data type = %s", elements.DataTypeToName(bursts[1].SlotType.DataType))
+				}
+
+				resp, msg, err := receive(t, &r, overAir(t, bursts), now)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp == nil || resp.Type != enums.DataResponseACK || resp.Status != ns || resp.Source != 3120002 || resp.Destination != 3120001 {
+					t.Fatalf("response = %+v", resp)
+				}
+				if msg == nil || !bytes.Equal(msg.Payload, payload) || msg.Source != 3120001 || msg.Destination != 3120002 {
+					t.Fatalf("message = %+v", msg)
+				}
+
+				out, done, err := respond(t, &s, resp, now)
+				if err != nil || !done || len(out) != 0 || s.Busy() {
+					t.Fatalf("ACK: %d bursts, done %t, busy %t, err %v", len(out), done, s.Busy(), err)
+				}
+			}
+		})
+	}
+}
+
+func TestConfirmedData_SelectiveRetransmission(t *testing.T) {
+	t.Parallel()
+	payload := bytes.Repeat([]byte("0123456789"), 8)
+	s := layer2.ConfirmedDataSender{SyncPattern: enums.MsSourcedData, ColorCode: 1, DataType: elements.DataTypeRate12}
+	var r layer2.ConfirmedDataReceiver
+	now := time.Unix(0, 0)
+
+	bursts, err := s.Send(testMessage(payload), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rx := overAir(t, bursts)
+	// Block 1 fails its FEC and block 3 its CRC-9.
+	rx[2].Data = nil
+	rx[4].Data.(*pdu.Rate12Data).Data[5] ^= 0x10
+
+	resp, msg, err := receive(t, &r, rx, now)
+	if err != nil || msg != nil {
+		t.Fatalf("message %v, err %v", msg, err)
+	}
+	if resp.Type != enums.DataResponseSelectiveACK || !slices.Equal(resp.Retransmit, []int{1, 3}) {
+		t.Fatalf("response = %s", resp.ToString())
+	}
+
+	retry, done, err := respond(t, &s, resp, now)
+	if err != nil || done {
+		t.Fatalf("done %t, err %v", done, err)
+	}
+	if len(retry) != 3 || retry[0].Data.(*pdu.DataHeader).ConfirmedDataHeader.BlocksToFollow != 2 {
+		t.Fatalf("retransmitted %d bursts", len(retry))
+	}
+	for i, dbsn := range []uint8{1, 3} {
+		if got := retry[i+1].Data.(*pdu.Rate12Data).Confirmed().SerialNumber; got != dbsn {
+			t.Errorf("retransmitted block %d DBSN = %d, want %d", i, got, dbsn)
+		}
+	}
+
+	resp, msg, err = receive(t, &r, overAir(t, retry), now)
+	if err != nil || resp.Type != enums.DataResponseACK {
+		t.Fatalf("response %v, err %v", resp, err)
+	}
+	if msg == nil || !bytes.Equal(msg.Payload, payload) {
+		t.Fatalf("message = %v", msg)
+	}
+	if _, done, err = respond(t, &s, resp, now); !done || err != nil {
+		t.Errorf("done %t, err %v", done, err)
+	}
+}
+
+func TestConfirmedData_LostACK(t *testing.T) {
+	t.Parallel()
+	payload := []byte("delivered once")
+	s := layer2.ConfirmedDataSender{SyncPattern: enums.MsSourcedData, ColorCode: 1}
+	var r layer2.ConfirmedDataReceiver
+	now := time.Unix(0, 0)
+
+	bursts, err := s.Send(testMessage(payload), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, msg, err := receive(t, &r, overAir(t, bursts), now); msg == nil || err != nil {
+		t.Fatalf("message %v, err %v", msg, err)
+	}
+
+	// The ACK is lost: the sender retransmits once the response wait has
+	// passed, and the receiver acknowledges the duplicate without
+	// delivering it again.
+	if retry, err := s.Poll(now); retry != nil || err != nil {
+		t.Fatalf("Poll before the response wait: %d bursts, err %v", len(retry), err)
+	}
+	later := now.Add(time.Second)
+	retry, err := s.Poll(later)
+	if err != nil || len(retry) != len(bursts) {
+		t.Fatalf("Poll: %d bursts, err %v", len(retry), err)
+	}
+	resp, msg, err := receive(t, &r, overAir(t, retry), later)
+	if err != nil || msg != nil || resp == nil || resp.Type != enums.DataResponseACK {
+		t.Fatalf("duplicate: response %v, message %v, err %v", resp, msg, err)
+	}
+	if _, done, err := respond(t, &s, resp, later); !done || err != nil {
+		t.Errorf("done %t, err %v", done, err)
+	}
+}
+
+func TestConfirmedData_PacketCRCFailure(t *testing.T) {
+	t.Parallel()
+	payload := bytes.Repeat([]byte{0xC3}, 20)
+	s := layer2.ConfirmedDataSender{SyncPattern: enums.MsSourcedData, ColorCode: 1, DataType: elements.DataTypeRate12}
+	var r layer2.ConfirmedDataReceiver
+	now := time.Unix(0, 0)
+
+	bursts, err := s.Send(testMessage(payload), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Change the data of block 0 with a valid CRC-9, so only the message
+	// CRC catches it.
+	rx := overAir(t, bursts)
+	block := rx[1].Data.(*pdu.Rate12Data)
+	block.Data[2] ^= 0xFF
+	sum := crc.CalculateDataBlockCRC9(0, block.Data[2:], crc.CRC9MaskRate12Data)
+	block.Data[0] = byte(sum >> 8)
+	block.Data[1] = byte(sum)
+
+	resp, msg, err := receive(t, &r, rx, now)
+	if !errors.Is(err, elements.ErrCRCMismatch) || msg != nil {
+		t.Fatalf("message %v, err %v", msg, err)
+	}
+	if resp.Type != enums.DataResponseNACKPacketCRC {
+		t.Fatalf("response = %s", resp.ToString())
+	}
+
+	// The whole packet is sent again.
+	retry, done, err := respond(t, &s, resp, now)
+	if err != nil || done || len(retry) != len(bursts) {
+		t.Fatalf("retransmitted %d bursts, done %t, err %v", len(retry), done, err)
+	}
+	resp, msg, err = receive(t, &r, overAir(t, retry), now)
+	if err != nil || resp.Type != enums.DataResponseACK || msg == nil || !bytes.Equal(msg.Payload, payload) {
+		t.Fatalf("response %v, message %v, err %v", resp, msg, err)
+	}
+}
+
+func TestConfirmedData_SenderFailures(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(0, 0)
+
+	t.Run("retry limit", func(t *testing.T) {
+		t.Parallel()
+		s := layer2.ConfirmedDataSender{RetryLimit: 2, ResponseWait: time.Second}
+		if _, err := s.Send(testMessage([]byte("no answer")), now); err != nil {
+			t.Fatal(err)
+		}
+		if retry, err := s.Poll(now.Add(2 * time.Second)); len(retry) == 0 || err != nil {
+			t.Fatalf("first retry: %d bursts, err %v", len(retry), err)
+		}
+		_, err := s.Poll(now.Add(4 * time.Second))
+		var pduErr *elements.PDUError
+		if !errors.Is(err, elements.ErrRetryLimit) || !errors.As(err, &pduErr) || pduErr.Layer != elements.LayerPacket {
+			t.Fatalf("err = %v, want retry limit", err)
+		}
+		if s.Busy() {
+			t.Error("still busy after the retry limit")
+		}
+	})
+
+	t.Run("NACK", func(t *testing.T) {
+		t.Parallel()
+		var s layer2.ConfirmedDataSender
+		if _, err := s.Send(testMessage([]byte("rejected")), now); err != nil {
+			t.Fatal(err)
+		}
+		resp := &layer2.DataResponse{
+			SAP:         pdu.ServiceAccessPointIDShortData,
+			Source:      3120002,
+			Destination: 3120001,
+			Type:        enums.DataResponseNACKUndeliverable,
+		}
+		_, _, err := respond(t, &s, resp, now)
+		var nack *layer2.NACKError
+		if !errors.Is(err, elements.ErrNACK) || !errors.As(err, &nack) || nack.Type != enums.DataResponseNACKUndeliverable {
+			t.Fatalf("err = %v, want NACK Undeliverable", err)
+		}
+		if s.Busy() {
+			t.Error("still busy after a NACK")
+		}
+	})
+
+	t.Run("payload too large", func(t *testing.T) {
+		t.Parallel()
+		var s layer2.ConfirmedDataSender
+		_, err := s.Send(testMessage(make([]byte, 128*16)), now)
+		if !errors.Is(err, elements.ErrInvalidLength) {
+			t.Fatalf("err = %v, want invalid length", err)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package layer2
+
+import (
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// maxPreambleBlocks is the largest CSBK Blocks to Follow a preamble carries.
+const maxPreambleBlocks = 0xFF
+
+// DataPacket describes one packet data transmission to be built by
+// BuildDataPacket: optional CSBK preambles, a C_HEAD or U_HEAD data header
+// and its data blocks.
+type DataPacket struct {
+	// SyncPattern is the data SYNC sent in every burst, e.g.
+	// enums.BsSourcedData or enums.MsSourcedData.
+	SyncPattern enums.SyncPattern
+	// ColorCode is sent in the slot type of every burst.
+	ColorCode int
+	// Preambles is the number of CSBK preambles (§9.3.7) sent before the
+	// data header to wake the destination.
+	Preambles int
+
+	// SAP, Source, Destination, Group and ResponseRequested are sent in the
+	// data header. A confirmed packet always requests a response.
+	SAP               pdu.ServiceAccessPointID
+	Source            int
+	Destination       int
+	Group             bool
+	ResponseRequested bool
+	// FullMessage and FragmentSequenceNumber are sent in the data header;
+	// set FullMessage with an FSN of 0 for an unfragmented message.
+	FullMessage            bool
+	FragmentSequenceNumber uint8
+
+	// Confirmed selects confirmed delivery (C_HEAD), with a DBSN and CRC-9
+	// in every data block. SendSequenceNumber and ReSynchronize are sent in
+	// its header.
+	Confirmed          bool
+	SendSequenceNumber uint8
+	ReSynchronize      bool
+	// DataType is the rate of the data blocks: elements.DataTypeRate12,
+	// DataTypeRate34 or DataTypeRate1. Any other value uses Rate 1/2.
+	DataType elements.DataType
+
+	// Payload is the user data. The pad octets and message CRC are added
+	// to fill the last block.
+	Payload []byte
+}
+
+// Bursts returns the bursts of the packet: the preambles, whose CSBK
+// Blocks to Follow counts down to the last data block, the data header and
+// the data blocks. A payload that needs more than 127 blocks, or more bursts
+// than the preambles can count, returns an *elements.PDUError wrapping
+// elements.ErrInvalidLength.
+func (p *DataPacket) Bursts() ([]Burst, error) {
+	dt := p.DataType
+	if PacketBlockOctets(dt, false) == 0 {
+		dt = elements.DataTypeRate12
+	}
+	blocks, pad := splitPacket(p.Payload, PacketBlockOctets(dt, p.Confirmed))
+	if len(blocks) > maxPacketBlocks {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Payload", Err: elements.ErrInvalidLength}
+	}
+	if p.Preambles < 0 || p.Preambles+len(blocks) > maxPreambleBlocks {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Preambles", Err: elements.ErrInvalidLength}
+	}
+
+	bursts := make([]Burst, 0, p.Preambles+1+len(blocks))
+	for i := range p.Preambles {
+		bursts = append(bursts, newDataBurst(p.SyncPattern, p.ColorCode, p.preamble(p.Preambles-i+len(blocks))))
+	}
+	bursts = append(bursts, newDataBurst(p.SyncPattern, p.ColorCode, p.header(len(blocks), pad)))
+	for i, block := range blocks {
+		if p.Confirmed {
+			block = encodeConfirmedBlock(dt, uint8(i), block) //nolint:gosec // i < maxPacketBlocks, fits in the 7-bit DBSN
+		}
+		bursts = append(bursts, newDataBurst(p.SyncPattern, p.ColorCode, dataBlockData(dt, block)))
+	}
+	return bursts, nil
+}
+
+// preamble returns a CSBK preamble followed by blocksToFollow bursts.
+func (p *DataPacket) preamble(blocksToFollow int) *pdu.CSBK {
+	pre := &pdu.PreamblePDU{
+		Data:               true,
+		Group:              p.Group,
+		CSBKBlocksToFollow: byte(blocksToFollow), //nolint:gosec // bounded by maxPreambleBlocks
+	}
+	copy(pre.TargetAddress[:], bit.BitsFromUint32(uint32(p.Destination), 24)) //nolint:gosec // LLIDs are 24 bits
+	copy(pre.SourceAddress[:], bit.BitsFromUint32(uint32(p.Source), 24))      //nolint:gosec // LLIDs are 24 bits
+	return &pdu.CSBK{
+		DataType:    elements.DataTypeCSBK,
+		LastBlock:   true,
+		CSBKOpcode:  pdu.CSBKPreamblePDU,
+		PreamblePDU: pre,
+	}
+}
+
+// header returns the data header of a packet of blocks data blocks with pad
+// pad octets.
+func (p *DataPacket) header(blocks, pad int) *pdu.DataHeader {
+	if p.Confirmed {
+		return &pdu.DataHeader{
+			DataType: elements.DataTypeDataHeader,
+			Format:   pdu.FormatConfirmed,
+			ConfirmedDataHeader: &pdu.ConfirmedDataHeader{
+				Group:                  p.Group,
+				ResponseRequested:      true,
+				PadOctetCount:          uint8(pad), //nolint:gosec // pad < one block
+				SAP:                    uint8(p.SAP),
+				LLIDDestination:        p.Destination,
+				LLIDSource:             p.Source,
+				FullMessageFlag:        p.FullMessage,
+				BlocksToFollow:         uint8(blocks), //nolint:gosec // blocks <= maxPacketBlocks
+				ReSynchronizeFlag:      p.ReSynchronize,
+				SendSequenceNumber:     p.SendSequenceNumber,
+				FragmentSequenceNumber: p.FragmentSequenceNumber,
+			},
+		}
+	}
+
+	h := &pdu.UnconfirmedDataHeader{
+		Group:                  p.Group,
+		ResponseRequested:      p.ResponseRequested,
+		PadOctetCount:          uint8(pad), //nolint:gosec // pad < one block
+		SAP:                    uint8(p.SAP),
+		FullMessage:            p.FullMessage,
+		BlocksToFollow:         uint8(blocks), //nolint:gosec // blocks <= maxPacketBlocks
+		FragmentSequenceNumber: p.FragmentSequenceNumber,
+	}
+	copy(h.LLIDDestination[:], bit.BitsFromUint32(uint32(p.Destination), 24)) //nolint:gosec // LLIDs are 24 bits
+	copy(h.LLIDSource[:], bit.BitsFromUint32(uint32(p.Source), 24))           //nolint:gosec // LLIDs are 24 bits
+	return &pdu.DataHeader{DataType: elements.DataTypeDataHeader, Format: pdu.FormatUnconfirmed, UnconfirmedDataHeader: h}
+}
+
+// BuildDataPacket encodes the bursts of a packet data transmission.
+func BuildDataPacket(p *DataPacket) ([][33]byte, error) {
+	bursts, err := p.Bursts()
+	if err != nil {
+		return nil, err
+	}
+	out := make([][33]byte, len(bursts))
+	for i := range bursts {
+		encoded, err := bursts[i].Encode()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = encoded
+	}
+	return out, nil
+}
@@ -0,0 +1,213 @@
+package layer2_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// burstData returns the Data of b as a T, failing the test if it is not one.
+func burstData[T elements.Data](t testing.TB, b *layer2.Burst) T {
+	t.Helper()
+	d, ok := b.Data.(T)
+	if !ok {
+		t.Fatalf("burst data is %T, want %T", b.Data, *new(T))
+	}
+	return d
+}
+
+// The captures are rebuilt bit for bit from their reassembled message.
+func TestBuildDataPacket_Captures(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		file string
+		// header is false for a capture whose U_HEAD sets reserved bits,
+		// which the builder always clears.
+		header bool
+	}{
+		{"testdata/d-sms.bin", true},
+		{"testdata/m-sms.bin", false},
+	}
+	for _, tt := range tests {
+		file := tt.file
+		raw := loadBursts(t, file)
+		var a layer2.UnconfirmedDataAssembler
+		var msg *layer2.PacketData
+		var header *pdu.UnconfirmedDataHeader
+		preambles := 0
+		for i := range raw {
+			burst, err := layer2.NewBurstFromBytes(raw[i])
+			if err != nil {
+				t.Fatalf("%s burst %d: %v", file, i, err)
+			}
+			switch d := burst.Data.(type) {
+			case *pdu.CSBK:
+				preambles++
+			case *pdu.DataHeader:
+				header = d.UnconfirmedDataHeader
+			}
+			m, err := a.AddBurst(burst, time.Unix(0, 0))
+			if err != nil {
+				t.Fatalf("%s burst %d: %v", file, i, err)
+			}
+			if m != nil {
+				msg = m
+			}
+		}
+		if msg == nil || header == nil {
+			t.Fatalf("%s: no message", file)
+		}
+
+		first, err := layer2.NewBurstFromBytes(raw[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		built, err := layer2.BuildDataPacket(&layer2.DataPacket{
+			SyncPattern:       first.SyncPattern,
+			ColorCode:         first.SlotType.ColorCode,
+			Preambles:         preambles,
+			SAP:               msg.SAP,
+			Source:            msg.Source,
+			Destination:       msg.Destination,
+			Group:             msg.Group,
+			ResponseRequested: header.ResponseRequested,
+			FullMessage:       true,
+			DataType:          elements.DataTypeRate12,
+			Payload:           msg.Payload,
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		if len(built) != len(raw) {
+			t.Fatalf("%s: built %d bursts, want %d", file, len(built), len(raw))
+		}
+		for i := range raw {
+			if i == preambles && !tt.header {
+				continue
+			}
+			if built[i] != raw[i] {
+				t.Errorf("%s burst %d:\n got % X\nwant % X", file, i, built[i], raw[i])
+			}
+		}
+	}
+}
+
+func TestDataPacket_Bursts_Layout(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		dt        elements.DataType
+		confirmed bool
+		payload   int
+		blocks    int
+		pad       int
+	}{
+		{"Rate 1/2 unconfirmed", elements.DataTypeRate12, false, 20, 2, 0},
+		{"Rate 1/2 confirmed", elements.DataTypeRate12, true, 20, 3, 6},
+		{"Rate 3/4 unconfirmed", elements.DataTypeRate34, false, 50, 3, 0},
+		{"Rate 3/4 confirmed", elements.DataTypeRate34, true, 50, 4, 10},
+		{"Rate 1 unconfirmed", elements.DataTypeRate1, false, 1, 1, 19},
+		{"Rate 1 confirmed", elements.DataTypeRate1, true, 40, 2, 0},
+		{"empty payload", elements.DataTypeRate12, false, 0, 1, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			payload := bytes.Repeat([]byte{0x3C}, tt.payload)
+			packet := &layer2.DataPacket{
+				SyncPattern: enums.BsSourcedData,
+				ColorCode:   7,
+				Preambles:   3,
+				SAP:         pdu.ServiceAccessPointIDShortData,
+				Source:      3120001,
+				Destination: 91,
+				Group:       true,
+				FullMessage: true,
+				Confirmed:   tt.confirmed,
+				DataType:    tt.dt,
+				Payload:     payload,
+			}
+			built, err := layer2.BuildDataPacket(packet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(built) != 3+1+tt.blocks {
+				t.Fatalf("built %d bursts, want %d", len(built), 3+1+tt.blocks)
+			}
+
+			bursts := make([]*layer2.Burst, len(built))
+			for i := range built {
+				if bursts[i], err = layer2.NewBurstFromBytes(built[i]); err != nil {
+					t.Fatalf("burst %d: %v", i, err)
+				}
+				if bursts[i].SlotType.ColorCode != 7 || bursts[i].SyncPattern != enums.BsSourcedData {
+					t.Errorf("burst %d: %s", i, bursts[i].ToString())
+				}
+			}
+			for i := range 3 {
+				pre := burstData[*pdu.CSBK](t, bursts[i]).PreamblePDU
+				if pre == nil || !pre.Data || !pre.Group || int(pre.CSBKBlocksToFollow) != 3-i+tt.blocks {
+					t.Errorf("preamble %d: %+v", i, pre)
+				}
+			}
+			for i, b := range bursts[4:] {
+				if b.SlotType.DataType != tt.dt {
+					t.Errorf("block %d data type = %s", i, elements.DataTypeToName(b.SlotType.DataType))
+				}
+			}
+
+			header := burstData[*pdu.DataHeader](t, bursts[3])
+			if tt.confirmed {
+				h := header.ConfirmedDataHeader
+				if h == nil || int(h.BlocksToFollow) != tt.blocks || int(h.PadOctetCount) != tt.pad || !h.ResponseRequested {
+					t.Fatalf("C_HEAD = %+v", h)
+				}
+				var r layer2.ConfirmedDataReceiver
+				resp, msg, err := receive(t, &r, bursts, time.Unix(0, 0))
+				if err != nil || resp.Type != enums.DataResponseACK || msg == nil || !bytes.Equal(msg.Payload, payload) {
+					t.Fatalf("response %v, message %v, err %v", resp, msg, err)
+				}
+				return
+			}
+
+			h := header.UnconfirmedDataHeader
+			if h == nil || int(h.BlocksToFollow) != tt.blocks || int(h.PadOctetCount) != tt.pad {
+				t.Fatalf("U_HEAD = %+v", h)
+			}
+			var a layer2.UnconfirmedDataAssembler
+			msgs, errs := feedBursts(&a, time.Unix(0, 0), bursts)
+			if len(errs) != 0 || len(msgs) != 1 || !bytes.Equal(msgs[0].Payload, payload) || !msgs[0].Group {
+				t.Fatalf("messages %v, errors %v", msgs, errs)
+			}
+		})
+	}
+}
+
+func TestDataPacket_Bursts_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		packet layer2.DataPacket
+		field  string
+	}{
+		{"too many blocks", layer2.DataPacket{Payload: make([]byte, 128*12)}, "Payload"},
+		{"too many confirmed blocks", layer2.DataPacket{Confirmed: true, DataType: elements.DataTypeRate1, Payload: make([]byte, 128*22)}, "Payload"},
+		{"too many preambles", layer2.DataPacket{Preambles: 255, Payload: []byte{1}}, "Preambles"},
+		{"negative preambles", layer2.DataPacket{Preambles: -1}, "Preambles"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.packet.Bursts()
+			testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, tt.field)
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package layer2
+
+import (
+	"encoding/binary"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/utf16text"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+)
+
+// ETSI TS 102 361-1 — Defined Data format (DD)
+//
+// The user data of a defined short data message is packed most significant
+// bit first with no gaps: BCD digits in 4 bits, 7-bit characters in 7 bits,
+// ISO/IEC 8859 characters in one octet and Unicode in its encoding form.
+// UTF-16 and UTF-32 without a byte order are big endian unless the data
+// starts with a byte order mark.
+
+const (
+	bcdBits       = 4
+	septetBits    = 7
+	asciiMax      = 0x7F
+	iso8859Shared = 0xA0 // octets below this are the same in every part of ISO/IEC 8859
+	byteOrderMark = 0xFEFF
+	// byteOrderMarkSwapped is a byte order mark read in the wrong byte order.
+	byteOrderMarkSwapped = 0xFFFE
+)
+
+// errInvalidEncoding returns the error for user data or text that is not
+// valid in its format.
+func errInvalidEncoding() error {
+	return &elements.PDUError{Layer: elements.LayerPacket, Field: "Data", Err: elements.ErrInvalidEncoding}
+}
+
+// decodeDDText returns the characters of the first bitLength bits of data in
+// character format f.
+func decodeDDText(f enums.DDFormat, data []byte, bitLength int) (string, error) {
+	octets := data[:bitLength/8]
+	switch f {
+	case enums.DDFormat7BitCharacter:
+		bits := bit.UnpackBits(data)
+		var sb strings.Builder
+		for i := 0; i+septetBits <= bitLength; i += septetBits {
+			sb.WriteByte(bit.BitsToUint8(bits, i, septetBits))
+		}
+		return sb.String(), nil
+	case enums.DDFormatUTF8:
+		if !utf8.Valid(octets) {
+			return "", errInvalidEncoding()
+		}
+		return string(octets), nil
+	case enums.DDFormatUTF16, enums.DDFormatUTF16BE, enums.DDFormatUTF16LE:
+		return decodeUTF16(f, octets)
+	case enums.DDFormatUTF32, enums.DDFormatUTF32BE, enums.DDFormatUTF32LE:
+		return decodeUTF32(f, octets)
+	}
+
+	upper := iso8859Upper(f.ISO8859Part())
+	if upper == nil {
+		return "", &elements.PDUError{Layer: elements.LayerPacket, Field: "Format", Err: elements.ErrInvalidEncoding}
+	}
+	runes := make([]rune, len(octets))
+	for i, c := range octets {
+		runes[i] = rune(c)
+		if c >= iso8859Shared {
+			runes[i] = upper[c-iso8859Shared]
+		}
+		if runes[i] == iso8859Undefined {
+			return "", errInvalidEncoding()
+		}
+	}
+	return string(runes), nil
+}
+
+// encodeDDText returns text encoded in character format f and its length
+// in bits.
+func encodeDDText(f enums.DDFormat, text string) ([]byte, int, error) {
+	if !utf8.ValidString(text) {
+		return nil, 0, errInvalidEncoding()
+	}
+	runes := []rune(text)
+	switch f {
+	case enums.DDFormat7BitCharacter:
+		bits := make([]bit.Bit, 0, len(runes)*septetBits)
+		for _, r := range runes {
+			if r > asciiMax {
+				return nil, 0, errInvalidEncoding()
+			}
+			bits = append(bits, bit.BitsFromUint8(uint8(r), septetBits)...) //nolint:gosec // r <= asciiMax
+		}
+		return bit.PackBits(bits), len(bits), nil
+	case enums.DDFormatUTF8:
+		return []byte(text), len(text) * 8, nil
+	case enums.DDFormatUTF16, enums.DDFormatUTF16BE, enums.DDFormatUTF16LE:
+		var order binary.AppendByteOrder = binary.BigEndian
+		if f == enums.DDFormatUTF16LE {
+			order = binary.LittleEndian
+		}
+		data := utf16text.Append(make([]byte, 0, 2*len(runes)), text, order)
+		return data, len(data) * 8, nil
+	case enums.DDFormatUTF32, enums.DDFormatUTF32BE, enums.DDFormatUTF32LE:
+		data := make([]byte, 0, 4*len(runes))
+		for _, r := range runes {
+			u := uint32(r) //nolint:gosec // valid UTF-8 decodes to non-negative runes
+			if f == enums.DDFormatUTF32LE {
+				data = append(data, byte(u), byte(u>>8), byte(u>>16), byte(u>>24))
+			} else {
+				data = append(data, byte(u>>24), byte(u>>16), byte(u>>8), byte(u))
+			}
+		}
+		return data, len(data) * 8, nil
+	}
+
+	upper := iso8859Upper(f.ISO8859Part())
+	if upper == nil {
+		return nil, 0, &elements.PDUError{Layer: elements.LayerPacket, Field: "Format", Err: elements.ErrInvalidEncoding}
+	}
+	data := make([]byte, len(runes))
+	for i, r := range runes {
+		c, ok := iso8859Octet(upper, r)
+		if !ok {
+			return nil, 0, errInvalidEncoding()
+		}
+		data[i] = c
+	}
+	return data, len(data) * 8, nil
+}
+
+// decodeBCD returns the digits of the first bitLength bits of data.
+func decodeBCD(data []byte, bitLength int) (string, error) {
+	bits := bit.UnpackBits(data)
+	var sb strings.Builder
+	for i := 0; i+bcdBits <= bitLength; i += bcdBits {
+		digit := bit.BitsToUint8(bits, i, bcdBits)
+		if digit > 9 {
+			return "", errInvalidEncoding()
+		}
+		sb.WriteByte(byte('0' + digit))
+	}
+	return sb.String(), nil
+}
+
+// encodeBCD returns the BCD encoding of a string of decimal digits and its
+// length in bits.
+func encodeBCD(digits string) ([]byte, int, error) {
+	bits := make([]bit.Bit, 0, len(digits)*bcdBits)
+	for i := range len(digits) {
+		if digits[i] < '0' || digits[i] > '9' {
+			return nil, 0, errInvalidEncoding()
+		}
+		bits = append(bits, bit.BitsFromUint8(digits[i]-'0', bcdBits)...)
+	}
+	return bit.PackBits(bits), len(bits), nil
+}
+
+// decodeUTF16 decodes UTF-16 in the byte order of f, or for DDFormatUTF16
+// in the byte order given by a leading byte order mark.
+func decodeUTF16(f enums.DDFormat, data []byte) (string, error) {
+	var order binary.ByteOrder = binary.BigEndian
+	if f == enums.DDFormatUTF16LE {
+		order = binary.LittleEndian
+	}
+	if f == enums.DDFormatUTF16 && len(data) >= 2 {
+		switch binary.BigEndian.Uint16(data) {
+		case byteOrderMark:
+			data = data[2:]
+		case byteOrderMarkSwapped:
+			data = data[2:]
+			order = binary.LittleEndian
+		}
+	}
+	text, ok := utf16text.Decode(data, order)
+	if !ok {
+		return "", errInvalidEncoding()
+	}
+	return text, nil
+}
+
+// decodeUTF32 decodes UTF-32 in the byte order of f, or for DDFormatUTF32
+// in the byte order given by a leading byte order mark.
+func decodeUTF32(f enums.DDFormat, data []byte) (string, error) {
+	if len(data)%4 != 0 {
+		return "", errInvalidEncoding()
+	}
+	littleEndian := f == enums.DDFormatUTF32LE
+	if f == enums.DDFormatUTF32 && len(data) >= 4 {
+		switch {
+		case data[0] == 0 && data[1] == 0 && data[2] == 0xFE && data[3] == 0xFF:
+			data = data[4:]
+		case data[0] == 0xFF && data[1] == 0xFE && data[2] == 0 && data[3] == 0:
+			data = data[4:]
+			littleEndian = true
+		}
+	}
+	runes := make([]rune, len(data)/4)
+	for i := range runes {
+		o := data[4*i : 4*i+4]
+		var u uint32
+		if littleEndian {
+			u = uint32(o[0]) | uint32(o[1])<<8 | uint32(o[2])<<16 | uint32(o[3])<<24
+		} else {
+			u = uint32(o[0])<<24 | uint32(o[1])<<16 | uint32(o[2])<<8 | uint32(o[3])
+		}
+		if u > utf8.MaxRune || !utf8.ValidRune(rune(u)) { //nolint:gosec // u <= utf8.MaxRune when converted
+			return "", errInvalidEncoding()
+		}
+		runes[i] = rune(u) //nolint:gosec // u <= utf8.MaxRune
+	}
+	return string(runes), nil
+}
+
+// iso8859Upper returns the characters of octets 0xA0 to 0xFF in a part of
+// ISO/IEC 8859, or nil for a part that does not exist. Parts 1 (Latin-1),
+// 5 (Cyrillic), 9 (Turkish) and 15 (Latin-9) are written as changes to
+// Latin-1; the others are in iso8859Tables. The result must not be
+// modified.
+func iso8859Upper(part int) *[0x100 - iso8859Shared]rune {
+	if t, ok := iso8859Tables[part]; ok {
+		return t
+	}
+	var t [0x100 - iso8859Shared]rune
+	for i := range t {
+		t[i] = rune(iso8859Shared + i)
+	}
+	set := func(c byte, r rune) { t[c-iso8859Shared] = r }
+
+	switch part {
+	case 1:
+	case 5:
+		// Cyrillic sits 0x360 above its octet, apart from NBSP, SHY, the
+		// numero sign and the section sign.
+		for i := range t {
+			t[i] += 0x360
+		}
+		set(0xA0, 0x00A0)
+		set(0xAD, 0x00AD)
+		set(0xF0, 0x2116)
+		set(0xFD, 0x00A7)
+	case 9:
+		set(0xD0, 0x011E)
+		set(0xDD, 0x0130)
+		set(0xDE, 0x015E)
+		set(0xF0, 0x011F)
+		set(0xFD, 0x0131)
+		set(0xFE, 0x015F)
+	case 15:
+		set(0xA4, 0x20AC)
+		set(0xA6, 0x0160)
+		set(0xA8, 0x0161)
+		set(0xB4, 0x017D)
+		set(0xB8, 0x017E)
+		set(0xBC, 0x0152)
+		set(0xBD, 0x0153)
+		set(0xBE, 0x0178)
+	default:
+		return nil
+	}
+	return &t
+}
+
+// iso8859Octet returns the octet of r in the part of ISO/IEC 8859 whose
+// upper half is upper.
+func iso8859Octet(upper *[0x100 - iso8859Shared]rune, r rune) (byte, bool) {
+	if r >= 0 && r < iso8859Shared {
+		return byte(r), true
+	}
+	for i, u := range upper {
+		if u == r {
+			return byte(iso8859Shared + i), true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,206 @@
+package layer2
+
+import (
+	"fmt"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// ETSI TS 102 361-3 §6.1 — Defined short data
+//
+// A defined short data message is a DD_HEAD data header followed by its
+// appended blocks. The header gives the DD format of the user data and the
+// number of pad bits between the user data and the 32-bit message CRC in
+// the last four octets of the last block. The CRC covers the user data and
+// the pad bits.
+
+// maxAppendedBlocks is the largest Appended Blocks a DD_HEAD carries.
+const maxAppendedBlocks = 0x3F
+
+// DefinedShortData is a defined short data message.
+type DefinedShortData struct {
+	// SAP is the service access point from the data header.
+	SAP pdu.ServiceAccessPointID
+	// Source and Destination are the LLIDs from the data header.
+	Source      int
+	Destination int
+	// Group reports a message addressed to a talkgroup.
+	Group bool
+	// ResponseRequested, SARQ (selective automatic repeat request) and
+	// FullMessage are the flags from the data header.
+	ResponseRequested bool
+	SARQ              bool
+	FullMessage       bool
+	// Format is the DD format of Data.
+	Format enums.DDFormat
+	// Data holds BitLength bits of user data, most significant bit first.
+	// The bits of the last octet past BitLength are zero.
+	Data      []byte
+	BitLength int
+}
+
+// ToString returns a string representation of the message.
+func (d *DefinedShortData) ToString() string {
+	return fmt.Sprintf("DefinedShortData{ SAP: %d, Source: %d, Destination: %d, Group: %t, ResponseRequested: %t, SARQ: %t, FullMessage: %t, Format: %s, BitLength: %d, Data: % X }",
+		d.SAP, d.Source, d.Destination, d.Group, d.ResponseRequested, d.SARQ, d.FullMessage, enums.DDFormatToName(d.Format), d.BitLength, d.Data)
+}
+
+// DecodeDefinedShortData returns the message carried by a DD_HEAD and its
+// appended blocks. Data block bursts that failed to decode should still be
+// passed in; they fail the message CRC.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrInvalidLength when
+// the number of blocks or the bit padding does not match the header, or
+// elements.ErrCRCMismatch.
+func DecodeDefinedShortData(h *pdu.DefinedDataHeader, blocks []*Burst) (*DefinedShortData, error) {
+	if len(blocks) == 0 || len(blocks) != int(h.AppendedBlocks) {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "AppendedBlocks", Err: elements.ErrInvalidLength}
+	}
+	var data []byte
+	for i, b := range blocks {
+		octets, ok := dataBlockOctets(b)
+		if !ok {
+			return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: fmt.Sprintf("Block[%d]", i), Err: elements.ErrDataTypeMismatch}
+		}
+		data = append(data, octets...)
+	}
+	return decodeDefinedShortData(h, data)
+}
+
+// decodeDefinedShortData returns the message carried by a DD_HEAD and the
+// octets of its appended blocks.
+func decodeDefinedShortData(h *pdu.DefinedDataHeader, data []byte) (*DefinedShortData, error) {
+	body, err := checkPacketCRC(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	bitLength := len(body)*8 - int(h.BitPadding)
+	if bitLength < 0 {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "BitPadding", Err: elements.ErrInvalidLength}
+	}
+
+	body = body[:(bitLength+7)/8]
+	if rem := bitLength % 8; rem != 0 {
+		body[len(body)-1] &= 0xFF << (8 - rem)
+	}
+	return &DefinedShortData{
+		SAP:               pdu.ServiceAccessPointID(h.SAP),
+		Source:            h.LLIDSource,
+		Destination:       h.LLIDDestination,
+		Group:             h.Group,
+		ResponseRequested: h.ResponseRequested,
+		SARQ:              h.SARQ,
+		FullMessage:       h.FullMessageFlag,
+		Format:            enums.DDFormat(h.DefinedData),
+		Data:              body,
+		BitLength:         bitLength,
+	}, nil
+}
+
+// Text returns the characters of a message in a 7-bit, ISO/IEC 8859 or
+// Unicode format. Errors are an *elements.PDUError wrapping
+// elements.ErrInvalidEncoding when the format does not carry text or the
+// data is not valid in it.
+func (d *DefinedShortData) Text() (string, error) {
+	if !d.Format.IsText() {
+		return "", &elements.PDUError{Layer: elements.LayerPacket, Field: "Format", Err: elements.ErrInvalidEncoding}
+	}
+	if err := d.checkLength(); err != nil {
+		return "", err
+	}
+	return decodeDDText(d.Format, d.Data, d.BitLength)
+}
+
+// Digits returns the decimal digits of a BCD message. Errors are an
+// *elements.PDUError wrapping elements.ErrInvalidEncoding when the format
+// is not BCD or a digit is out of range.
+func (d *DefinedShortData) Digits() (string, error) {
+	if d.Format != enums.DDFormatBCD {
+		return "", &elements.PDUError{Layer: elements.LayerPacket, Field: "Format", Err: elements.ErrInvalidEncoding}
+	}
+	if err := d.checkLength(); err != nil {
+		return "", err
+	}
+	return decodeBCD(d.Data, d.BitLength)
+}
+
+// SetText sets the Format, Data and BitLength of the message to text in a
+// 7-bit, ISO/IEC 8859 or Unicode format. Errors are as for Text.
+func (d *DefinedShortData) SetText(format enums.DDFormat, text string) error {
+	if !format.IsText() {
+		return &elements.PDUError{Layer: elements.LayerPacket, Field: "Format", Err: elements.ErrInvalidEncoding}
+	}
+	data, bitLength, err := encodeDDText(format, text)
+	if err != nil {
+		return err
+	}
+	d.Format, d.Data, d.BitLength = format, data, bitLength
+	return nil
+}
+
+// SetDigits sets the message to a string of decimal digits in BCD. A
+// non-digit returns an *elements.PDUError wrapping
+// elements.ErrInvalidEncoding.
+func (d *DefinedShortData) SetDigits(digits string) error {
+	data, bitLength, err := encodeBCD(digits)
+	if err != nil {
+		return err
+	}
+	d.Format, d.Data, d.BitLength = enums.DDFormatBCD, data, bitLength
+	return nil
+}
+
+// checkLength verifies that Data holds BitLength bits.
+func (d *DefinedShortData) checkLength() error {
+	if d.BitLength < 0 || len(d.Data) != (d.BitLength+7)/8 {
+		return &elements.PDUError{Layer: elements.LayerPacket, Field: "BitLength", Err: elements.ErrInvalidLength}
+	}
+	return nil
+}
+
+// Bursts returns the DD_HEAD and appended blocks of the message, sent with
+// the data SYNC syncPattern and colorCode. dt is the rate of the blocks:
+// elements.DataTypeRate12, DataTypeRate34 or DataTypeRate1; any other
+// value uses Rate 1/2. Data that does not hold BitLength bits, or needs
+// more than 63 blocks, returns an *elements.PDUError wrapping
+// elements.ErrInvalidLength.
+func (d *DefinedShortData) Bursts(syncPattern enums.SyncPattern, colorCode int, dt elements.DataType) ([]Burst, error) {
+	if err := d.checkLength(); err != nil {
+		return nil, err
+	}
+	if PacketBlockOctets(dt, false) == 0 {
+		dt = elements.DataTypeRate12
+	}
+	blocks, pad := splitPacket(d.Data, PacketBlockOctets(dt, false))
+	if len(blocks) > maxAppendedBlocks {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Data", Err: elements.ErrInvalidLength}
+	}
+
+	appended := uint8(len(blocks)) //nolint:gosec // len(blocks) <= maxAppendedBlocks
+	header := &pdu.DataHeader{
+		DataType:       elements.DataTypeDataHeader,
+		Format:         pdu.FormatShortDataDefined,
+		AppendedBlocks: appended,
+		DefinedDataHeader: &pdu.DefinedDataHeader{
+			Group:             d.Group,
+			ResponseRequested: d.ResponseRequested,
+			AppendedBlocks:    appended,
+			SAP:               uint8(d.SAP),
+			LLIDDestination:   d.Destination,
+			LLIDSource:        d.Source,
+			DefinedData:       uint8(d.Format),
+			SARQ:              d.SARQ,
+			FullMessageFlag:   d.FullMessage,
+			BitPadding:        uint8(pad*8 + len(d.Data)*8 - d.BitLength), //nolint:gosec // less than one block of bits
+		},
+	}
+
+	bursts := make([]Burst, 0, 1+len(blocks))
+	bursts = append(bursts, newDataBurst(syncPattern, colorCode, header))
+	for _, block := range blocks {
+		bursts = append(bursts, newDataBurst(syncPattern, colorCode, dataBlockData(dt, block)))
+	}
+	return bursts, nil
+}
@@ -0,0 +1,278 @@
+package layer2_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// The Hytera capture is a DD_HEAD with 48 bits of padding before the
+// message CRC. Its header declares BCD, but the user data is Hytera's own
+// UTF-16LE text message.
+func TestDecodeDefinedShortData_Capture(t *testing.T) {
+	t.Parallel()
+	var header *pdu.DefinedDataHeader
+	var blocks []*layer2.Burst
+	for i, raw := range loadBursts(t, "testdata/h-sms.bin") {
+		b, err := layer2.NewBurstFromBytes(raw)
+		if err != nil {
+			t.Fatalf("burst %d: %v", i, err)
+		}
+		switch d := b.Data.(type) {
+		case *pdu.DataHeader:
+			header = d.DefinedDataHeader
+		case *pdu.Rate12Data:
+			blocks = append(blocks, b)
+		}
+	}
+	if header == nil {
+		t.Fatal("no DD_HEAD")
+	}
+
+	msg, err := layer2.DecodeDefinedShortData(header, blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Format != enums.DDFormatBCD || msg.BitLength != 26*8 || len(msg.Data) != 26 || !msg.FullMessage || msg.SARQ {
+		t.Fatalf("message = %s", msg.ToString())
+	}
+	want := append(append([]byte{0, 0}, utf16LE("TEST KI5VMF")...), 0, 0)
+	if !bytes.Equal(msg.Data, want) {
+		t.Errorf("Data = % X, want % X", msg.Data, want)
+	}
+}
+
+func utf16LE(s string) []byte {
+	out := make([]byte, 0, 2*len(s))
+	for i := range len(s) {
+		out = append(out, s[i], 0)
+	}
+	return out
+}
+
+func TestDefinedShortData_Text_RoundTrip(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		format enums.DDFormat
+		text   string
+		data   []byte
+	}{
+		{"7-bit", enums.DDFormat7BitCharacter, "Hi!", []byte{0x91, 0xA5, 0x08}},
+		{"ISO 8859-1", enums.DDFormatISO8859_1, "café", []byte{'c', 'a', 'f', 0xE9}},
+		{"ISO 8859-5", enums.DDFormatISO8859_5, "Привет №", []byte{0xBF, 0xE0, 0xD8, 0xD2, 0xD5, 0xE2, ' ', 0xF0}},
+		{"ISO 8859-9", enums.DDFormatISO8859_9, "İğ", []byte{0xDD, 0xF0}},
+		{"ISO 8859-15", enums.DDFormatISO8859_15, "5€", []byte{'5', 0xA4}},
+		{"ISO 8859-2", enums.DDFormatISO8859_2, "Łódź", []byte{0xA3, 0xF3, 'd', 0xBC}},
+		{"ISO 8859-3", enums.DDFormatISO8859_3, "ĉu", []byte{0xE6, 'u'}},
+		{"ISO 8859-4", enums.DDFormatISO8859_4, "ŗū", []byte{0xB3, 0xFE}},
+		{"ISO 8859-6", enums.DDFormatISO8859_6, "سلام", []byte{0xD3, 0xE4, 0xC7, 0xE5}},
+		{"ISO 8859-7", enums.DDFormatISO8859_7, "Γειά €", []byte{0xC3, 0xE5, 0xE9, 0xDC, ' ', 0xA4}},
+		{"ISO 8859-8", enums.DDFormatISO8859_8, "שלום", []byte{0xF9, 0xEC, 0xE5, 0xED}},
+		{"ISO 8859-10", enums.DDFormatISO8859_10, "Þŋ", []byte{0xDE, 0xBF}},
+		{"ISO 8859-11", enums.DDFormatISO8859_11, "สวัสดี", []byte{0xCA, 0xC7, 0xD1, 0xCA, 0xB4, 0xD5}},
+		{"ISO 8859-13", enums.DDFormatISO8859_13, "Ąž", []byte{0xC0, 0xFE}},
+		{"ISO 8859-14", enums.DDFormatISO8859_14, "Ŵẁ", []byte{0xD0, 0xB8}},
+		{"ISO 8859-16", enums.DDFormatISO8859_16, "Șț", []byte{0xAA, 0xFE}},
+		{"UTF-8", enums.DDFormatUTF8, "73 ☺", []byte("73 ☺")},
+		{"UTF-16", enums.DDFormatUTF16, "Aé", []byte{0x00, 0x41, 0x00, 0xE9}},
+		{"UTF-16BE", enums.DDFormatUTF16BE, "𝄞", []byte{0xD8, 0x34, 0xDD, 0x1E}},
+		{"UTF-16LE", enums.DDFormatUTF16LE, "TEST", utf16LE("TEST")},
+		{"UTF-32", enums.DDFormatUTF32, "A", []byte{0, 0, 0, 0x41}},
+		{"UTF-32BE", enums.DDFormatUTF32BE, "€", []byte{0, 0, 0x20, 0xAC}},
+		{"UTF-32LE", enums.DDFormatUTF32LE, "€", []byte{0xAC, 0x20, 0, 0}},
+		{"empty", enums.DDFormatUTF8, "", []byte{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			msg := layer2.DefinedShortData{
+				SAP:         pdu.ServiceAccessPointIDShortData,
+				Source:      3120001,
+				Destination: 3120002,
+				SARQ:        true,
+				FullMessage: true,
+			}
+			if err := msg.SetText(tt.format, tt.text); err != nil {
+				t.Fatal(err)
+			}
+			if msg.Format != tt.format || !bytes.Equal(msg.Data, tt.data) {
+				t.Fatalf("SetText: format %s, data % X, want % X", enums.DDFormatToName(msg.Format), msg.Data, tt.data)
+			}
+
+			got := sendDefinedShortData(t, &msg, elements.DataTypeRate12)
+			if got.Format != tt.format || got.BitLength != msg.BitLength || !got.SARQ || !got.FullMessage ||
+				got.Source != 3120001 || got.Destination != 3120002 || got.SAP != pdu.ServiceAccessPointIDShortData {
+				t.Fatalf("received %s", got.ToString())
+			}
+			text, err := got.Text()
+			if err != nil || text != tt.text {
+				t.Errorf("Text() = %q, %v, want %q", text, err, tt.text)
+			}
+		})
+	}
+}
+
+// sendDefinedShortData passes a message over the air and decodes it.
+func sendDefinedShortData(t *testing.T, msg *layer2.DefinedShortData, dt elements.DataType) *layer2.DefinedShortData {
+	t.Helper()
+	bursts, err := msg.Bursts(enums.MsSourcedData, 1, dt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rx := overAir(t, bursts)
+	header := burstData[*pdu.DataHeader](t, rx[0])
+	if header.Format != pdu.FormatShortDataDefined || header.DefinedDataHeader == nil || int(header.AppendedBlocks) != len(rx)-1 {
+		t.Fatalf("header = %+v", header)
+	}
+	got, err := layer2.DecodeDefinedShortData(header.DefinedDataHeader, rx[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestDefinedShortData_Digits_RoundTrip(t *testing.T) {
+	t.Parallel()
+	for _, digits := range []string{"", "7", "0123456789", "31200019"} {
+		var msg layer2.DefinedShortData
+		if err := msg.SetDigits(digits); err != nil {
+			t.Fatal(err)
+		}
+		got := sendDefinedShortData(t, &msg, elements.DataTypeRate34)
+		if got.Format != enums.DDFormatBCD || got.BitLength != 4*len(digits) {
+			t.Fatalf("%q: received %s", digits, got.ToString())
+		}
+		if d, err := got.Digits(); err != nil || d != digits {
+			t.Errorf("Digits() = %q, %v, want %q", d, err, digits)
+		}
+	}
+
+	var msg layer2.DefinedShortData
+	if err := msg.SetDigits("0123"); err != nil || !bytes.Equal(msg.Data, []byte{0x01, 0x23}) {
+		t.Errorf("SetDigits(0123): % X, %v", msg.Data, err)
+	}
+}
+
+func TestDefinedShortData_Binary_BitPadding(t *testing.T) {
+	t.Parallel()
+	for _, dt := range []elements.DataType{elements.DataTypeRate12, elements.DataTypeRate34, elements.DataTypeRate1} {
+		for _, bitLength := range []int{1, 13, 64, 95, 160, 500} {
+			data := bytes.Repeat([]byte{0xA5}, (bitLength+7)/8)
+			if rem := bitLength % 8; rem != 0 {
+				data[len(data)-1] &= 0xFF << (8 - rem)
+			}
+			msg := layer2.DefinedShortData{Format: enums.DDFormatBinary, Data: data, BitLength: bitLength}
+			bursts, err := msg.Bursts(enums.BsSourcedData, 2, dt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			blockBits := layer2.PacketBlockOctets(dt, false) * 8
+			padding := int(burstData[*pdu.DataHeader](t, &bursts[0]).DefinedDataHeader.BitPadding)
+			if (len(bursts)-1)*blockBits != bitLength+padding+32 || padding >= blockBits {
+				t.Errorf("%s, %d bits: %d blocks with %d bits of padding", elements.DataTypeToName(dt), bitLength, len(bursts)-1, padding)
+			}
+
+			got := sendDefinedShortData(t, &msg, dt)
+			if got.BitLength != bitLength || !bytes.Equal(got.Data, data) {
+				t.Errorf("%s, %d bits: received %s", elements.DataTypeToName(dt), bitLength, got.ToString())
+			}
+		}
+	}
+}
+
+func TestDefinedShortData_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encode", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name     string
+			format   enums.DDFormat
+			text     string
+			sentinel error
+		}{
+			{"7-bit non-ASCII", enums.DDFormat7BitCharacter, "é", elements.ErrInvalidEncoding},
+			{"ISO 8859-1 Cyrillic", enums.DDFormatISO8859_1, "Д", elements.ErrInvalidEncoding},
+			{"ISO 8859-15 currency sign", enums.DDFormatISO8859_15, "¤", elements.ErrInvalidEncoding},
+			{"ISO 8859-2 Cyrillic", enums.DDFormatISO8859_2, "Д", elements.ErrInvalidEncoding},
+			{"binary", enums.DDFormatBinary, "a", elements.ErrInvalidEncoding},
+			{"invalid UTF-8", enums.DDFormatUTF8, "\xff", elements.ErrInvalidEncoding},
+		}
+		for _, tt := range tests {
+			var msg layer2.DefinedShortData
+			if err := msg.SetText(tt.format, tt.text); !errors.Is(err, tt.sentinel) {
+				t.Errorf("%s: err = %v, want %v", tt.name, err, tt.sentinel)
+			}
+		}
+		var msg layer2.DefinedShortData
+		if err := msg.SetDigits("12a"); !errors.Is(err, elements.ErrInvalidEncoding) {
+			t.Errorf("SetDigits: err = %v", err)
+		}
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name     string
+			msg      layer2.DefinedShortData
+			sentinel error
+		}{
+			{"BCD digit out of range", layer2.DefinedShortData{Format: enums.DDFormatBCD, Data: []byte{0x1A}, BitLength: 8}, elements.ErrInvalidEncoding},
+			{"odd UTF-16", layer2.DefinedShortData{Format: enums.DDFormatUTF16BE, Data: []byte{0, 0x41, 0}, BitLength: 24}, elements.ErrInvalidEncoding},
+			{"UTF-32 surrogate", layer2.DefinedShortData{Format: enums.DDFormatUTF32BE, Data: []byte{0, 0, 0xD8, 0}, BitLength: 32}, elements.ErrInvalidEncoding},
+			{"ISO 8859-3 undefined octet", layer2.DefinedShortData{Format: enums.DDFormatISO8859_3, Data: []byte{0xA5}, BitLength: 8}, elements.ErrInvalidEncoding},
+			{"ISO 8859-11 undefined octet", layer2.DefinedShortData{Format: enums.DDFormatISO8859_11, Data: []byte{0xDB}, BitLength: 8}, elements.ErrInvalidEncoding},
+			{"short data", layer2.DefinedShortData{Format: enums.DDFormatUTF8, Data: []byte{'a'}, BitLength: 16}, elements.ErrInvalidLength},
+		}
+		for _, tt := range tests {
+			var err error
+			if tt.msg.Format == enums.DDFormatBCD {
+				_, err = tt.msg.Digits()
+			} else {
+				_, err = tt.msg.Text()
+			}
+			if !errors.Is(err, tt.sentinel) {
+				t.Errorf("%s: err = %v, want %v", tt.name, err, tt.sentinel)
+			}
+		}
+		bin := layer2.DefinedShortData{Format: enums.DDFormatBinary, Data: []byte{1}, BitLength: 8}
+		if _, err := bin.Digits(); !errors.Is(err, elements.ErrInvalidEncoding) {
+			t.Errorf("Digits of binary: err = %v", err)
+		}
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		t.Parallel()
+		msg := layer2.DefinedShortData{Format: enums.DDFormatBinary, Data: bytes.Repeat([]byte{0x55}, 16), BitLength: 128}
+		bursts, err := msg.Bursts(enums.MsSourcedData, 1, elements.DataTypeRate12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rx := overAir(t, bursts)
+		header := burstData[*pdu.DataHeader](t, rx[0]).DefinedDataHeader
+
+		_, err = layer2.DecodeDefinedShortData(header, rx[1:len(rx)-1])
+		testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "AppendedBlocks")
+
+		corrupt := *header
+		corrupt.BitPadding = 255
+		_, err = layer2.DecodeDefinedShortData(&corrupt, rx[1:])
+		testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "BitPadding")
+
+		burstData[*pdu.Rate12Data](t, rx[2]).Data[0] ^= 0x01
+		_, err = layer2.DecodeDefinedShortData(header, rx[1:])
+		testutil.AssertPDUError(t, err, elements.ErrCRCMismatch, elements.LayerPacket, "CRC")
+
+		big := layer2.DefinedShortData{Data: make([]byte, 64*12), BitLength: 64 * 12 * 8}
+		_, err = big.Bursts(enums.MsSourcedData, 1, elements.DataTypeRate12)
+		testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "Data")
+	})
+}
@@ -0,0 +1,74 @@
+package elements
+
+import "errors"
+
+// Sentinel errors reported by the layer 2 decode and encode paths. The
+// concrete error returned is normally a *PDUError wrapping one of these, so
+// callers should test for them with errors.Is.
+var (
+	// ErrUncorrectableFEC reports that a FEC-protected field (BPTC, Trellis,
+	// Golay, ...) contained more errors than the code can correct.
+	ErrUncorrectableFEC = errors.New("uncorrectable FEC")
+	// ErrCRCMismatch reports that a PDU's integrity check, its CRC or the
+	// Reed-Solomon checksum of a full LC, did not match its contents.
+	ErrCRCMismatch = errors.New("CRC mismatch")
+	// ErrReservedDataType reports a slot type carrying a reserved data type
+	// (ETSI TS 102 361-1 Table 6.1, values 1100 to 1111).
+	ErrReservedDataType = errors.New("reserved data type")
+	// ErrUnknownOpcode reports a standardized (FID 0) CSBK opcode or FLCO
+	// that this package does not define.
+	ErrUnknownOpcode = errors.New("unknown opcode")
+	// ErrNotImplemented reports a PDU that is recognised but not yet parsed.
+	ErrNotImplemented = errors.New("not implemented")
+	// ErrDataTypeMismatch reports a burst whose Data PDU cannot be carried
+	// by its slot type's data type.
+	ErrDataTypeMismatch = errors.New("data type mismatch")
+	// ErrMissingBlocks reports a packet data message that was abandoned
+	// before all of its data blocks were received.
+	ErrMissingBlocks = errors.New("missing data blocks")
+	// ErrFragmentSequence reports a packet data fragment that does not
+	// follow on from the fragments received before it.
+	ErrFragmentSequence = errors.New("fragment out of sequence")
+	// ErrInvalidLength reports a length or count field that is inconsistent
+	// with the data it describes.
+	ErrInvalidLength = errors.New("invalid length")
+	// ErrNACK reports a confirmed data packet rejected by its receiver.
+	ErrNACK = errors.New("packet rejected")
+	// ErrRetryLimit reports a confirmed data packet that was not
+	// acknowledged within the retry limit.
+	ErrRetryLimit = errors.New("retry limit reached")
+	// ErrInvalidEncoding reports user data that is not valid in its
+	// declared format, or data that the format cannot represent.
+	ErrInvalidEncoding = errors.New("invalid encoding")
+)
+
+// Layers reported in PDUError.Layer.
+const (
+	// LayerBurst identifies the burst framing (SYNC, slot type, payload FEC).
+	LayerBurst = "layer2"
+	// LayerPDU identifies the PDU carried in the burst payload.
+	LayerPDU = "layer2/pdu"
+	// LayerPacket identifies packet data reassembly across bursts.
+	LayerPacket = "layer2/packet"
+	// LayerApplication identifies an application protocol carried in
+	// packet data, such as text messaging.
+	LayerApplication = "application"
+)
+
+// PDUError describes a decode or encode failure at a specific layer and
+// field. Use errors.As to inspect it and errors.Is to match the wrapped
+// sentinel.
+type PDUError struct {
+	Layer string
+	Field string
+	Err   error
+}
+
+func (e *PDUError) Error() string {
+	return e.Layer + ": " + e.Field + ": " + e.Err.Error()
+}
+
+// Unwrap returns the wrapped sentinel error.
+func (e *PDUError) Unwrap() error {
+	return e.Err
+}
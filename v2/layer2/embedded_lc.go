@@ -0,0 +1,209 @@
+package layer2
+
+import (
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/crc"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/fec"
+	"github.com/USA-RedDragon/dmrgo/v2/fec/bptc"
+	"github.com/USA-RedDragon/dmrgo/v2/fec/reed_solomon"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// ETSI TS 102 361-1 §7.1.3 — Embedded signalling
+//
+// A Full LC is carried in the embedded signalling of voice bursts B–E. The
+// 72 LC bits and a 5-bit checksum (§B.3.11) are placed in the 77 information
+// bits of the embedded BPTC (§B.2.1): rows 0–1 hold 11 LC bits each, and
+// rows 2–6 hold 10 LC bits followed by one checksum bit, MSB first. The
+// 128 coded bits are split into four 32-bit fragments, one per burst, with
+// the EMB LCSS marking the first, continuation and last fragments.
+//
+// The embedded LC has no Reed-Solomon parity; the checksum is its only
+// integrity check beyond the BPTC.
+
+// EmbeddedLCFragments is the number of voice bursts that carry one
+// embedded LC.
+const EmbeddedLCFragments = 4
+
+// DecodeEmbeddedLCFromFragments decodes a Full LC from the 32-bit embedded
+// signalling data of voice bursts B–E, in that order. It applies the
+// embedded BPTC (§B.2.1) followed by the 5-bit checksum (§B.3.11).
+//
+// Returns the decoded Full LC and a combined FEC result covering both the
+// BPTC and the checksum. The Full LC's DataType is left unset, as the
+// embedded LC is not carried in a data burst.
+func DecodeEmbeddedLCFromFragments(fragments [EmbeddedLCFragments][32]bit.Bit) (pdu.FullLinkControl, fec.FECResult) {
+	info, bptcResult := bptc.DecodeEmbeddedLC(fragments)
+
+	var lcBits [72]bit.Bit
+	var checksum uint8
+	idx := 0
+	for row := 0; row < 7; row++ {
+		for col := 0; col < 11; col++ {
+			b := info[row*11+col]
+			if row >= 2 && col == 10 {
+				checksum = checksum<<1 | uint8(b)
+				continue
+			}
+			lcBits[idx] = b
+			idx++
+		}
+	}
+
+	var lcBytes [9]byte
+	copy(lcBytes[:], bit.PackBits(lcBits[:]))
+	checksumResult := fec.FECResult{
+		BitsChecked:   len(lcBits),
+		Uncorrectable: !crc.CheckChecksum5(lcBytes, checksum),
+	}
+
+	flc, _ := pdu.DecodeFullLinkControl(fullLCWithParity(lcBytes))
+
+	combined := fec.FECResult{
+		BitsChecked:     bptcResult.BitsChecked + checksumResult.BitsChecked,
+		ErrorsCorrected: bptcResult.ErrorsCorrected,
+		Uncorrectable:   bptcResult.Uncorrectable || checksumResult.Uncorrectable,
+	}
+	flc.FEC = combined
+
+	return flc, combined
+}
+
+// EncodeEmbeddedLCToFragments encodes a Full LC into the 32-bit embedded
+// signalling data of voice bursts B–E, in that order. It computes the 5-bit
+// checksum (§B.3.11) over the 72 LC bits and applies the embedded BPTC
+// (§B.2.1).
+func EncodeEmbeddedLCToFragments(flc *pdu.FullLinkControl) [EmbeddedLCFragments][32]bit.Bit {
+	full := pdu.EncodeFullLinkControl(flc)
+	var lcBytes [9]byte
+	copy(lcBytes[:], bit.PackBits(full[:72]))
+	checksum := crc.CalculateChecksum5(lcBytes)
+
+	var info [77]bit.Bit
+	idx := 0
+	checksumBit := 4
+	for row := 0; row < 7; row++ {
+		for col := 0; col < 11; col++ {
+			if row >= 2 && col == 10 {
+				info[row*11+col] = bit.Bit((checksum >> checksumBit) & 1)
+				checksumBit--
+				continue
+			}
+			info[row*11+col] = full[idx]
+			idx++
+		}
+	}
+
+	return bptc.EncodeEmbeddedLC(info)
+}
+
+// fullLCWithParity returns the 96-bit Full LC for the given LC octets with
+// unmasked Reed-Solomon parity, so it can be handed to
+// pdu.DecodeFullLinkControl.
+func fullLCWithParity(lcBytes [9]byte) [96]bit.Bit {
+	var out [96]bit.Bit
+	codeword, _ := reedsolomon.Encode(lcBytes[:]) // only fails on a wrong input length
+	for i := range out {
+		out[i] = bit.Bit((codeword[i/8] >> (7 - i%8)) & 1)
+	}
+	return out
+}
+
+// EmbeddedLCAssembler collects the embedded LC fragments from the voice
+// bursts of a superframe, following the EMB LCSS of each burst, and decodes
+// the Full LC once the last fragment arrives.
+//
+// Bursts that carry an RC or a null embedded LC (LCSS single fragment) are
+// skipped. A burst that breaks the first/continuation/last sequence, a voice
+// SYNC or data burst in the middle of a sequence, or an EMB that could not
+// be decoded discards the partial sequence.
+type EmbeddedLCAssembler struct {
+	fragments [EmbeddedLCFragments][32]bit.Bit
+	count     int
+	discarded int
+}
+
+// Reset clears the assembler state for reuse.
+func (a *EmbeddedLCAssembler) Reset() {
+	a.count = 0
+	a.discarded = 0
+}
+
+// Count returns the number of fragments accumulated so far.
+func (a *EmbeddedLCAssembler) Count() int {
+	return a.count
+}
+
+// Discarded returns the number of partial sequences dropped since the last
+// Reset.
+func (a *EmbeddedLCAssembler) Discarded() int {
+	return a.discarded
+}
+
+// AddBurst feeds the next burst received on the slot to the assembler.
+// Returns true when all four fragments of an embedded LC have been
+// collected and the assembler is ready for Complete().
+func (a *EmbeddedLCAssembler) AddBurst(b *Burst) bool {
+	switch {
+	case b.IsData || isVoiceSync(b.SyncPattern):
+		a.discard()
+		return false
+	case !b.HasEmbeddedSignalling || b.HasReverseChannel || b.SyncPattern == enums.MsSourcedRcSync:
+		return false
+	case b.EmbeddedSignalling.FEC.Uncorrectable:
+		a.discard()
+		return false
+	}
+	return a.AddFragment(b.EmbeddedSignalling.LCSS, b.EmbeddedSignallingData)
+}
+
+// AddFragment appends a 32-bit embedded signalling payload with the LCSS
+// from its EMB. A first fragment always starts a new sequence; a
+// continuation or last fragment that does not follow on from the fragments
+// collected so far discards them. Single fragments (RC or null embedded LC)
+// are ignored. Returns true when the sequence is complete and ready for
+// Complete().
+func (a *EmbeddedLCAssembler) AddFragment(lcss enums.LCSS, payload [32]bit.Bit) bool {
+	if a.count >= EmbeddedLCFragments {
+		// Already full — caller should have called Complete() or Reset()
+		return true
+	}
+
+	switch lcss {
+	case enums.SingleFragmentLCorCSBK:
+		return false
+	case enums.FirstFragmentLC:
+		a.discard()
+	case enums.ContinuationFragmentLCorCSBK:
+		if a.count == 0 || a.count == EmbeddedLCFragments-1 {
+			a.discard()
+			return false
+		}
+	case enums.LastFragmentLCorCSBK:
+		if a.count != EmbeddedLCFragments-1 {
+			a.discard()
+			return false
+		}
+	}
+
+	a.fragments[a.count] = payload
+	a.count++
+	return a.count == EmbeddedLCFragments
+}
+
+// Complete decodes the Full LC from the 4 accumulated fragments and clears
+// them, ready for the next superframe. Returns the decoded Full LC and a
+// combined FEC result covering both the BPTC and the checksum.
+func (a *EmbeddedLCAssembler) Complete() (pdu.FullLinkControl, fec.FECResult) {
+	a.count = 0
+	return DecodeEmbeddedLCFromFragments(a.fragments)
+}
+
+// discard drops any partially collected sequence.
+func (a *EmbeddedLCAssembler) discard() {
+	if a.count > 0 {
+		a.discarded++
+	}
+	a.count = 0
+}
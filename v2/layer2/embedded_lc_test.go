@@ -0,0 +1,240 @@
+package layer2_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/crc"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/fec/bptc"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// buildEmbeddedLCFragments lays out the 72 LC bits of flc and their 5-bit
+// checksum in the embedded BPTC and returns the four coded fragments.
+func buildEmbeddedLCFragments(flc *pdu.FullLinkControl) [4][32]bit.Bit {
+	full := pdu.EncodeFullLinkControl(flc)
+	var lcBytes [9]byte
+	copy(lcBytes[:], bit.PackBits(full[:72]))
+	cs := crc.CalculateChecksum5(lcBytes)
+
+	var info [77]bit.Bit
+	lcIdx, csIdx := 0, 0
+	for row := 0; row < 7; row++ {
+		for col := 0; col < 11; col++ {
+			if row >= 2 && col == 10 {
+				info[row*11+col] = bit.Bit((cs >> (4 - csIdx)) & 1)
+				csIdx++
+				continue
+			}
+			info[row*11+col] = full[lcIdx]
+			lcIdx++
+		}
+	}
+	return bptc.EncodeEmbeddedLC(info)
+}
+
+func embeddedBurst(lcss enums.LCSS, payload [32]bit.Bit) *layer2.Burst {
+	return &layer2.Burst{
+		SyncPattern:            enums.EmbeddedSignallingPattern,
+		HasEmbeddedSignalling:  true,
+		EmbeddedSignalling:     pdu.EmbeddedSignalling{LCSS: lcss},
+		EmbeddedSignallingData: payload,
+	}
+}
+
+// embeddedLCSequence returns the LCSS of bursts B–E.
+func embeddedLCSequence() [4]enums.LCSS {
+	return [4]enums.LCSS{
+		enums.FirstFragmentLC,
+		enums.ContinuationFragmentLCorCSBK,
+		enums.ContinuationFragmentLCorCSBK,
+		enums.LastFragmentLCorCSBK,
+	}
+}
+
+func testGroupVoiceFLC() *pdu.FullLinkControl {
+	return &pdu.FullLinkControl{
+		FLCO:       enums.FLCOGroupVoiceChannelUser,
+		GroupVoice: &pdu.FLCGroupVoice{GroupAddress: 91, SourceAddress: 3120001},
+	}
+}
+
+func TestEmbeddedLC_CapturedVoiceMatchesHeader(t *testing.T) {
+	t.Parallel()
+	header := firstDataBurst(t, "testdata/voice.bin", elements.DataTypeVoiceLCHeader)
+	headerLC, ok := header.Data.(*pdu.FullLinkControl)
+	if !ok || headerLC.UnitToUnit == nil {
+		t.Fatalf("voice LC header Data = %s, want a unit-to-unit FLC", header.Data.ToString())
+	}
+
+	var a layer2.EmbeddedLCAssembler
+	decoded := 0
+	for i, raw := range loadBursts(t, "testdata/voice.bin") {
+		burst, err := layer2.NewBurstFromBytes(raw)
+		if err != nil {
+			t.Fatalf("burst %d: %v", i, err)
+		}
+		if !a.AddBurst(burst) {
+			continue
+		}
+		flc, result := a.Complete()
+		decoded++
+		if result.Uncorrectable {
+			t.Errorf("burst %d: embedded LC uncorrectable: %+v", i, result)
+			continue
+		}
+		if flc.FLCO != enums.FLCOUnitToUnitVoiceChannelUser || flc.UnitToUnit == nil {
+			t.Fatalf("burst %d: FLCO = %s, want unit-to-unit", i, enums.FLCOToName(flc.FLCO))
+		}
+		if flc.UnitToUnit.SourceAddress != headerLC.UnitToUnit.SourceAddress ||
+			flc.UnitToUnit.TargetAddress != headerLC.UnitToUnit.TargetAddress {
+			t.Errorf("burst %d: embedded LC %s does not match header %s", i, flc.ToString(), headerLC.ToString())
+		}
+	}
+	if decoded == 0 {
+		t.Fatal("no embedded LC decoded from testdata/voice.bin")
+	}
+}
+
+func TestEmbeddedLC_DecodeFromFragments_RoundTrip(t *testing.T) {
+	t.Parallel()
+	fragments := buildEmbeddedLCFragments(testGroupVoiceFLC())
+
+	flc, result := layer2.DecodeEmbeddedLCFromFragments(fragments)
+	if result.Uncorrectable || result.ErrorsCorrected != 0 {
+		t.Fatalf("FEC = %+v, want clean", result)
+	}
+	if flc.FEC != result {
+		t.Errorf("flc.FEC = %+v, want %+v", flc.FEC, result)
+	}
+	if flc.GroupVoice == nil || flc.GroupVoice.GroupAddress != 91 || flc.GroupVoice.SourceAddress != 3120001 {
+		t.Errorf("decoded %s", flc.ToString())
+	}
+}
+
+func TestEmbeddedLC_DecodeFromFragments_CorrectsBitError(t *testing.T) {
+	t.Parallel()
+	fragments := buildEmbeddedLCFragments(testGroupVoiceFLC())
+	fragments[2][5] ^= 1
+
+	flc, result := layer2.DecodeEmbeddedLCFromFragments(fragments)
+	if result.Uncorrectable || result.ErrorsCorrected == 0 {
+		t.Fatalf("FEC = %+v, want one corrected error", result)
+	}
+	if flc.GroupVoice == nil || flc.GroupVoice.SourceAddress != 3120001 {
+		t.Errorf("decoded %s", flc.ToString())
+	}
+}
+
+func TestEmbeddedLC_DecodeFromFragments_ChecksumMismatch(t *testing.T) {
+	t.Parallel()
+	// A valid BPTC codeword whose checksum bits (all zero) do not match the
+	// LC: the first octet is 0x80, whose checksum is 0x80 % 31 = 4.
+	var info [77]bit.Bit
+	info[0] = 1
+	fragments := bptc.EncodeEmbeddedLC(info)
+
+	_, result := layer2.DecodeEmbeddedLCFromFragments(fragments)
+	if !result.Uncorrectable {
+		t.Errorf("FEC = %+v, want checksum failure", result)
+	}
+}
+
+func TestEmbeddedLCAssembler_SkipsSingleFragments(t *testing.T) {
+	t.Parallel()
+	fragments := buildEmbeddedLCFragments(testGroupVoiceFLC())
+
+	var a layer2.EmbeddedLCAssembler
+	nullLC := embeddedBurst(enums.SingleFragmentLCorCSBK, layer2.NullEmbeddedLCBits)
+	rc := embeddedBurst(enums.SingleFragmentLCorCSBK, layer2.EncodeRCToEmbeddedData(&pdu.ReverseChannel{}))
+	rc.EmbeddedSignalling.PreemptionAndPowerControlIndicator = true
+	rc.HasReverseChannel = true
+
+	bursts := []*layer2.Burst{
+		nullLC,
+		embeddedBurst(embeddedLCSequence()[0], fragments[0]),
+		rc,
+		embeddedBurst(embeddedLCSequence()[1], fragments[1]),
+		embeddedBurst(embeddedLCSequence()[2], fragments[2]),
+		nullLC,
+	}
+	for i, b := range bursts {
+		if a.AddBurst(b) {
+			t.Fatalf("burst %d: complete too early", i)
+		}
+	}
+	if a.Count() != 3 || a.Discarded() != 0 {
+		t.Fatalf("Count = %d, Discarded = %d, want 3 and 0", a.Count(), a.Discarded())
+	}
+	if !a.AddBurst(embeddedBurst(embeddedLCSequence()[3], fragments[3])) {
+		t.Fatal("not complete after the last fragment")
+	}
+	flc, result := a.Complete()
+	if result.Uncorrectable || flc.GroupVoice == nil || flc.GroupVoice.GroupAddress != 91 {
+		t.Errorf("decoded %s, FEC %+v", flc.ToString(), result)
+	}
+	if a.Count() != 0 {
+		t.Errorf("Count = %d after Complete, want 0", a.Count())
+	}
+}
+
+func TestEmbeddedLCAssembler_DiscardsOnGap(t *testing.T) {
+	t.Parallel()
+	fragments := buildEmbeddedLCFragments(testGroupVoiceFLC())
+	first := embeddedBurst(enums.FirstFragmentLC, fragments[0])
+	cont := embeddedBurst(enums.ContinuationFragmentLCorCSBK, fragments[1])
+	last := embeddedBurst(enums.LastFragmentLCorCSBK, fragments[3])
+	badEMB := embeddedBurst(enums.ContinuationFragmentLCorCSBK, fragments[2])
+	badEMB.EmbeddedSignalling.FEC.Uncorrectable = true
+
+	tests := []struct {
+		name   string
+		bursts []*layer2.Burst
+	}{
+		{"missing continuation", []*layer2.Burst{first, cont, last}},
+		{"extra continuation", []*layer2.Burst{first, cont, cont, cont}},
+		{"restart on first", []*layer2.Burst{first, cont, first}},
+		{"uncorrectable EMB", []*layer2.Burst{first, cont, badEMB}},
+		{"data burst", []*layer2.Burst{first, {IsData: true}}},
+		{"voice sync", []*layer2.Burst{first, cont, {SyncPattern: enums.BsSourcedVoice}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var a layer2.EmbeddedLCAssembler
+			for i, b := range tt.bursts {
+				if a.AddBurst(b) {
+					t.Fatalf("burst %d: unexpectedly complete", i)
+				}
+			}
+			if a.Discarded() != 1 {
+				t.Errorf("Discarded = %d, want 1", a.Discarded())
+			}
+		})
+	}
+}
+
+func TestEmbeddedLCAssembler_ContinuationWithoutFirstIgnored(t *testing.T) {
+	t.Parallel()
+	fragments := buildEmbeddedLCFragments(testGroupVoiceFLC())
+
+	// Joining a call after burst B: nothing to discard until the next B.
+	var a layer2.EmbeddedLCAssembler
+	a.AddFragment(enums.ContinuationFragmentLCorCSBK, fragments[1])
+	a.AddFragment(enums.LastFragmentLCorCSBK, fragments[3])
+	if a.Count() != 0 || a.Discarded() != 0 {
+		t.Fatalf("Count = %d, Discarded = %d, want 0 and 0", a.Count(), a.Discarded())
+	}
+	for i, lcss := range embeddedLCSequence() {
+		ready := a.AddFragment(lcss, fragments[i])
+		if ready != (i == 3) {
+			t.Fatalf("fragment %d: ready = %v", i, ready)
+		}
+	}
+	if _, result := a.Complete(); result.Uncorrectable {
+		t.Errorf("FEC = %+v", result)
+	}
+}
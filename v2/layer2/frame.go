@@ -0,0 +1,96 @@
+package layer2
+
+import (
+	"fmt"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+)
+
+// ETSI TS 102 361-1 §6.3 — Common Announcement Channel burst
+//
+// On the BS outbound channel each 30 ms frame is a 24-bit CACH followed by a
+// 264-bit burst (Figure 6.6). The CACH is sent first on air, and its TACT TC
+// bit identifies the TDMA channel of the burst that follows it.
+//
+// The CACH transmit order puts TX(23) on air first (see CACHDeinterleave),
+// so the first 24 bits of a frame are stored into the transmit array in
+// reverse.
+
+const (
+	// FrameBits is the total number of bits in a BS outbound frame.
+	FrameBits = CACHBits + BurstBits
+	// FrameBytes is the length of a BS outbound frame in bytes.
+	FrameBytes = FrameBits / 8
+)
+
+// Frame represents a decoded BS outbound frame: the CACH and the burst that
+// follows it.
+type Frame struct {
+	CACH  CACH
+	Burst Burst
+}
+
+// NewFrameFromBytes creates a new Frame from a 36-byte BS outbound frame.
+func NewFrameFromBytes(data [FrameBytes]byte) (*Frame, error) {
+	frame := &Frame{}
+	err := frame.DecodeFromBytes(data)
+	return frame, err
+}
+
+// DecodeFromBytes populates the frame in place. The burst keeps its
+// trunking mode and SYNC bit error threshold, as for Burst.DecodeFromBytes.
+// The CACH is decoded even when the burst returns an error.
+func (f *Frame) DecodeFromBytes(data [FrameBytes]byte) error {
+	bits := bit.UnpackBits(data[:3])
+	var txBits [CACHBits]bit.Bit
+	for i := range txBits {
+		txBits[CACHBits-1-i] = bits[i]
+	}
+	f.CACH = DecodeCACH(CACHDeinterleave(txBits))
+
+	var burstBytes [33]byte
+	copy(burstBytes[:], data[3:])
+	return f.Burst.DecodeFromBytes(burstBytes)
+}
+
+// Timeslot returns the TDMA channel (1 or 2) of the frame's burst, from the
+// CACH TACT TC bit.
+func (f *Frame) Timeslot() int {
+	if f.CACH.TACT.TDMAChannel {
+		return 2
+	}
+	return 1
+}
+
+// Encode returns the encoded bytes of the frame. It fails if the burst
+// cannot be encoded; see Burst.Encode.
+func (f *Frame) Encode() ([FrameBytes]byte, error) {
+	var out [FrameBytes]byte
+
+	burstBytes, err := f.Burst.Encode()
+	if err != nil {
+		return out, err
+	}
+
+	bits := cachAirBits(&f.CACH)
+	copy(out[:3], bit.PackBits(bits[:]))
+	copy(out[3:], burstBytes[:])
+	return out, nil
+}
+
+// cachAirBits returns the 24 bits of a CACH in the order they are sent on
+// air, first bit first.
+func cachAirBits(c *CACH) [CACHBits]bit.Bit {
+	txBits := CACHInterleave(EncodeCACH(c))
+	var bits [CACHBits]bit.Bit
+	for i := range bits {
+		bits[i] = txBits[CACHBits-1-i]
+	}
+	return bits
+}
+
+// ToString returns a string representation of the frame.
+func (f *Frame) ToString() string {
+	return fmt.Sprintf("{ Timeslot: %d, CACH: { TACT: %s, Payload: %v }, Burst: %s }",
+		f.Timeslot(), f.CACH.TACT.ToString(), f.CACH.Payload, f.Burst.ToString())
+}
@@ -0,0 +1,111 @@
+package layer2_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+func buildFrame(t *testing.T, cach layer2.CACH, burst [33]byte) [layer2.FrameBytes]byte {
+	t.Helper()
+	decoded, err := layer2.NewBurstFromBytes(burst)
+	if err != nil {
+		t.Fatalf("NewBurstFromBytes: %v", err)
+	}
+	frame := layer2.Frame{CACH: cach, Burst: *decoded}
+	encoded, err := frame.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	return encoded
+}
+
+func TestFrame_EncodeDecodeRoundTrip(t *testing.T) {
+	t.Parallel()
+	raw := loadBursts(t, "testdata/voice.bin")[0]
+	var payload [layer2.CACHPayloadBits]bit.Bit
+	payload[0], payload[16] = 1, 1
+
+	for _, tc := range []bool{false, true} {
+		cach := layer2.CACH{
+			TACT:    pdu.TACT{AccessType: true, TDMAChannel: tc, LCSS: enums.FirstFragmentLC},
+			Payload: payload,
+		}
+		data := buildFrame(t, cach, raw)
+		if [33]byte(data[3:]) != raw {
+			t.Errorf("TC=%t: burst bytes not carried through", tc)
+		}
+
+		frame, err := layer2.NewFrameFromBytes(data)
+		if err != nil {
+			t.Fatalf("TC=%t: NewFrameFromBytes: %v", tc, err)
+		}
+		got := frame.CACH.TACT
+		if !got.AccessType || got.TDMAChannel != tc || got.LCSS != enums.FirstFragmentLC {
+			t.Errorf("TC=%t: TACT = %s", tc, got.ToString())
+		}
+		if frame.CACH.Payload != payload {
+			t.Errorf("TC=%t: Payload = %v, want %v", tc, frame.CACH.Payload, payload)
+		}
+		wantSlot := 1
+		if tc {
+			wantSlot = 2
+		}
+		if frame.Timeslot() != wantSlot {
+			t.Errorf("TC=%t: Timeslot = %d, want %d", tc, frame.Timeslot(), wantSlot)
+		}
+		if frame.Burst.SlotType.DataType != elements.DataTypeVoiceLCHeader {
+			t.Errorf("TC=%t: burst = %s", tc, frame.Burst.ToString())
+		}
+	}
+}
+
+func TestFrame_CACHAirOrder(t *testing.T) {
+	t.Parallel()
+	// Figure B.9: AT is the first bit on air and TC the fifth.
+	raw := loadBursts(t, "testdata/voice.bin")[0]
+	data := buildFrame(t, layer2.CACH{TACT: pdu.TACT{AccessType: true}}, raw)
+	if data[0]&0x80 == 0 {
+		t.Errorf("AT not first on air: %08b", data[0])
+	}
+
+	var payload [layer2.CACHPayloadBits]bit.Bit
+	payload[0] = 1 // P(16)
+	data = buildFrame(t, layer2.CACH{Payload: payload}, raw)
+	if data[0]&0x40 == 0 {
+		t.Errorf("P(16) not second on air: %08b", data[0])
+	}
+}
+
+func TestFrame_CorrectsTACTError(t *testing.T) {
+	t.Parallel()
+	raw := loadBursts(t, "testdata/voice.bin")[0]
+	data := buildFrame(t, layer2.CACH{TACT: pdu.TACT{TDMAChannel: true}}, raw)
+	data[0] ^= 0x08 // TC, the fifth bit on air
+
+	frame, err := layer2.NewFrameFromBytes(data)
+	if err != nil {
+		t.Fatalf("NewFrameFromBytes: %v", err)
+	}
+	if frame.Timeslot() != 2 || frame.CACH.FEC.ErrorsCorrected != 1 {
+		t.Errorf("Timeslot = %d, FEC = %+v, want 2 with one corrected error", frame.Timeslot(), frame.CACH.FEC)
+	}
+}
+
+func TestFrame_DecodesCACHOnBurstError(t *testing.T) {
+	t.Parallel()
+	data := buildFrame(t, layer2.CACH{TACT: pdu.TACT{AccessType: true}}, loadBursts(t, "testdata/voice.bin")[0])
+	reserved := layer2.BuildLCDataBurst([12]byte{}, elements.DataTypeReserved, 1)
+	copy(data[3:], reserved[:])
+
+	frame, err := layer2.NewFrameFromBytes(data)
+	testutil.AssertPDUError(t, err, elements.ErrReservedDataType, elements.LayerBurst, "SlotType.DataType")
+	if !frame.CACH.TACT.AccessType {
+		t.Errorf("TACT = %s, want AT set", frame.CACH.TACT.ToString())
+	}
+}
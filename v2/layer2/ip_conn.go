@@ -0,0 +1,488 @@
+package layer2
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// defaultIPQueueLength is the number of received datagrams an IPConn holds
+// for Read when none is configured.
+const defaultIPQueueLength = 64
+
+// RadioAddr is the DMR address of a radio or talkgroup. It implements
+// net.Addr.
+type RadioAddr struct {
+	LLID  int
+	Group bool
+}
+
+// Network returns "dmr".
+func (a RadioAddr) Network() string {
+	return "dmr"
+}
+
+// String returns the LLID, prefixed with "group/" for a talkgroup.
+func (a RadioAddr) String() string {
+	if a.Group {
+		return fmt.Sprintf("group/%d", a.LLID)
+	}
+	return fmt.Sprintf("%d", a.LLID)
+}
+
+// ipDatagram is a received datagram waiting for Read.
+type ipDatagram struct {
+	from     RadioAddr
+	datagram []byte
+}
+
+// IPConn carries IPv4 datagrams over DMR packet data on a single timeslot.
+// It implements net.PacketConn and io.ReadWriter, so it can be attached to
+// a TUN device; the bursts are exchanged with the radio through Transmit
+// and AddBurst. Set the fields before first use; the zero value with a
+// Transmit function is ready to use, and its methods may be called from
+// several goroutines.
+//
+// Datagrams are sent as unconfirmed packets with SAP IP based packet data,
+// or with UDP/IP or TCP/IP header compression when CompressUDP or
+// CompressTCP is set and both addresses are on the networks of Addresses.
+// Received unconfirmed and confirmed messages with any of those SAPs are
+// returned by Read; confirmed packets addressed to LLID are answered
+// through Transmit. With an ARP resolver, ARP messages are answered too,
+// and addresses outside the networks of Addresses are resolved with ARP.
+type IPConn struct {
+	// SyncPattern and ColorCode are set on every burst sent.
+	SyncPattern enums.SyncPattern
+	ColorCode   int
+	// DataType is the rate of the data blocks sent: elements.DataTypeRate12,
+	// DataTypeRate34 or DataTypeRate1. Any other value uses Rate 1/2.
+	DataType elements.DataType
+	// Preambles is the number of CSBK preambles sent before each packet.
+	Preambles int
+	// LLID is the station's own LLID, sent as the source of uncompressed
+	// datagrams. When non-zero, only individual messages addressed to it
+	// are received.
+	LLID int
+	// Addresses maps IPv4 addresses to LLIDs, and rebuilds the addresses
+	// of compressed headers.
+	Addresses IPv4AddressContext
+	// Routes maps destination addresses to the radio or talkgroup that
+	// reaches them, ahead of Addresses. Datagrams to a routed address are
+	// sent uncompressed.
+	Routes map[netip.Addr]RadioAddr
+	// ARP, when set, answers ARP requests and resolves destinations on
+	// none of the networks of Addresses.
+	ARP *ARPResolver
+	// CompressUDP and CompressTCP select UDP/IP and TCP/IP header
+	// compression.
+	CompressUDP bool
+	CompressTCP bool
+	// QueueLength is the number of received datagrams held for Read; the
+	// oldest is dropped when another arrives. Zero uses 64.
+	QueueLength int
+	// Transmit sends the bursts of one packet or response, in order. A nil
+	// Transmit discards them.
+	Transmit func([]Burst) error
+
+	mu          sync.Mutex
+	unconfirmed UnconfirmedDataAssembler
+	confirmed   ConfirmedDataReceiver
+	// compressors and decompressors hold the TCP/IP header compression
+	// state of each peer LLID.
+	compressors   map[int]*TCPIPCompressor
+	decompressors map[int]*TCPIPDecompressor
+	queue         []ipDatagram
+	// wake is closed and replaced to wake blocked readers.
+	wake          chan struct{}
+	closed        bool
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+// ReadFrom waits for the next datagram received and copies it into p,
+// returning the RadioAddr of its source. A datagram larger than p is
+// truncated, as on a network socket.
+func (c *IPConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	for {
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			return 0, nil, net.ErrClosed
+		}
+		if len(c.queue) > 0 {
+			d := c.queue[0]
+			c.queue = c.queue[1:]
+			c.mu.Unlock()
+			return copy(p, d.datagram), d.from, nil
+		}
+		wake := c.wakeChannel()
+		deadline := c.readDeadline
+		c.mu.Unlock()
+
+		if deadline.IsZero() {
+			<-wake
+			continue
+		}
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return 0, nil, os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// Read is ReadFrom without the source address.
+func (c *IPConn) Read(p []byte) (int, error) {
+	n, _, err := c.ReadFrom(p)
+	return n, err
+}
+
+// WriteTo sends an IPv4 datagram. A RadioAddr addr sends it uncompressed
+// to that radio or talkgroup; any other addr, including nil, routes it by
+// its destination address.
+//
+// Errors are those of Transmit, or an *elements.PDUError wrapping
+// elements.ErrInvalidEncoding for a datagram that is not IPv4 or a
+// destination with no route, or elements.ErrInvalidLength for a datagram
+// too large for one packet. A destination with no route is sent an ARP
+// request first when there is an ARP resolver; write the datagram again
+// once it has replied.
+func (c *IPConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	now := time.Now()
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return 0, net.ErrClosed
+	}
+	if !c.writeDeadline.IsZero() && !now.Before(c.writeDeadline) {
+		c.mu.Unlock()
+		return 0, os.ErrDeadlineExceeded
+	}
+	msg, unresolved, err := c.message(p, addr, now)
+	c.mu.Unlock()
+	if unresolved {
+		request, _ := c.bursts(c.ARP.Request(netip.AddrFrom4([4]byte(p[16:20]))))
+		if txErr := c.transmit(request); txErr != nil {
+			return 0, txErr
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	bursts, err := c.bursts(msg)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.transmit(bursts); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// bursts returns the bursts of an unconfirmed packet carrying a message.
+func (c *IPConn) bursts(msg *PacketData) ([]Burst, error) {
+	packet := DataPacket{
+		SyncPattern: c.SyncPattern,
+		ColorCode:   c.ColorCode,
+		Preambles:   c.Preambles,
+		SAP:         msg.SAP,
+		Source:      msg.Source,
+		Destination: msg.Destination,
+		Group:       msg.Group,
+		FullMessage: true,
+		DataType:    c.DataType,
+		Payload:     msg.Payload,
+	}
+	return packet.Bursts()
+}
+
+// Write is WriteTo routed by the datagram's destination address.
+func (c *IPConn) Write(p []byte) (int, error) {
+	return c.WriteTo(p, nil)
+}
+
+// message returns the message that carries a datagram to addr at time
+// now, or unresolved for a destination to resolve with ARP.
+func (c *IPConn) message(datagram []byte, addr net.Addr, now time.Time) (msg *PacketData, unresolved bool, err error) {
+	if len(datagram) < ipv4HeaderOctets || datagram[0]>>4 != ipv4Version {
+		return nil, false, &elements.PDUError{Layer: elements.LayerPacket, Field: "Version", Err: elements.ErrInvalidEncoding}
+	}
+	dst := netip.AddrFrom4([4]byte(datagram[16:20]))
+
+	to, ok := addr.(RadioAddr)
+	if a, isPtr := addr.(*RadioAddr); isPtr && a != nil {
+		to, ok = *a, true
+	}
+	if !ok {
+		to, ok = c.Routes[dst]
+	}
+	if !ok {
+		to, err = c.route(datagram)
+		if err != nil {
+			if c.ARP == nil {
+				return nil, false, err
+			}
+			llid, found := c.ARP.Lookup(dst, now)
+			if !found {
+				return nil, true, err
+			}
+			to = RadioAddr{LLID: llid}
+		} else {
+			// Compression needs both addresses on the context's networks;
+			// anything it cannot carry is sent uncompressed.
+			switch {
+			case c.CompressUDP && datagram[9] == ipv4ProtocolUDP:
+				if msg, err := c.Addresses.CompressUDPIPv4(datagram); err == nil {
+					return msg, false, nil
+				}
+			case c.CompressTCP && datagram[9] == ipv4ProtocolTCP:
+				if _, _, err := c.Addresses.SourceID(netip.AddrFrom4([4]byte(datagram[12:16]))); err == nil {
+					msg, err := c.compressor(to.LLID).Compress(datagram)
+					return msg, false, err
+				}
+			}
+		}
+	}
+	return &PacketData{
+		SAP:         pdu.ServiceAccessPointIDIPBasedPacketData,
+		Source:      c.LLID,
+		Destination: to.LLID,
+		Group:       to.Group,
+		Payload:     bytes.Clone(datagram),
+	}, false, nil
+}
+
+// route returns the radio or talkgroup of a datagram's destination on the
+// networks of Addresses.
+func (c *IPConn) route(datagram []byte) (RadioAddr, error) {
+	daid, llid, err := c.Addresses.DestinationID(netip.AddrFrom4([4]byte(datagram[16:20])))
+	if err != nil {
+		return RadioAddr{}, err
+	}
+	return RadioAddr{LLID: llid, Group: daid == enums.DAIDGroupNetwork}, nil
+}
+
+// compressor returns the TCP/IP header compressor for a peer.
+func (c *IPConn) compressor(llid int) *TCPIPCompressor {
+	if c.compressors == nil {
+		c.compressors = make(map[int]*TCPIPCompressor)
+	}
+	tc, ok := c.compressors[llid]
+	if !ok {
+		tc = &TCPIPCompressor{}
+		c.compressors[llid] = tc
+	}
+	tc.Addresses = c.Addresses
+	return tc
+}
+
+// decompressor returns the TCP/IP header decompressor for a peer.
+func (c *IPConn) decompressor(llid int) *TCPIPDecompressor {
+	if c.decompressors == nil {
+		c.decompressors = make(map[int]*TCPIPDecompressor)
+	}
+	td, ok := c.decompressors[llid]
+	if !ok {
+		td = &TCPIPDecompressor{}
+		c.decompressors[llid] = td
+	}
+	return td
+}
+
+// AddBurst feeds a burst received on the slot at time now. A datagram it
+// completes is queued for Read, and the response to a confirmed packet or
+// the reply to an ARP request is sent through Transmit.
+//
+// Errors report a message that was dropped, as for
+// UnconfirmedDataAssembler.AddBurst, DecompressUDPIPv4,
+// TCPIPDecompressor.Decompress and DecodeARP, or a Transmit failure. The connection
+// carries on with the next message after an error.
+func (c *IPConn) AddBurst(b *Burst, now time.Time) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return net.ErrClosed
+	}
+	msg, err := c.unconfirmed.AddBurst(b, now)
+	resp, confirmedMsg, confirmedErr := c.confirmed.AddBurst(b, now)
+	if err == nil {
+		err = confirmedErr
+	}
+	if err != nil {
+		c.toss()
+	}
+	var response []Burst
+	if resp != nil && (c.LLID == 0 || resp.Source == c.LLID) {
+		response = resp.Bursts(c.SyncPattern, c.ColorCode)
+	}
+	for _, m := range []*PacketData{msg, confirmedMsg} {
+		if m == nil {
+			continue
+		}
+		reply, receiveErr := c.receive(m, now)
+		if err == nil {
+			err = receiveErr
+		}
+		if reply != nil {
+			bursts, _ := c.bursts(reply)
+			response = append(response, bursts...)
+		}
+	}
+	c.mu.Unlock()
+
+	if response != nil {
+		if transmitErr := c.transmit(response); err == nil {
+			err = transmitErr
+		}
+	}
+	return err
+}
+
+// Expire drops the messages in progress that have timed out at time now,
+// as for UnconfirmedDataAssembler.Expire. Call it periodically when no
+// bursts are being received.
+func (c *IPConn) Expire(now time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	err := c.unconfirmed.Expire(now)
+	if confirmedErr := c.confirmed.Expire(now); err == nil {
+		err = confirmedErr
+	}
+	if err != nil {
+		c.toss()
+	}
+	return err
+}
+
+// toss reports a lost message to the TCP/IP header decompressors. The
+// message's source is unknown, so every peer tosses.
+func (c *IPConn) toss() {
+	for _, td := range c.decompressors {
+		td.Toss()
+	}
+}
+
+// receive queues the datagram carried by a message addressed to the
+// station at time now, and returns the reply to an ARP request.
+func (c *IPConn) receive(msg *PacketData, now time.Time) (*PacketData, error) {
+	if c.LLID != 0 && !msg.Group && msg.Destination != c.LLID {
+		return nil, nil
+	}
+	var datagram []byte
+	var err error
+	switch msg.SAP {
+	case pdu.ServiceAccessPointIDIPBasedPacketData:
+		if len(msg.Payload) < ipv4HeaderOctets || msg.Payload[0]>>4 != ipv4Version {
+			return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Version", Err: elements.ErrInvalidEncoding}
+		}
+		datagram = msg.Payload
+	case pdu.ServiceAccessPointIDUDPIPHeaderCompression:
+		datagram, err = c.Addresses.DecompressUDPIPv4(msg)
+	case pdu.ServiceAccessPointIDTCPIPHeaderCompression:
+		datagram, err = c.decompressor(msg.Source).Decompress(msg)
+	case pdu.ServiceAccessPointIDAddressResolutionProtocol:
+		if c.ARP != nil {
+			return c.ARP.Receive(msg, now)
+		}
+	}
+	if datagram == nil {
+		return nil, err
+	}
+
+	limit := c.QueueLength
+	if limit <= 0 {
+		limit = defaultIPQueueLength
+	}
+	if len(c.queue) >= limit {
+		c.queue = c.queue[len(c.queue)-limit+1:]
+	}
+	c.queue = append(c.queue, ipDatagram{from: RadioAddr{LLID: msg.Source}, datagram: datagram})
+	c.wakeReaders()
+	return nil, nil
+}
+
+// transmit passes bursts to Transmit.
+func (c *IPConn) transmit(bursts []Burst) error {
+	if c.Transmit == nil {
+		return nil
+	}
+	return c.Transmit(bursts)
+}
+
+// wakeChannel returns the channel closed on the next change to the queue,
+// the deadlines or Close.
+func (c *IPConn) wakeChannel() chan struct{} {
+	if c.wake == nil {
+		c.wake = make(chan struct{})
+	}
+	return c.wake
+}
+
+// wakeReaders wakes every blocked ReadFrom.
+func (c *IPConn) wakeReaders() {
+	if c.wake != nil {
+		close(c.wake)
+		c.wake = nil
+	}
+}
+
+// Close wakes blocked reads and makes further calls return net.ErrClosed.
+func (c *IPConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return net.ErrClosed
+	}
+	c.closed = true
+	c.queue = nil
+	c.wakeReaders()
+	return nil
+}
+
+// LocalAddr returns the RadioAddr of LLID.
+func (c *IPConn) LocalAddr() net.Addr {
+	return RadioAddr{LLID: c.LLID}
+}
+
+// SetDeadline sets the read and write deadlines.
+func (c *IPConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline, c.writeDeadline = t, t
+	c.wakeReaders()
+	return nil
+}
+
+// SetReadDeadline sets the time after which a blocked ReadFrom returns
+// os.ErrDeadlineExceeded. The zero time waits forever.
+func (c *IPConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	c.wakeReaders()
+	return nil
+}
+
+// SetWriteDeadline sets the time after which WriteTo returns
+// os.ErrDeadlineExceeded. Writes do not block, so it only fails writes
+// made after it has passed.
+func (c *IPConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeDeadline = t
+	return nil
+}
@@ -0,0 +1,272 @@
+package layer2_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// Both ends of an IPConn are usable as a TUN device's packet source and
+// sink.
+var (
+	_ net.PacketConn = (*layer2.IPConn)(nil)
+	_ io.ReadWriter  = (*layer2.IPConn)(nil)
+)
+
+// ipConnPair returns two connections whose bursts pass over the air to
+// each other, and records the data header SAP of each packet sent by a.
+func ipConnPair(t *testing.T, a, b *layer2.IPConn) *[]pdu.ServiceAccessPointID {
+	t.Helper()
+	var saps []pdu.ServiceAccessPointID
+	link := func(to *layer2.IPConn, record bool) func([]layer2.Burst) error {
+		return func(bursts []layer2.Burst) error {
+			for _, b := range overAir(t, bursts) {
+				if h, ok := b.Data.(*pdu.DataHeader); ok && record && h.UnconfirmedDataHeader != nil {
+					saps = append(saps, pdu.ServiceAccessPointID(h.UnconfirmedDataHeader.SAP))
+				}
+				if err := to.AddBurst(b, time.Now()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	for _, c := range []*layer2.IPConn{a, b} {
+		c.SyncPattern = enums.MsSourcedData
+		c.ColorCode = 1
+	}
+	a.Transmit = link(b, true)
+	b.Transmit = link(a, false)
+	return &saps
+}
+
+// readDatagram reads one datagram from c without blocking.
+func readDatagram(t *testing.T, c *layer2.IPConn) ([]byte, net.Addr) {
+	t.Helper()
+	if err := c.SetReadDeadline(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1500)
+	n, from, err := c.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	return buf[:n], from
+}
+
+func TestIPConn_RoundTrip(t *testing.T) {
+	t.Parallel()
+	// The UDP/IPv4 datagram of TestDecompressUDPIPv4_Vector, from LLID
+	// 3120001 to 3120002, and a TCP datagram between the same radios.
+	udp := []byte{
+		0x45, 0x00, 0x00, 0x1E, 0x12, 0x34, 0x00, 0x00, 0x40, 0x11, 0x19, 0x3A,
+		12, 0x2F, 0x9B, 0x81, 12, 0x2F, 0x9B, 0x82,
+		0x13, 0x98, 0x13, 0x98, 0x00, 0x0A, 0x20, 0xDF, 'h', 'i',
+	}
+	tcp := tcpDatagram(&tcpSegment{src: "12.47.155.129", dst: "12.47.155.130", srcPort: 1, dstPort: 2, seq: 1, ack: 1, flags: tcpACK, window: 512, data: "hello"})
+	// A datagram to an address outside the context, routed explicitly.
+	routed := tcpDatagram(&tcpSegment{src: "10.0.0.1", dst: "10.0.0.2", srcPort: 1, dstPort: 2, flags: tcpSYN})
+
+	tests := []struct {
+		name        string
+		compressUDP bool
+		compressTCP bool
+		datagram    []byte
+		sap         pdu.ServiceAccessPointID
+	}{
+		{"UDP", false, false, udp, pdu.ServiceAccessPointIDIPBasedPacketData},
+		{"compressed UDP", true, false, udp, pdu.ServiceAccessPointIDUDPIPHeaderCompression},
+		{"TCP", false, false, tcp, pdu.ServiceAccessPointIDIPBasedPacketData},
+		{"compressed TCP", false, true, tcp, pdu.ServiceAccessPointIDTCPIPHeaderCompression},
+		{"routed", true, true, routed, pdu.ServiceAccessPointIDIPBasedPacketData},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a := &layer2.IPConn{
+				LLID:        3120001,
+				CompressUDP: tt.compressUDP,
+				CompressTCP: tt.compressTCP,
+				Routes:      map[netip.Addr]layer2.RadioAddr{netip.MustParseAddr("10.0.0.2"): {LLID: 3120002}},
+			}
+			b := &layer2.IPConn{LLID: 3120002}
+			saps := ipConnPair(t, a, b)
+
+			n, err := a.Write(tt.datagram)
+			if err != nil || n != len(tt.datagram) {
+				t.Fatalf("Write = %d, %v", n, err)
+			}
+			got, from := readDatagram(t, b)
+			if !bytes.Equal(got, tt.datagram) {
+				t.Errorf("datagram:\n got % X\nwant % X", got, tt.datagram)
+			}
+			if from != (layer2.RadioAddr{LLID: 3120001}) {
+				t.Errorf("from = %v", from)
+			}
+			if len(*saps) != 1 || (*saps)[0] != tt.sap {
+				t.Errorf("SAPs = %v, want [%d]", *saps, tt.sap)
+			}
+		})
+	}
+}
+
+func TestIPConn_CompressedTCPSession(t *testing.T) {
+	t.Parallel()
+	a := &layer2.IPConn{CompressTCP: true}
+	b := &layer2.IPConn{}
+	ipConnPair(t, a, b)
+	datagrams, _ := tcpSession()
+	for i, datagram := range datagrams {
+		if _, err := a.Write(datagram); err != nil {
+			t.Fatalf("datagram %d: Write: %v", i, err)
+		}
+		if got, _ := readDatagram(t, b); !bytes.Equal(got, datagram) {
+			t.Fatalf("datagram %d:\n got % X\nwant % X", i, got, datagram)
+		}
+	}
+}
+
+func TestIPConn_Confirmed(t *testing.T) {
+	t.Parallel()
+	var responses []layer2.Burst
+	c := &layer2.IPConn{
+		SyncPattern: enums.BsSourcedData,
+		LLID:        3120002,
+		Transmit: func(bursts []layer2.Burst) error {
+			responses = append(responses, bursts...)
+			return nil
+		},
+	}
+	datagram := tcpDatagram(&tcpSegment{src: "12.0.0.1", dst: "12.0.0.2", flags: tcpSYN})
+	s := layer2.ConfirmedDataSender{SyncPattern: enums.MsSourcedData, ColorCode: 1}
+	now := time.Now()
+	bursts, err := s.Send(&layer2.PacketData{
+		SAP:         pdu.ServiceAccessPointIDIPBasedPacketData,
+		Source:      3120001,
+		Destination: 3120002,
+		Payload:     datagram,
+	}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range overAir(t, bursts) {
+		if err := c.AddBurst(b, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, _ := readDatagram(t, c); !bytes.Equal(got, datagram) {
+		t.Errorf("datagram:\n got % X\nwant % X", got, datagram)
+	}
+
+	var done bool
+	for _, b := range overAir(t, responses) {
+		_, d, err := s.AddBurst(b, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		done = done || d
+	}
+	if !done {
+		t.Error("the packet was not acknowledged")
+	}
+}
+
+func TestIPConn_Receive(t *testing.T) {
+	t.Parallel()
+	send := func(c *layer2.IPConn, destination int, group bool, payload []byte) error {
+		p := layer2.DataPacket{
+			SyncPattern: enums.MsSourcedData,
+			SAP:         pdu.ServiceAccessPointIDIPBasedPacketData,
+			Source:      1,
+			Destination: destination,
+			Group:       group,
+			FullMessage: true,
+			Payload:     payload,
+		}
+		bursts, err := p.Bursts()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, b := range overAir(t, bursts) {
+			if err := c.AddBurst(b, time.Now()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	datagram := func(id byte) []byte {
+		d := tcpDatagram(&tcpSegment{src: "12.0.0.1", dst: "12.0.0.2", flags: tcpSYN})
+		d[5] = id
+		return d
+	}
+
+	c := &layer2.IPConn{LLID: 2, QueueLength: 2}
+	for i, m := range []struct {
+		destination int
+		group       bool
+	}{{3, false}, {2, false}, {9, true}, {2, false}} {
+		if err := send(c, m.destination, m.group, datagram(byte(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The message to LLID 3 is not received, and the queue keeps the last
+	// two of the others.
+	for _, id := range []byte{2, 3} {
+		if got, _ := readDatagram(t, c); got[5] != id {
+			t.Errorf("datagram ID %d, want %d", got[5], id)
+		}
+	}
+
+	err := send(c, 2, false, []byte("not IPv4 at all, not at all"))
+	testutil.AssertPDUError(t, err, elements.ErrInvalidEncoding, elements.LayerPacket, "Version")
+}
+
+func TestIPConn_Deadlines(t *testing.T) {
+	t.Parallel()
+	c := &layer2.IPConn{}
+	buf := make([]byte, 64)
+
+	if err := c.SetReadDeadline(time.Now().Add(10 * time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Read(buf); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Read = %v, want os.ErrDeadlineExceeded", err)
+	}
+	if err := c.SetWriteDeadline(time.Now().Add(-time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Write(tcpDatagram(&tcpSegment{src: "12.0.0.1", dst: "12.0.0.2"})); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("Write = %v, want os.ErrDeadlineExceeded", err)
+	}
+
+	// A blocked read is woken by Close.
+	if err := c.SetDeadline(time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	errc := make(chan error)
+	go func() {
+		_, err := c.Read(buf)
+		errc <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errc; !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Read after Close = %v", err)
+	}
+	if _, err := c.Write(nil); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Write after Close = %v", err)
+	}
+}
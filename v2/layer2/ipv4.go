@@ -0,0 +1,360 @@
+package layer2
+
+import (
+	"encoding/binary"
+	"net/netip"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+)
+
+// IPv4 and UDP header layout used when rebuilding compressed headers.
+const (
+	ipv4HeaderOctets = 20
+	ipv4Version      = 4
+	ipv4IHL          = ipv4HeaderOctets / 4
+	// ipv4TTL is the Time To Live of a rebuilt header; the compressed
+	// header does not carry one.
+	ipv4TTL = 64
+	// ipv4FlagMoreFragments and ipv4FragmentOffsetMask select the
+	// fragmentation bits of the flags and fragment offset field.
+	ipv4FlagMoreFragments  = 0x2000
+	ipv4FragmentOffsetMask = 0x1FFF
+	ipv4ProtocolUDP        = 17
+	udpHeaderOctets        = 8
+
+	// llidMask selects the LLID in the low 24 bits of a radio's IPv4
+	// address.
+	llidMask = 0xFFFFFF
+)
+
+// IPv4AddressContext maps the source and destination address identifiers
+// (SAID and DAID) of a compressed header to IPv4 networks. The address of
+// a radio, or of a talkgroup, is the first octet of its network followed
+// by its 24-bit LLID: LLID 3120001 on network 12 is 12.47.155.129.
+//
+// The zero value uses network 12 for the radio network, 13 for the
+// USB/Ethernet network behind a radio and 225 for talkgroups.
+type IPv4AddressContext struct {
+	// Sources maps a SAID to the first octet of its network, overriding
+	// the default.
+	Sources map[enums.SAID]byte
+	// Destinations maps a DAID to the first octet of its network,
+	// overriding the default.
+	Destinations map[enums.DAID]byte
+}
+
+// sourceNetwork returns the network of a SAID.
+func (c *IPv4AddressContext) sourceNetwork(said enums.SAID) (byte, bool) {
+	if n, ok := c.Sources[said]; ok {
+		return n, true
+	}
+	switch said {
+	case enums.SAIDRadioNetwork:
+		return 12, true
+	case enums.SAIDUSBEthernetNetwork:
+		return 13, true
+	}
+	return 0, false
+}
+
+// destinationNetwork returns the network of a DAID.
+func (c *IPv4AddressContext) destinationNetwork(daid enums.DAID) (byte, bool) {
+	if n, ok := c.Destinations[daid]; ok {
+		return n, true
+	}
+	switch daid {
+	case enums.DAIDRadioNetwork:
+		return 12, true
+	case enums.DAIDUSBEthernetNetwork:
+		return 13, true
+	case enums.DAIDGroupNetwork:
+		return 225, true
+	}
+	return 0, false
+}
+
+// SourceAddress returns the IPv4 address of an LLID on the network of a
+// SAID. An unmapped SAID returns an *elements.PDUError wrapping
+// elements.ErrNotImplemented.
+func (c *IPv4AddressContext) SourceAddress(said enums.SAID, llid int) (netip.Addr, error) {
+	n, ok := c.sourceNetwork(said)
+	if !ok {
+		return netip.Addr{}, &elements.PDUError{Layer: elements.LayerPacket, Field: "SAID", Err: elements.ErrNotImplemented}
+	}
+	return llidAddress(n, llid), nil
+}
+
+// DestinationAddress returns the IPv4 address of an LLID on the network of
+// a DAID. An unmapped DAID returns an *elements.PDUError wrapping
+// elements.ErrNotImplemented.
+func (c *IPv4AddressContext) DestinationAddress(daid enums.DAID, llid int) (netip.Addr, error) {
+	n, ok := c.destinationNetwork(daid)
+	if !ok {
+		return netip.Addr{}, &elements.PDUError{Layer: elements.LayerPacket, Field: "DAID", Err: elements.ErrNotImplemented}
+	}
+	return llidAddress(n, llid), nil
+}
+
+// SourceID returns the SAID and LLID of a source address. An address on
+// none of the networks returns an *elements.PDUError wrapping
+// elements.ErrInvalidEncoding.
+func (c *IPv4AddressContext) SourceID(addr netip.Addr) (enums.SAID, int, error) {
+	if addr.Is4() {
+		a := addr.As4()
+		for said := range enums.SAID(16) {
+			if n, ok := c.sourceNetwork(said); ok && n == a[0] {
+				return said, addressLLID(a), nil
+			}
+		}
+	}
+	return 0, 0, &elements.PDUError{Layer: elements.LayerPacket, Field: "SourceAddress", Err: elements.ErrInvalidEncoding}
+}
+
+// DestinationID returns the DAID and LLID of a destination address. An
+// address on none of the networks returns an *elements.PDUError wrapping
+// elements.ErrInvalidEncoding.
+func (c *IPv4AddressContext) DestinationID(addr netip.Addr) (enums.DAID, int, error) {
+	if addr.Is4() {
+		a := addr.As4()
+		for daid := range enums.DAID(16) {
+			if n, ok := c.destinationNetwork(daid); ok && n == a[0] {
+				return daid, addressLLID(a), nil
+			}
+		}
+	}
+	return 0, 0, &elements.PDUError{Layer: elements.LayerPacket, Field: "DestinationAddress", Err: elements.ErrInvalidEncoding}
+}
+
+// llidAddress returns the address of an LLID on network n.
+func llidAddress(n byte, llid int) netip.Addr {
+	return netip.AddrFrom4([4]byte{n, byte(llid >> 16), byte(llid >> 8), byte(llid)})
+}
+
+// addressLLID returns the LLID in the low 24 bits of an address.
+func addressLLID(a [4]byte) int {
+	return int(binary.BigEndian.Uint32(a[:]) & llidMask)
+}
+
+// ipChecksum returns the Internet checksum (RFC 1071) of the concatenated
+// parts. Every part but the last must have an even length.
+func ipChecksum(parts ...[]byte) uint16 {
+	var sum uint32
+	for _, p := range parts {
+		for i := 0; i+1 < len(p); i += 2 {
+			sum += uint32(p[i])<<8 | uint32(p[i+1])
+		}
+		if len(p)%2 != 0 {
+			sum += uint32(p[len(p)-1]) << 8
+		}
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xFFFF + sum>>16
+	}
+	return ^uint16(sum) //nolint:gosec // folded to 16 bits above
+}
+
+// transportChecksum returns the TCP or UDP checksum of segment, including
+// the IPv4 pseudo-header, with the checksum field of segment taken as zero.
+// A UDP checksum of zero is sent as 0xFFFF.
+func transportChecksum(src, dst netip.Addr, protocol byte, segment []byte) uint16 {
+	s, d := src.As4(), dst.As4()
+	pseudo := make([]byte, 0, 12)
+	pseudo = append(pseudo, s[:]...)
+	pseudo = append(pseudo, d[:]...)
+	pseudo = append(pseudo, 0, protocol)
+	pseudo = binary.BigEndian.AppendUint16(pseudo, uint16(len(segment))) //nolint:gosec // bounded by the IPv4 total length
+	sum := ipChecksum(pseudo, segment)
+	if sum == 0 && protocol == ipv4ProtocolUDP {
+		return 0xFFFF
+	}
+	return sum
+}
+
+// ipv4Header is the part of an IPv4 header that compression carries.
+type ipv4Header struct {
+	Identification uint16
+	Protocol       byte
+	Source         netip.Addr
+	Destination    netip.Addr
+}
+
+// appendIPv4Header appends an IPv4 header without options for a datagram
+// carrying payloadLength octets, with its header checksum.
+func appendIPv4Header(out []byte, h *ipv4Header, payloadLength int) []byte {
+	start := len(out)
+	out = append(out, ipv4Version<<4|ipv4IHL, 0)
+	out = binary.BigEndian.AppendUint16(out, uint16(ipv4HeaderOctets+payloadLength)) //nolint:gosec // checked against the IPv4 total length by callers
+	out = binary.BigEndian.AppendUint16(out, h.Identification)
+	out = append(out, 0, 0, ipv4TTL, h.Protocol, 0, 0)
+	s, d := h.Source.As4(), h.Destination.As4()
+	out = append(out, s[:]...)
+	out = append(out, d[:]...)
+	binary.BigEndian.PutUint16(out[start+10:], ipChecksum(out[start:]))
+	return out
+}
+
+// parseIPv4Header checks that datagram is an unfragmented IPv4 datagram
+// without options and returns its header and payload. Fields lost by
+// compression (type of service, TTL and the Don't Fragment flag) are
+// ignored.
+func parseIPv4Header(datagram []byte) (*ipv4Header, []byte, error) {
+	if len(datagram) < ipv4HeaderOctets {
+		return nil, nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "IPv4Header", Err: elements.ErrInvalidLength}
+	}
+	if datagram[0]>>4 != ipv4Version {
+		return nil, nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Version", Err: elements.ErrInvalidEncoding}
+	}
+	if datagram[0]&0x0F != ipv4IHL {
+		return nil, nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "IPv4Options", Err: elements.ErrInvalidEncoding}
+	}
+	total := int(binary.BigEndian.Uint16(datagram[2:]))
+	if total < ipv4HeaderOctets || total > len(datagram) {
+		return nil, nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "TotalLength", Err: elements.ErrInvalidLength}
+	}
+	if binary.BigEndian.Uint16(datagram[6:])&(ipv4FlagMoreFragments|ipv4FragmentOffsetMask) != 0 {
+		return nil, nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "FragmentOffset", Err: elements.ErrInvalidEncoding}
+	}
+	return &ipv4Header{
+		Identification: binary.BigEndian.Uint16(datagram[4:]),
+		Protocol:       datagram[9],
+		Source:         netip.AddrFrom4([4]byte(datagram[12:16])),
+		Destination:    netip.AddrFrom4([4]byte(datagram[16:20])),
+	}, datagram[ipv4HeaderOctets:total], nil
+}
+
+// UDPDatagram is a UDP/IPv4 datagram, as carried by packet data with or
+// without header compression.
+type UDPDatagram struct {
+	Source         netip.AddrPort
+	Destination    netip.AddrPort
+	Identification uint16
+	Payload        []byte
+}
+
+// ParseUDPDatagram returns the UDP datagram carried by an IPv4 datagram.
+// The type of service, TTL and Don't Fragment flag are ignored.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrInvalidLength for
+// a truncated datagram, elements.ErrDataTypeMismatch for another protocol,
+// or elements.ErrInvalidEncoding for IPv4 options or a fragment.
+func ParseUDPDatagram(datagram []byte) (*UDPDatagram, error) {
+	ip, payload, err := parseIPv4Header(datagram)
+	if err != nil {
+		return nil, err
+	}
+	if ip.Protocol != ipv4ProtocolUDP {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Protocol", Err: elements.ErrDataTypeMismatch}
+	}
+	if len(payload) < udpHeaderOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "UDPHeader", Err: elements.ErrInvalidLength}
+	}
+	udpLength := int(binary.BigEndian.Uint16(payload[4:]))
+	if udpLength < udpHeaderOctets || udpLength > len(payload) {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "UDPLength", Err: elements.ErrInvalidLength}
+	}
+	return &UDPDatagram{
+		Source:         netip.AddrPortFrom(ip.Source, binary.BigEndian.Uint16(payload[0:])),
+		Destination:    netip.AddrPortFrom(ip.Destination, binary.BigEndian.Uint16(payload[2:])),
+		Identification: ip.Identification,
+		Payload:        payload[udpHeaderOctets:udpLength],
+	}, nil
+}
+
+// Encode returns the IPv4 datagram, with the header that compression
+// rebuilds: no options, a TTL of 64 and both checksums. Addresses that are
+// not IPv4, or a payload too large for one datagram, return an
+// *elements.PDUError wrapping elements.ErrInvalidEncoding or
+// elements.ErrInvalidLength.
+func (d *UDPDatagram) Encode() ([]byte, error) {
+	if !d.Source.Addr().Is4() || !d.Destination.Addr().Is4() {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Address", Err: elements.ErrInvalidEncoding}
+	}
+	udpLength := udpHeaderOctets + len(d.Payload)
+	if ipv4HeaderOctets+udpLength > maxIPv4Octets {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Payload", Err: elements.ErrInvalidLength}
+	}
+	src, dst := d.Source.Addr(), d.Destination.Addr()
+	datagram := make([]byte, 0, ipv4HeaderOctets+udpLength)
+	datagram = appendIPv4Header(datagram, &ipv4Header{
+		Identification: d.Identification,
+		Protocol:       ipv4ProtocolUDP,
+		Source:         src,
+		Destination:    dst,
+	}, udpLength)
+	udp := len(datagram)
+	datagram = binary.BigEndian.AppendUint16(datagram, d.Source.Port())
+	datagram = binary.BigEndian.AppendUint16(datagram, d.Destination.Port())
+	datagram = binary.BigEndian.AppendUint16(datagram, uint16(udpLength)) //nolint:gosec // checked against maxIPv4Octets
+	datagram = append(datagram, 0, 0)
+	datagram = append(datagram, d.Payload...)
+	binary.BigEndian.PutUint16(datagram[udp+6:], transportChecksum(src, dst, ipv4ProtocolUDP, datagram[udp:]))
+	return datagram, nil
+}
+
+// RadioDatagram is a UDP datagram between the well-known ports of a radio
+// application, with its addresses given as radio IDs.
+type RadioDatagram struct {
+	// Source is the radio ID of the sender, on the radio network.
+	Source int
+	// Destination is the radio ID or talkgroup the datagram is sent to.
+	Destination int
+	// Group reports a Destination on the group network.
+	Group   bool
+	Payload []byte
+}
+
+// DecodeRadioDatagram returns the radio datagram carried by a UDP/IPv4
+// datagram to port, taking the radio IDs from its addresses.
+//
+// Errors are an *elements.PDUError: as for ParseUDPDatagram, SourceID and
+// DestinationID, or wrapping elements.ErrDataTypeMismatch for another
+// port.
+func (c *IPv4AddressContext) DecodeRadioDatagram(datagram []byte, port uint16) (*RadioDatagram, error) {
+	d, err := ParseUDPDatagram(datagram)
+	if err != nil {
+		return nil, err
+	}
+	if d.Destination.Port() != port {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Port", Err: elements.ErrDataTypeMismatch}
+	}
+	_, source, err := c.SourceID(d.Source.Addr())
+	if err != nil {
+		return nil, err
+	}
+	daid, destination, err := c.DestinationID(d.Destination.Addr())
+	if err != nil {
+		return nil, err
+	}
+	return &RadioDatagram{
+		Source:      source,
+		Destination: destination,
+		Group:       daid == enums.DAIDGroupNetwork,
+		Payload:     d.Payload,
+	}, nil
+}
+
+// EncodeRadioDatagram returns the UDP/IPv4 datagram that carries d between
+// port at both ends, with the IPv4 identification given. Errors are as for
+// SourceAddress, DestinationAddress and UDPDatagram.Encode.
+func (c *IPv4AddressContext) EncodeRadioDatagram(d *RadioDatagram, port, identification uint16) ([]byte, error) {
+	daid := enums.DAIDRadioNetwork
+	if d.Group {
+		daid = enums.DAIDGroupNetwork
+	}
+	src, err := c.SourceAddress(enums.SAIDRadioNetwork, d.Source)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := c.DestinationAddress(daid, d.Destination)
+	if err != nil {
+		return nil, err
+	}
+	udp := UDPDatagram{
+		Source:         netip.AddrPortFrom(src, port),
+		Destination:    netip.AddrPortFrom(dst, port),
+		Identification: identification,
+		Payload:        d.Payload,
+	}
+	return udp.Encode()
+}
@@ -0,0 +1,212 @@
+package layer2
+
+import (
+	"fmt"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/crc"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// ETSI TS 102 361-1 §9.2.6 / ETSI TS 102 361-3 §5 — Packet data
+//
+// A packet is a data header followed by BlocksToFollow data blocks of one
+// rate (Rate 1/2, 3/4 or 1). The user data fills the blocks in order,
+// followed by PadOctetCount pad octets and a 32-bit message CRC (B.3.9) in
+// the last four octets of the last block, least significant octet first.
+// The CRC covers the user data and the pad octets.
+//
+// A message larger than one packet is sent as fragments. FullMessage is set
+// in the header of the first fragment, the three LSBs of the
+// FragmentSequenceNumber count the fragments modulo 8, and its MSB marks
+// the last fragment. A header with FullMessage set and an FSN of 0 is an
+// unfragmented message.
+
+const (
+	// PacketCRCOctets is the size of the message CRC at the end of the last
+	// block of a packet.
+	PacketCRCOctets = 4
+
+	// fsnLastFragment is the FragmentSequenceNumber bit that marks the last
+	// fragment of a message.
+	fsnLastFragment = 0b1000
+	// fsnSequenceMask selects the fragment counter bits of the
+	// FragmentSequenceNumber.
+	fsnSequenceMask = 0b0111
+)
+
+// PacketData is a packet data message reassembled from its data header and
+// data blocks, and from every fragment when the message was fragmented.
+type PacketData struct {
+	// SAP is the service access point from the data header.
+	SAP pdu.ServiceAccessPointID
+	// Source and Destination are the LLIDs from the data header.
+	Source      int
+	Destination int
+	// Group reports a message addressed to a talkgroup.
+	Group bool
+	// ResponseRequested is the flag from the first fragment's header.
+	ResponseRequested bool
+	// FragmentSequenceNumbers holds the FSN of each fragment, in order.
+	FragmentSequenceNumbers []uint8
+	// Payload is the user data with the pad octets and message CRC removed.
+	Payload []byte
+}
+
+// ToString returns a string representation of the message.
+func (p *PacketData) ToString() string {
+	return fmt.Sprintf("PacketData{ SAP: %d, Source: %d, Destination: %d, Group: %t, ResponseRequested: %t, FragmentSequenceNumbers: %v, Payload: % X }",
+		p.SAP, p.Source, p.Destination, p.Group, p.ResponseRequested, p.FragmentSequenceNumbers, p.Payload)
+}
+
+// MissingBlocksError reports a packet that was abandoned before all of its
+// data blocks were received, because another header or a voice burst
+// arrived, or because it timed out. It wraps elements.ErrMissingBlocks.
+type MissingBlocksError struct {
+	// Expected is the BlocksToFollow count of the packet's header.
+	Expected int
+	// Received is the number of data blocks received.
+	Received int
+	// TimedOut reports that the packet was abandoned by its timeout.
+	TimedOut bool
+}
+
+func (e *MissingBlocksError) Error() string {
+	reason := "interrupted"
+	if e.TimedOut {
+		reason = "timed out"
+	}
+	return fmt.Sprintf("%s: %s: received %d of %d blocks, %s",
+		elements.LayerPacket, elements.ErrMissingBlocks, e.Received, e.Expected, reason)
+}
+
+// Unwrap returns elements.ErrMissingBlocks.
+func (e *MissingBlocksError) Unwrap() error {
+	return elements.ErrMissingBlocks
+}
+
+// dataBlockOctets returns the octets carried by a Rate 1/2, 3/4 or 1 data
+// burst. A data block whose FEC could not be decoded is returned as zeros so
+// it still counts towards BlocksToFollow; the packet then fails its CRC.
+func dataBlockOctets(b *Burst) ([]byte, bool) {
+	switch d := b.Data.(type) {
+	case *pdu.Rate12Data:
+		return d.Data[:], true
+	case *pdu.Rate34Data:
+		return d.Data[:], true
+	case *pdu.Rate1Data:
+		return d.Data[:], true
+	}
+	if !b.IsData || !b.HasSlotType {
+		return nil, false
+	}
+	switch b.SlotType.DataType {
+	case elements.DataTypeRate12:
+		return make([]byte, 12), true
+	case elements.DataTypeRate34:
+		return make([]byte, 18), true
+	case elements.DataTypeRate1:
+		return make([]byte, 24), true
+	default:
+		return nil, false
+	}
+}
+
+// PacketBlockOctets returns the number of data octets carried by one data
+// block of dt (elements.DataTypeRate12, DataTypeRate34 or DataTypeRate1), or
+// 0 for any other data type. In a confirmed block the first two octets hold
+// the DBSN and CRC-9, leaving two fewer for data.
+func PacketBlockOctets(dt elements.DataType, confirmed bool) int {
+	var n int
+	switch dt {
+	case elements.DataTypeRate12:
+		n = 12
+	case elements.DataTypeRate34:
+		n = 18
+	case elements.DataTypeRate1:
+		n = 24
+	default:
+		return 0
+	}
+	if confirmed {
+		n -= confirmedBlockHeaderOctets
+	}
+	return n
+}
+
+// dataBlockData wraps the octets of a data block of dt in its PDU.
+func dataBlockData(dt elements.DataType, octets []byte) elements.Data {
+	switch dt {
+	case elements.DataTypeRate34:
+		d := &pdu.Rate34Data{DataType: dt}
+		copy(d.Data[:], octets)
+		return d
+	case elements.DataTypeRate1:
+		d := &pdu.Rate1Data{DataType: dt}
+		copy(d.Data[:], octets)
+		return d
+	default:
+		d := &pdu.Rate12Data{DataType: elements.DataTypeRate12}
+		copy(d.Data[:], octets)
+		return d
+	}
+}
+
+// newDataBurst returns a data burst carrying d.
+func newDataBurst(syncPattern enums.SyncPattern, colorCode int, d elements.Data) Burst {
+	return Burst{
+		SyncPattern: syncPattern,
+		IsData:      true,
+		HasSlotType: true,
+		SlotType:    pdu.SlotType{ColorCode: colorCode, DataType: d.GetDataType()},
+		Data:        d,
+	}
+}
+
+// splitPacket appends the pad octets and message CRC to payload and splits
+// the result into blocks of blockOctets. Returns the blocks and the number
+// of pad octets.
+func splitPacket(payload []byte, blockOctets int) ([][]byte, int) {
+	n := (len(payload) + PacketCRCOctets + blockOctets - 1) / blockOctets
+	pad := n*blockOctets - len(payload) - PacketCRCOctets
+
+	data := make([]byte, 0, n*blockOctets)
+	data = append(data, payload...)
+	data = append(data, make([]byte, pad)...)
+	data = appendPacketCRC(data)
+
+	blocks := make([][]byte, n)
+	for i := range blocks {
+		blocks[i] = data[i*blockOctets : (i+1)*blockOctets]
+	}
+	return blocks, pad
+}
+
+// appendPacketCRC appends the message CRC of data, least significant octet
+// first.
+func appendPacketCRC(data []byte) []byte {
+	sum := crc.CalculateCRC32(data)
+	return append(data, byte(sum), byte(sum>>8), byte(sum>>16), byte(sum>>24))
+}
+
+// checkPacketCRC verifies the message CRC at the end of the data octets of a
+// packet and returns the user data with the pad octets and CRC removed.
+func checkPacketCRC(data []byte, padOctets int) ([]byte, error) {
+	if len(data) < PacketCRCOctets+padOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "PadOctetCount", Err: elements.ErrInvalidLength}
+	}
+	body := data[:len(data)-PacketCRCOctets]
+	tail := data[len(data)-PacketCRCOctets:]
+	received := uint32(tail[0]) | uint32(tail[1])<<8 | uint32(tail[2])<<16 | uint32(tail[3])<<24
+	if !crc.CheckCRC32(body, received) {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "CRC", Err: elements.ErrCRCMismatch}
+	}
+	return body[:len(body)-padOctets], nil
+}
+
+// llid returns the value of a 24-bit LLID field.
+func llid(bits [24]bit.Bit) int {
+	return bit.BitsToInt(bits[:], 0, 24)
+}
@@ -0,0 +1,30 @@
+package pdu
+
+// ARPPacket is an Address Resolution Protocol packet, sent with SAP
+// AddressResolutionProtocol (0b0101). It has the RFC 826 layout with
+// 3-octet hardware addresses, the DMR IDs of the radios, and 4-octet IPv4
+// protocol addresses.
+type ARPPacket struct {
+	HardwareType          uint16 `dmr:"bits:0-15"`
+	ProtocolType          uint16 `dmr:"bits:16-31"`
+	HardwareAddressLength uint8  `dmr:"bits:32-39"`
+	ProtocolAddressLength uint8  `dmr:"bits:40-47"`
+	Operation             uint16 `dmr:"bits:48-63"`
+	SenderHardwareAddress int    `dmr:"bits:64-87"`
+	SenderProtocolAddress uint32 `dmr:"bits:88-119"`
+	TargetHardwareAddress int    `dmr:"bits:120-143"`
+	TargetProtocolAddress uint32 `dmr:"bits:144-175"`
+}
+
+// ARPOctets is the size of an ARPPacket.
+const ARPOctets = 22
+
+// ARP operations and field values of an ARPPacket.
+const (
+	ARPOperationRequest = 1
+	ARPOperationReply   = 2
+
+	ARPProtocolTypeIPv4          = 0x0800
+	ARPHardwareAddressLengthDMR  = 3
+	ARPProtocolAddressLengthIPv4 = 4
+)
@@ -0,0 +1,45 @@
+/*
+Code generated by dmrgen.
+DO NOT EDIT.
+*/
+
+package pdu
+
+import (
+	"fmt"
+	bit "github.com/USA-RedDragon/dmrgo/v2/bit"
+	fec "github.com/USA-RedDragon/dmrgo/v2/fec"
+)
+
+func DecodeARPPacket(data [176]bit.Bit) (ARPPacket, fec.FECResult) {
+	var result ARPPacket
+	var fecResult fec.FECResult
+	result.HardwareType = bit.BitsToUint16(data[:], 0, 16)
+	result.ProtocolType = bit.BitsToUint16(data[:], 16, 16)
+	result.HardwareAddressLength = bit.BitsToUint8(data[:], 32, 8)
+	result.ProtocolAddressLength = bit.BitsToUint8(data[:], 40, 8)
+	result.Operation = bit.BitsToUint16(data[:], 48, 16)
+	result.SenderHardwareAddress = bit.BitsToInt(data[:], 64, 24)
+	result.SenderProtocolAddress = bit.BitsToUint32(data[:], 88, 32)
+	result.TargetHardwareAddress = bit.BitsToInt(data[:], 120, 24)
+	result.TargetProtocolAddress = bit.BitsToUint32(data[:], 144, 32)
+	return result, fecResult
+}
+
+func EncodeARPPacket(s *ARPPacket) [176]bit.Bit {
+	var data [176]bit.Bit
+	copy(data[0:16], bit.BitsFromUint16(s.HardwareType, 16))
+	copy(data[16:32], bit.BitsFromUint16(s.ProtocolType, 16))
+	copy(data[32:40], bit.BitsFromUint8(s.HardwareAddressLength, 8))
+	copy(data[40:48], bit.BitsFromUint8(s.ProtocolAddressLength, 8))
+	copy(data[48:64], bit.BitsFromUint16(s.Operation, 16))
+	copy(data[64:88], bit.BitsFromUint32(uint32(s.SenderHardwareAddress), 24))
+	copy(data[88:120], bit.BitsFromUint32(s.SenderProtocolAddress, 32))
+	copy(data[120:144], bit.BitsFromUint32(uint32(s.TargetHardwareAddress), 24))
+	copy(data[144:176], bit.BitsFromUint32(s.TargetProtocolAddress, 32))
+	return data
+}
+
+func (s *ARPPacket) ToString() string {
+	return fmt.Sprintf("ARPPacket{ HardwareType: %d, ProtocolType: %d, HardwareAddressLength: %d, ProtocolAddressLength: %d, Operation: %d, SenderHardwareAddress: %d, SenderProtocolAddress: %d, TargetHardwareAddress: %d, TargetProtocolAddress: %d }", s.HardwareType, s.ProtocolType, s.HardwareAddressLength, s.ProtocolAddressLength, s.Operation, s.SenderHardwareAddress, s.SenderProtocolAddress, s.TargetHardwareAddress, s.TargetProtocolAddress)
+}
@@ -0,0 +1,38 @@
+package pdu_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+func TestARPPacket_RoundTrip(t *testing.T) {
+	t.Parallel()
+	original := pdu.ARPPacket{
+		HardwareType:          0x0001,
+		ProtocolType:          pdu.ARPProtocolTypeIPv4,
+		HardwareAddressLength: pdu.ARPHardwareAddressLengthDMR,
+		ProtocolAddressLength: pdu.ARPProtocolAddressLengthIPv4,
+		Operation:             pdu.ARPOperationReply,
+		SenderHardwareAddress: 3120001,
+		SenderProtocolAddress: 0x0A000001,
+		TargetHardwareAddress: 0xFFFFFF,
+		TargetProtocolAddress: 0xC0A80102,
+	}
+
+	encoded := pdu.EncodeARPPacket(&original)
+	want := []byte{
+		0x00, 0x01, 0x08, 0x00, 0x03, 0x04, 0x00, 0x02,
+		0x2F, 0x9B, 0x81, 0x0A, 0x00, 0x00, 0x01,
+		0xFF, 0xFF, 0xFF, 0xC0, 0xA8, 0x01, 0x02,
+	}
+	if got := bit.PackBits(encoded[:]); !bytes.Equal(got, want) {
+		t.Errorf("encoded:\n got % X\nwant % X", got, want)
+	}
+	decoded, _ := pdu.DecodeARPPacket(encoded)
+	if decoded != original {
+		t.Errorf("decoded = %s, want %s", decoded.ToString(), original.ToString())
+	}
+}
@@ -114,6 +114,45 @@ func (opcode CSBKOpcode) ToString() string {
 	}
 }
 
+// IsKnown reports whether the opcode is one of the standardized opcodes
+// defined above.
+func (opcode CSBKOpcode) IsKnown() bool {
+	switch opcode {
+	case CSBKUnitToUnitVoiceServiceRequestPDU,
+		CSBKUnitToUnitVoiceServiceAnswerResponsePDU,
+		CSBKChannelTimingPDU,
+		CSBKNegativeAcknowledgementPDU,
+		CSBKBSOutboundActivationPDU,
+		CSBKPreamblePDU,
+		CSBKAloha,
+		CSBKUDTOutboundHeader,
+		CSBKUDTInboundHeader,
+		CSBKAhoy,
+		CSBKAckvitation,
+		CSBKRandomAccess,
+		CSBKAckOutbound,
+		CSBKAckInbound,
+		CSBKAckOutboundPayload,
+		CSBKAckInboundPayload,
+		CSBKBroadcast,
+		CSBKMaintenance,
+		CSBKClear,
+		CSBKProtect,
+		CSBKPrivateVoiceGrant,
+		CSBKTalkgroupVoiceGrant,
+		CSBKBroadcastTalkgroupVoiceGrant,
+		CSBKPrivateDataGrant,
+		CSBKTalkgroupDataGrant,
+		CSBKDuplexPrivateVoiceGrant,
+		CSBKDuplexPrivateDataGrant,
+		CSBKPrivateDataGrantMultiItem,
+		CSBKMove:
+		return true
+	default:
+		return false
+	}
+}
+
 // ETSI TS 102 361-1 - 9.3.6 BS Outbound Activation (BS_Dwn_Act) PDU
 type BSOutboundActivationPDU struct {
 	Reserved      uint16      `dmr:"bits:0-15"`
@@ -2,6 +2,7 @@ package pdu
 
 import (
 	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
 	"github.com/USA-RedDragon/dmrgo/v2/fec"
 	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
 )
@@ -39,6 +40,8 @@ type DataHeader struct {
 	DefinedDataHeader     *DefinedDataHeader     `dmr:"bits:0-79,dispatch:Format=FormatShortDataDefined"`
 	StatusPrecodedHeader  *StatusPrecodedHeader  `dmr:"bits:0-79,dispatch:Format=FormatShortDataRawOrStatusPrecoded,when:AppendedBlocks==0"`
 	RawDataHeader         *RawDataHeader         `dmr:"bits:0-79,dispatch:Format=FormatShortDataRawOrStatusPrecoded"`
+	ProprietaryHeader     *ProprietaryHeader     `dmr:"bits:0-79,dispatch:Format=FormatProprietary"`
+	UDTHeader             *UDTHeader             `dmr:"bits:0-79,dispatch:Format=FormatUnifiedDataTransport"`
 }
 
 type ServiceAccessPointID uint8
@@ -159,3 +162,44 @@ type StatusPrecodedHeader struct {
 	DestinationPort   uint8  `dmr:"bits:67-69"`
 	StatusPrecoded    uint16 `dmr:"bits:70-79"`
 }
+
+// ETSI TS 102 361-1 - Table 9.18: Proprietary Header (P_HEAD) PDU content
+// The manufacturer identified by MFID defines the proprietary data.
+type ProprietaryHeader struct {
+	SAP  uint8              `dmr:"bits:0-3"`
+	MFID enums.FeatureSetID `dmr:"bits:8-15,name:enums.FeatureSetIDToName"`
+	Data [8]byte            `dmr:"bits:16-79,packed"`
+}
+
+// ETSI TS 102 361-4 - Unified Data Transport Header (UDT_HEAD) PDU content
+// The first eight octets match the C_UDTHD/C_UDTHU CSBKs.
+type UDTHeader struct {
+	Group             bool  `dmr:"bit:0"`
+	ResponseRequested bool  `dmr:"bit:1"`
+	Emergency         bool  `dmr:"bit:2"`
+	UDTOptionFlag     bool  `dmr:"bit:3"`
+	SAP               uint8 `dmr:"bits:8-11"`
+	UDTFormat         uint8 `dmr:"bits:12-15"`
+	LLIDDestination   int   `dmr:"bits:16-39"`
+	LLIDSource        int   `dmr:"bits:40-63"`
+	// PadNibble is the number of pad nibbles at the end of the appended
+	// blocks.
+	PadNibble uint8 `dmr:"bits:64-68"`
+	Reserved  bool  `dmr:"bit:69"`
+	// AppendedBlocks is the number of appended blocks minus one (UAB).
+	AppendedBlocks    uint8 `dmr:"bits:70-71"`
+	SupplementaryFlag bool  `dmr:"bit:72"`
+	ProtectFlag       bool  `dmr:"bit:73"`
+	Opcode            uint8 `dmr:"bits:74-79"`
+}
+
+// Manufacturer returns the name of the manufacturer that defines the
+// proprietary data.
+func (h *ProprietaryHeader) Manufacturer() string {
+	return enums.FeatureSetIDToName(h.MFID)
+}
+
+// Blocks returns the number of data blocks appended to the UDT header.
+func (h *UDTHeader) Blocks() int {
+	return int(h.AppendedBlocks) + 1
+}
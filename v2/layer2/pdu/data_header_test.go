@@ -1,10 +1,12 @@
 package pdu_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/USA-RedDragon/dmrgo/v2/bit"
 	"github.com/USA-RedDragon/dmrgo/v2/crc"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
 	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
 	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
 )
@@ -88,39 +90,21 @@ func TestDataHeader_CRCFailure(t *testing.T) {
 }
 
 func TestDataHeader_FormatDispatch(t *testing.T) {
-	// Test that non-Unconfirmed formats decode without panic but have nil sub-PDUs
+	// Test that the UDT and Proprietary formats dispatch to their own sub-PDU
 	formats := []struct {
 		name string
 		bits [4]bit.Bit // bits at positions 4-7
+		want func(dh *pdu.DataHeader) bool
 	}{
-		{"UDT", [4]bit.Bit{0, 0, 0, 0}},
-		{"Proprietary", [4]bit.Bit{1, 1, 1, 1}},
+		{"UDT", [4]bit.Bit{0, 0, 0, 0}, func(dh *pdu.DataHeader) bool { return dh.UDTHeader != nil }},
+		{"Proprietary", [4]bit.Bit{1, 1, 1, 1}, func(dh *pdu.DataHeader) bool { return dh.ProprietaryHeader != nil }},
 	}
 
 	for _, tt := range formats {
 		t.Run(tt.name, func(t *testing.T) {
-			var infoBits [96]bit.Bit
-			infoBits[4] = tt.bits[0]
-			infoBits[5] = tt.bits[1]
-			infoBits[6] = tt.bits[2]
-			infoBits[7] = tt.bits[3]
-
-			// Add valid CRC so the decode proceeds past CRC check
-			var dataBytes [10]byte
-			for i := range 10 {
-				for j := range 8 {
-					dataBytes[i] <<= 1
-					dataBytes[i] |= byte(infoBits[i*8+j])
-				}
-			}
-			crcVal := crc.CalculateCRCCCITT(dataBytes[:])
-			// Apply Data Header CRC mask per ETSI TS 102 361-1 Table B.3
-			crcHigh := byte(crcVal>>8) ^ 0xCC
-			crcLow := byte(crcVal) ^ 0xCC
-			for b := 7; b >= 0; b-- {
-				infoBits[80+(7-b)] = bit.Bit((crcHigh >> b) & 1)
-				infoBits[88+(7-b)] = bit.Bit((crcLow >> b) & 1)
-			}
+			infoBits := buildDataHeaderBits(func(bits *[96]bit.Bit) {
+				copy(bits[4:8], tt.bits[:])
+			})
 
 			dh, fecResult := pdu.DecodeDataHeader(infoBits)
 			if fecResult.Uncorrectable {
@@ -130,6 +114,9 @@ func TestDataHeader_FormatDispatch(t *testing.T) {
 			if dh.UnconfirmedDataHeader != nil {
 				t.Errorf("format %s should have nil UnconfirmedDataHeader", tt.name)
 			}
+			if !tt.want(&dh) {
+				t.Errorf("format %s did not decode its sub-PDU: %s", tt.name, dh.ToString())
+			}
 		})
 	}
 }
@@ -434,3 +421,109 @@ func TestDataHeader_StatusPrecoded_RoundTrip(t *testing.T) {
 		t.Error("RawDataHeader should be nil for zero AppendedBlocks")
 	}
 }
+
+func TestDataHeader_Proprietary_Decode(t *testing.T) {
+	t.Parallel()
+	// SAP 9 (proprietary packet data), DPF 1111, MFID 0x10 (Motorola)
+	octets := []byte{0x9F, 0x10, 0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF}
+	infoBits := buildDataHeaderBits(func(bits *[96]bit.Bit) {
+		copy(bits[:80], bit.UnpackBits(octets))
+	})
+
+	decoded, fecResult := pdu.DecodeDataHeader(infoBits)
+	if fecResult.Uncorrectable {
+		t.Fatal("DecodeDataHeader returned uncorrectable FEC")
+	}
+	ph := decoded.ProprietaryHeader
+	if ph == nil {
+		t.Fatal("ProprietaryHeader is nil")
+	}
+	if ph.SAP != uint8(pdu.ServiceAccessPointIDProprietaryPacketData) {
+		t.Errorf("SAP = %d, want 9", ph.SAP)
+	}
+	if ph.MFID != enums.MotorolaLtd {
+		t.Errorf("MFID = %#x, want %#x", ph.MFID, enums.MotorolaLtd)
+	}
+	if ph.Manufacturer() != "Motorola Ltd. UK" {
+		t.Errorf("Manufacturer() = %q", ph.Manufacturer())
+	}
+	if ph.Data != [8]byte{0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF} {
+		t.Errorf("Data = % X", ph.Data)
+	}
+
+	// Re-encoding reproduces the header bit for bit.
+	if got := pdu.EncodeDataHeader(&decoded); got != infoBits {
+		t.Errorf("EncodeDataHeader() = %v, want %v", got, infoBits)
+	}
+}
+
+func TestDataHeader_Proprietary_RoundTrip(t *testing.T) {
+	t.Parallel()
+	original := &pdu.DataHeader{
+		Format: pdu.FormatProprietary,
+		ProprietaryHeader: &pdu.ProprietaryHeader{
+			SAP:  uint8(pdu.ServiceAccessPointIDProprietaryPacketData),
+			MFID: 0x42, // not in the FID table
+			Data: [8]byte{0xDE, 0xAD, 0xBE, 0xEF, 0x00, 0x11, 0x22, 0x33},
+		},
+	}
+
+	decoded, fecResult := pdu.DecodeDataHeader(pdu.EncodeDataHeader(original))
+	if fecResult.Uncorrectable {
+		t.Fatal("DecodeDataHeader returned uncorrectable FEC")
+	}
+	if decoded.Format != pdu.FormatProprietary || decoded.ProprietaryHeader == nil {
+		t.Fatalf("decoded = %s", decoded.ToString())
+	}
+	if *decoded.ProprietaryHeader != *original.ProprietaryHeader {
+		t.Errorf("ProprietaryHeader = %s, want %s", decoded.ProprietaryHeader.ToString(), original.ProprietaryHeader.ToString())
+	}
+	if got := decoded.ProprietaryHeader.Manufacturer(); got != "Unknown FeatureSetID: 66" {
+		t.Errorf("Manufacturer() = %q", got)
+	}
+	if got := decoded.ProprietaryHeader.ToString(); !strings.Contains(got, "MFID: Unknown FeatureSetID: 66,") {
+		t.Errorf("ToString() = %q", got)
+	}
+}
+
+func TestDataHeader_UDT_RoundTrip(t *testing.T) {
+	t.Parallel()
+	original := &pdu.DataHeader{
+		Format: pdu.FormatUnifiedDataTransport,
+		UDTHeader: &pdu.UDTHeader{
+			Group:             true,
+			ResponseRequested: false,
+			Emergency:         true,
+			SAP:               uint8(pdu.ServiceAccessPointIDUnifiedDataTransport),
+			UDTFormat:         0b0111,
+			LLIDDestination:   0xABCDEF,
+			LLIDSource:        0x123456,
+			PadNibble:         0b10101,
+			AppendedBlocks:    0b11,
+			SupplementaryFlag: true,
+			ProtectFlag:       false,
+			Opcode:            0b101101,
+		},
+	}
+
+	infoBits := pdu.EncodeDataHeader(original)
+	decoded, fecResult := pdu.DecodeDataHeader(infoBits)
+	if fecResult.Uncorrectable {
+		t.Fatal("DecodeDataHeader returned uncorrectable FEC")
+	}
+	if decoded.Format != pdu.FormatUnifiedDataTransport || decoded.UDTHeader == nil {
+		t.Fatalf("decoded = %s", decoded.ToString())
+	}
+	if *decoded.UDTHeader != *original.UDTHeader {
+		t.Errorf("UDTHeader = %s, want %s", decoded.UDTHeader.ToString(), original.UDTHeader.ToString())
+	}
+	if decoded.UDTHeader.Blocks() != 4 {
+		t.Errorf("Blocks() = %d, want 4", decoded.UDTHeader.Blocks())
+	}
+
+	// Octets 8 and 9: pad nibble, reserved, UAB; SF, PF, opcode.
+	octets := bit.PackBits(infoBits[:80])
+	if octets[0] != 0b1010_0000 || octets[1]&0x0F != 0b0111 || octets[8] != 0b10101_0_11 || octets[9] != 0b1_0_101101 {
+		t.Errorf("octets = % X", octets)
+	}
+}
@@ -76,3 +76,28 @@ type FLCTerminatorDataLinkControl struct {
 func (flc FullLinkControl) GetDataType() layer2Elements.DataType {
 	return flc.DataType
 }
+
+// Reed-Solomon parity masks for the Full LC (ETSI TS 102 361-1 - B.3.12,
+// Table B.21). Each of the three parity octets is XORed with the mask.
+const (
+	FLCVoiceLCHeaderParityMask    = byte(0x96)
+	FLCTerminatorWithLCParityMask = byte(0x99)
+)
+
+// ApplyFLCParityMask XORs the Reed-Solomon parity (bits 72-95) of a Full LC
+// with the mask for dataType. Applying it twice restores the original bits;
+// other data types are left unchanged.
+func ApplyFLCParityMask(bits *[96]bit.Bit, dataType layer2Elements.DataType) {
+	var mask byte
+	switch dataType {
+	case layer2Elements.DataTypeVoiceLCHeader:
+		mask = FLCVoiceLCHeaderParityMask
+	case layer2Elements.DataTypeTerminatorWithLC:
+		mask = FLCTerminatorWithLCParityMask
+	default:
+		return
+	}
+	for i := 72; i < 96; i++ {
+		bits[i] ^= bit.Bit((mask >> (7 - (i % 8))) & 1)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/USA-RedDragon/dmrgo/v2/bit"
 	"github.com/USA-RedDragon/dmrgo/v2/enums"
 	"github.com/USA-RedDragon/dmrgo/v2/fec"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
 	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
 	layer3Elements "github.com/USA-RedDragon/dmrgo/v2/layer3/elements"
 )
@@ -424,3 +425,38 @@ func TestFLC_TerminatorDataLinkControl_RoundTrip(t *testing.T) {
 		t.Errorf("ToString missing FLCO name, got: %s", str)
 	}
 }
+
+func TestApplyFLCParityMask(t *testing.T) {
+	flc := &pdu.FullLinkControl{
+		FLCO:       enums.FLCOGroupVoiceChannelUser,
+		GroupVoice: &pdu.FLCGroupVoice{GroupAddress: 9, SourceAddress: 3120001},
+	}
+	plain := pdu.EncodeFullLinkControl(flc)
+
+	tests := []struct {
+		dt   elements.DataType
+		mask byte
+	}{
+		{elements.DataTypeVoiceLCHeader, pdu.FLCVoiceLCHeaderParityMask},
+		{elements.DataTypeTerminatorWithLC, pdu.FLCTerminatorWithLCParityMask},
+		{elements.DataTypeCSBK, 0},
+	}
+	for _, tt := range tests {
+		masked := plain
+		pdu.ApplyFLCParityMask(&masked, tt.dt)
+		if [72]bit.Bit(masked[:72]) != [72]bit.Bit(plain[:72]) {
+			t.Errorf("%s: mask changed the information bits", elements.DataTypeToName(tt.dt))
+		}
+		got := bit.PackBits(masked[72:])
+		want := bit.PackBits(plain[72:])
+		for i := range got {
+			if got[i] != want[i]^tt.mask {
+				t.Errorf("%s: parity[%d] = 0x%02X, want 0x%02X", elements.DataTypeToName(tt.dt), i, got[i], want[i]^tt.mask)
+			}
+		}
+		pdu.ApplyFLCParityMask(&masked, tt.dt)
+		if masked != plain {
+			t.Errorf("%s: mask is not its own inverse", elements.DataTypeToName(tt.dt))
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package pdu
 
 import (
+	"github.com/USA-RedDragon/dmrgo/v2/crc"
 	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
 )
 
@@ -10,6 +11,35 @@ type Rate12Data struct {
 	Data     [12]byte          `dmr:"bits:0-95,packed"`
 }
 
+// ETSI TS 102 361-1 - 9.2.7 Rate 1/2 coded confirmed data block
+type Rate12DataConfirmed struct {
+	DataType     elements.DataType `dmr:"-"`
+	SerialNumber uint8             `dmr:"bits:0-6"`
+	CRC          uint16            `dmr:"bits:7-15"`
+	Data         [10]byte          `dmr:"bits:16-95,packed"`
+}
+
 func (rtData *Rate12Data) GetDataType() elements.DataType {
 	return rtData.DataType
 }
+
+// Confirmed reinterprets the block as a confirmed data block.
+func (rtData *Rate12Data) Confirmed() Rate12DataConfirmed {
+	c, _ := DecodeRate12DataConfirmed(EncodeRate12Data(rtData))
+	c.DataType = rtData.DataType
+	return c
+}
+
+func (rtData *Rate12DataConfirmed) GetDataType() elements.DataType {
+	return rtData.DataType
+}
+
+// CalculateCRC returns the CRC-9 for the block's serial number and data.
+func (rtData *Rate12DataConfirmed) CalculateCRC() uint16 {
+	return crc.CalculateDataBlockCRC9(rtData.SerialNumber, rtData.Data[:], crc.CRC9MaskRate12Data)
+}
+
+// CRCValid reports whether the CRC field matches the block contents.
+func (rtData *Rate12DataConfirmed) CRCValid() bool {
+	return rtData.CRC == rtData.CalculateCRC()
+}
@@ -2,6 +2,7 @@
 Code generated by dmrgen.
 
 ETSI TS 102 361-1 - 9.1.7 Rate 1/2 data
+ETSI TS 102 361-1 - 9.2.7 Rate 1/2 coded confirmed data block
 
 DO NOT EDIT.
 */
@@ -33,3 +34,26 @@ func EncodeRate12Data(s *Rate12Data) [96]bit.Bit {
 func (s *Rate12Data) ToString() string {
 	return fmt.Sprintf("Rate12Data{ DataType: %s, Data: %v }", layer2Elements.DataTypeToName(s.DataType), s.Data)
 }
+
+// DecodeRate12DataConfirmed decodes a Rate12DataConfirmed per ETSI TS 102 361-1 - 9.2.7 Rate 1/2 coded confirmed data block
+func DecodeRate12DataConfirmed(data [96]bit.Bit) (Rate12DataConfirmed, fec.FECResult) {
+	var result Rate12DataConfirmed
+	var fecResult fec.FECResult
+	result.SerialNumber = bit.BitsToUint8(data[:], 0, 7)
+	result.CRC = bit.BitsToUint16(data[:], 7, 9)
+	copy(result.Data[:], bit.PackBits(data[16:96]))
+	return result, fecResult
+}
+
+// EncodeRate12DataConfirmed encodes a Rate12DataConfirmed per ETSI TS 102 361-1 - 9.2.7 Rate 1/2 coded confirmed data block
+func EncodeRate12DataConfirmed(s *Rate12DataConfirmed) [96]bit.Bit {
+	var data [96]bit.Bit
+	copy(data[0:7], bit.BitsFromUint8(s.SerialNumber, 7))
+	copy(data[7:16], bit.BitsFromUint16(s.CRC, 9))
+	copy(data[16:96], bit.UnpackBits(s.Data[:]))
+	return data
+}
+
+func (s *Rate12DataConfirmed) ToString() string {
+	return fmt.Sprintf("Rate12DataConfirmed{ DataType: %s, SerialNumber: %d, CRC: %d, Data: %v }", layer2Elements.DataTypeToName(s.DataType), s.SerialNumber, s.CRC, s.Data)
+}
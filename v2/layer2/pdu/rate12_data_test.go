@@ -80,3 +80,53 @@ func TestRate12Data_EncodeDecodeRoundTrip(t *testing.T) {
 		t.Errorf("round-trip failed: got %v, want %v", decoded.Data, original.Data)
 	}
 }
+
+func TestRate12DataConfirmed_EncodeDecodeRoundTrip(t *testing.T) {
+	original := pdu.Rate12DataConfirmed{SerialNumber: 0x55}
+	for i := range original.Data {
+		original.Data[i] = byte(0xA0 + i)
+	}
+	original.CRC = original.CalculateCRC()
+
+	encoded := pdu.EncodeRate12DataConfirmed(&original)
+	decoded, _ := pdu.DecodeRate12DataConfirmed(encoded)
+	if decoded != original {
+		t.Errorf("round-trip failed: got %+v, want %+v", decoded, original)
+	}
+	if !decoded.CRCValid() {
+		t.Error("CRCValid() = false after round-trip")
+	}
+	if decoded.CRC > 0x1FF {
+		t.Errorf("CRC = 0x%X exceeds 9 bits", decoded.CRC)
+	}
+}
+
+func TestRate12DataConfirmed_CRCDetectsCorruption(t *testing.T) {
+	block := pdu.Rate12DataConfirmed{SerialNumber: 3, Data: [10]byte{0x01, 0x02, 0x03}}
+	block.CRC = block.CalculateCRC()
+
+	corrupted := block
+	corrupted.Data[10-1] ^= 0x80
+	if corrupted.CRCValid() {
+		t.Error("CRCValid() = true with corrupted data")
+	}
+	corrupted = block
+	corrupted.SerialNumber = 4
+	if corrupted.CRCValid() {
+		t.Error("CRCValid() = true with wrong serial number")
+	}
+}
+
+func TestRate12Data_Confirmed(t *testing.T) {
+	want := pdu.Rate12DataConfirmed{SerialNumber: 0x7F, Data: [10]byte{0xDE, 0xAD}}
+	want.CRC = want.CalculateCRC()
+	bits := pdu.EncodeRate12DataConfirmed(&want)
+
+	rt, _ := pdu.DecodeRate12Data(bits)
+	rt.DataType = elements.DataTypeRate12
+	got := rt.Confirmed()
+	want.DataType = elements.DataTypeRate12
+	if got != want {
+		t.Errorf("Confirmed() = %+v, want %+v", got, want)
+	}
+}
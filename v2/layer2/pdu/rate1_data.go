@@ -1,15 +1,45 @@
 package pdu
 
 import (
+	"github.com/USA-RedDragon/dmrgo/v2/crc"
 	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
 )
 
 // ETSI TS 102 361-1 - 9.1.7 Rate 1 data
 type Rate1Data struct {
 	DataType elements.DataType `dmr:"-"`
-	Data     [12]byte          `dmr:"bits:0-95,packed"`
+	Data     [24]byte          `dmr:"bits:0-191,packed"`
+}
+
+// ETSI TS 102 361-1 - 9.2.15 Rate 1 coded confirmed data block
+type Rate1DataConfirmed struct {
+	DataType     elements.DataType `dmr:"-"`
+	SerialNumber uint8             `dmr:"bits:0-6"`
+	CRC          uint16            `dmr:"bits:7-15"`
+	Data         [22]byte          `dmr:"bits:16-191,packed"`
 }
 
 func (rtData *Rate1Data) GetDataType() elements.DataType {
 	return rtData.DataType
 }
+
+// Confirmed reinterprets the block as a confirmed data block.
+func (rtData *Rate1Data) Confirmed() Rate1DataConfirmed {
+	c, _ := DecodeRate1DataConfirmed(EncodeRate1Data(rtData))
+	c.DataType = rtData.DataType
+	return c
+}
+
+func (rtData *Rate1DataConfirmed) GetDataType() elements.DataType {
+	return rtData.DataType
+}
+
+// CalculateCRC returns the CRC-9 for the block's serial number and data.
+func (rtData *Rate1DataConfirmed) CalculateCRC() uint16 {
+	return crc.CalculateDataBlockCRC9(rtData.SerialNumber, rtData.Data[:], crc.CRC9MaskRate1Data)
+}
+
+// CRCValid reports whether the CRC field matches the block contents.
+func (rtData *Rate1DataConfirmed) CRCValid() bool {
+	return rtData.CRC == rtData.CalculateCRC()
+}
@@ -2,6 +2,7 @@
 Code generated by dmrgen.
 
 ETSI TS 102 361-1 - 9.1.7 Rate 1 data
+ETSI TS 102 361-1 - 9.2.15 Rate 1 coded confirmed data block
 
 DO NOT EDIT.
 */
@@ -16,20 +17,43 @@ import (
 )
 
 // DecodeRate1Data decodes a Rate1Data per ETSI TS 102 361-1 - 9.1.7 Rate 1 data
-func DecodeRate1Data(data [96]bit.Bit) (Rate1Data, fec.FECResult) {
+func DecodeRate1Data(data [192]bit.Bit) (Rate1Data, fec.FECResult) {
 	var result Rate1Data
 	var fecResult fec.FECResult
-	copy(result.Data[:], bit.PackBits(data[0:96]))
+	copy(result.Data[:], bit.PackBits(data[0:192]))
 	return result, fecResult
 }
 
 // EncodeRate1Data encodes a Rate1Data per ETSI TS 102 361-1 - 9.1.7 Rate 1 data
-func EncodeRate1Data(s *Rate1Data) [96]bit.Bit {
-	var data [96]bit.Bit
-	copy(data[0:96], bit.UnpackBits(s.Data[:]))
+func EncodeRate1Data(s *Rate1Data) [192]bit.Bit {
+	var data [192]bit.Bit
+	copy(data[0:192], bit.UnpackBits(s.Data[:]))
 	return data
 }
 
 func (s *Rate1Data) ToString() string {
 	return fmt.Sprintf("Rate1Data{ DataType: %s, Data: %v }", layer2Elements.DataTypeToName(s.DataType), s.Data)
 }
+
+// DecodeRate1DataConfirmed decodes a Rate1DataConfirmed per ETSI TS 102 361-1 - 9.2.15 Rate 1 coded confirmed data block
+func DecodeRate1DataConfirmed(data [192]bit.Bit) (Rate1DataConfirmed, fec.FECResult) {
+	var result Rate1DataConfirmed
+	var fecResult fec.FECResult
+	result.SerialNumber = bit.BitsToUint8(data[:], 0, 7)
+	result.CRC = bit.BitsToUint16(data[:], 7, 9)
+	copy(result.Data[:], bit.PackBits(data[16:192]))
+	return result, fecResult
+}
+
+// EncodeRate1DataConfirmed encodes a Rate1DataConfirmed per ETSI TS 102 361-1 - 9.2.15 Rate 1 coded confirmed data block
+func EncodeRate1DataConfirmed(s *Rate1DataConfirmed) [192]bit.Bit {
+	var data [192]bit.Bit
+	copy(data[0:7], bit.BitsFromUint8(s.SerialNumber, 7))
+	copy(data[7:16], bit.BitsFromUint16(s.CRC, 9))
+	copy(data[16:192], bit.UnpackBits(s.Data[:]))
+	return data
+}
+
+func (s *Rate1DataConfirmed) ToString() string {
+	return fmt.Sprintf("Rate1DataConfirmed{ DataType: %s, SerialNumber: %d, CRC: %d, Data: %v }", layer2Elements.DataTypeToName(s.DataType), s.SerialNumber, s.CRC, s.Data)
+}
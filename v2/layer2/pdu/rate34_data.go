@@ -1,15 +1,45 @@
 package pdu
 
 import (
+	"github.com/USA-RedDragon/dmrgo/v2/crc"
 	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
 )
 
 // ETSI TS 102 361-1 - 9.1.7 Rate 3/4 data
 type Rate34Data struct {
 	DataType elements.DataType `dmr:"-"`
-	Data     [12]byte          `dmr:"bits:0-95,packed"`
+	Data     [18]byte          `dmr:"bits:0-143,packed"`
+}
+
+// ETSI TS 102 361-1 - 9.2.2 Rate 3/4 coded confirmed data block
+type Rate34DataConfirmed struct {
+	DataType     elements.DataType `dmr:"-"`
+	SerialNumber uint8             `dmr:"bits:0-6"`
+	CRC          uint16            `dmr:"bits:7-15"`
+	Data         [16]byte          `dmr:"bits:16-143,packed"`
 }
 
 func (rtData *Rate34Data) GetDataType() elements.DataType {
 	return rtData.DataType
 }
+
+// Confirmed reinterprets the block as a confirmed data block.
+func (rtData *Rate34Data) Confirmed() Rate34DataConfirmed {
+	c, _ := DecodeRate34DataConfirmed(EncodeRate34Data(rtData))
+	c.DataType = rtData.DataType
+	return c
+}
+
+func (rtData *Rate34DataConfirmed) GetDataType() elements.DataType {
+	return rtData.DataType
+}
+
+// CalculateCRC returns the CRC-9 for the block's serial number and data.
+func (rtData *Rate34DataConfirmed) CalculateCRC() uint16 {
+	return crc.CalculateDataBlockCRC9(rtData.SerialNumber, rtData.Data[:], crc.CRC9MaskRate34Data)
+}
+
+// CRCValid reports whether the CRC field matches the block contents.
+func (rtData *Rate34DataConfirmed) CRCValid() bool {
+	return rtData.CRC == rtData.CalculateCRC()
+}
@@ -2,6 +2,7 @@
 Code generated by dmrgen.
 
 ETSI TS 102 361-1 - 9.1.7 Rate 3/4 data
+ETSI TS 102 361-1 - 9.2.2 Rate 3/4 coded confirmed data block
 
 DO NOT EDIT.
 */
@@ -16,20 +17,43 @@ import (
 )
 
 // DecodeRate34Data decodes a Rate34Data per ETSI TS 102 361-1 - 9.1.7 Rate 3/4 data
-func DecodeRate34Data(data [96]bit.Bit) (Rate34Data, fec.FECResult) {
+func DecodeRate34Data(data [144]bit.Bit) (Rate34Data, fec.FECResult) {
 	var result Rate34Data
 	var fecResult fec.FECResult
-	copy(result.Data[:], bit.PackBits(data[0:96]))
+	copy(result.Data[:], bit.PackBits(data[0:144]))
 	return result, fecResult
 }
 
 // EncodeRate34Data encodes a Rate34Data per ETSI TS 102 361-1 - 9.1.7 Rate 3/4 data
-func EncodeRate34Data(s *Rate34Data) [96]bit.Bit {
-	var data [96]bit.Bit
-	copy(data[0:96], bit.UnpackBits(s.Data[:]))
+func EncodeRate34Data(s *Rate34Data) [144]bit.Bit {
+	var data [144]bit.Bit
+	copy(data[0:144], bit.UnpackBits(s.Data[:]))
 	return data
 }
 
 func (s *Rate34Data) ToString() string {
 	return fmt.Sprintf("Rate34Data{ DataType: %s, Data: %v }", layer2Elements.DataTypeToName(s.DataType), s.Data)
 }
+
+// DecodeRate34DataConfirmed decodes a Rate34DataConfirmed per ETSI TS 102 361-1 - 9.2.2 Rate 3/4 coded confirmed data block
+func DecodeRate34DataConfirmed(data [144]bit.Bit) (Rate34DataConfirmed, fec.FECResult) {
+	var result Rate34DataConfirmed
+	var fecResult fec.FECResult
+	result.SerialNumber = bit.BitsToUint8(data[:], 0, 7)
+	result.CRC = bit.BitsToUint16(data[:], 7, 9)
+	copy(result.Data[:], bit.PackBits(data[16:144]))
+	return result, fecResult
+}
+
+// EncodeRate34DataConfirmed encodes a Rate34DataConfirmed per ETSI TS 102 361-1 - 9.2.2 Rate 3/4 coded confirmed data block
+func EncodeRate34DataConfirmed(s *Rate34DataConfirmed) [144]bit.Bit {
+	var data [144]bit.Bit
+	copy(data[0:7], bit.BitsFromUint8(s.SerialNumber, 7))
+	copy(data[7:16], bit.BitsFromUint16(s.CRC, 9))
+	copy(data[16:144], bit.UnpackBits(s.Data[:]))
+	return data
+}
+
+func (s *Rate34DataConfirmed) ToString() string {
+	return fmt.Sprintf("Rate34DataConfirmed{ DataType: %s, SerialNumber: %d, CRC: %d, Data: %v }", layer2Elements.DataTypeToName(s.DataType), s.SerialNumber, s.CRC, s.Data)
+}
@@ -9,8 +9,8 @@ import (
 )
 
 func TestRate34Data_DecodeFromBits(t *testing.T) {
-	// Build 96 info bits representing known packed data
-	var infoBits [96]bit.Bit
+	// Build 144 info bits representing known packed data
+	var infoBits [144]bit.Bit
 
 	// Set byte 0 = 0xAB: 10101011
 	infoBits[0] = 1
@@ -34,7 +34,7 @@ func TestRate34Data_DecodeFromBits(t *testing.T) {
 		t.Errorf("Data[1] = 0x%02X, want 0xCD", rt.Data[1])
 	}
 	// Remaining bytes should be zero
-	for i := 2; i < 12; i++ {
+	for i := 2; i < 18; i++ {
 		if rt.Data[i] != 0 {
 			t.Errorf("Data[%d] = 0x%02X, want 0x00", i, rt.Data[i])
 		}
@@ -42,7 +42,7 @@ func TestRate34Data_DecodeFromBits(t *testing.T) {
 }
 
 func TestRate34Data_GetDataType(t *testing.T) {
-	rt, _ := pdu.DecodeRate34Data([96]bit.Bit{})
+	rt, _ := pdu.DecodeRate34Data([144]bit.Bit{})
 	rt.DataType = elements.DataTypeRate34
 	if rt.GetDataType() != elements.DataTypeRate34 {
 		t.Errorf("GetDataType() = %d, want DataTypeRate34", rt.GetDataType())
@@ -50,7 +50,7 @@ func TestRate34Data_GetDataType(t *testing.T) {
 }
 
 func TestRate34Data_ToString(t *testing.T) {
-	rt, _ := pdu.DecodeRate34Data([96]bit.Bit{})
+	rt, _ := pdu.DecodeRate34Data([144]bit.Bit{})
 	s := rt.ToString()
 	if s == "" {
 		t.Error("ToString() should not be empty")
@@ -58,12 +58,12 @@ func TestRate34Data_ToString(t *testing.T) {
 }
 
 func TestRate34Data_AllOnes(t *testing.T) {
-	var infoBits [96]bit.Bit
+	var infoBits [144]bit.Bit
 	for i := range infoBits {
 		infoBits[i] = 1
 	}
 	rt, _ := pdu.DecodeRate34Data(infoBits)
-	for i := 0; i < 12; i++ {
+	for i := 0; i < 18; i++ {
 		if rt.Data[i] != 0xFF {
 			t.Errorf("Data[%d] = 0x%02X, want 0xFF", i, rt.Data[i])
 		}
@@ -72,7 +72,7 @@ func TestRate34Data_AllOnes(t *testing.T) {
 
 func TestRate34Data_EncodeDecodeRoundTrip(t *testing.T) {
 	original := pdu.Rate34Data{
-		Data: [12]byte{0xAB, 0xCD, 0xEF, 0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF, 0x01},
+		Data: [18]byte{0xAB, 0xCD, 0xEF, 0x01, 0x23, 0x45, 0x67, 0x89, 0xAB, 0xCD, 0xEF, 0x01, 0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
 	}
 	encoded := pdu.EncodeRate34Data(&original)
 	decoded, _ := pdu.DecodeRate34Data(encoded)
@@ -80,3 +80,53 @@ func TestRate34Data_EncodeDecodeRoundTrip(t *testing.T) {
 		t.Errorf("round-trip failed: got %v, want %v", decoded.Data, original.Data)
 	}
 }
+
+func TestRate34DataConfirmed_EncodeDecodeRoundTrip(t *testing.T) {
+	original := pdu.Rate34DataConfirmed{SerialNumber: 0x55}
+	for i := range original.Data {
+		original.Data[i] = byte(0xA0 + i)
+	}
+	original.CRC = original.CalculateCRC()
+
+	encoded := pdu.EncodeRate34DataConfirmed(&original)
+	decoded, _ := pdu.DecodeRate34DataConfirmed(encoded)
+	if decoded != original {
+		t.Errorf("round-trip failed: got %+v, want %+v", decoded, original)
+	}
+	if !decoded.CRCValid() {
+		t.Error("CRCValid() = false after round-trip")
+	}
+	if decoded.CRC > 0x1FF {
+		t.Errorf("CRC = 0x%X exceeds 9 bits", decoded.CRC)
+	}
+}
+
+func TestRate34DataConfirmed_CRCDetectsCorruption(t *testing.T) {
+	block := pdu.Rate34DataConfirmed{SerialNumber: 3, Data: [16]byte{0x01, 0x02, 0x03}}
+	block.CRC = block.CalculateCRC()
+
+	corrupted := block
+	corrupted.Data[16-1] ^= 0x80
+	if corrupted.CRCValid() {
+		t.Error("CRCValid() = true with corrupted data")
+	}
+	corrupted = block
+	corrupted.SerialNumber = 4
+	if corrupted.CRCValid() {
+		t.Error("CRCValid() = true with wrong serial number")
+	}
+}
+
+func TestRate34Data_Confirmed(t *testing.T) {
+	want := pdu.Rate34DataConfirmed{SerialNumber: 0x7F, Data: [16]byte{0xDE, 0xAD}}
+	want.CRC = want.CalculateCRC()
+	bits := pdu.EncodeRate34DataConfirmed(&want)
+
+	rt, _ := pdu.DecodeRate34Data(bits)
+	rt.DataType = elements.DataTypeRate34
+	got := rt.Confirmed()
+	want.DataType = elements.DataTypeRate34
+	if got != want {
+		t.Errorf("Confirmed() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,152 @@
+package layer2
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/constants"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// ShortData is a short data message reassembled by a ShortDataAssembler.
+// Exactly one of its fields is set.
+type ShortData struct {
+	Defined *DefinedShortData
+	UDT     *UnifiedDataTransport
+}
+
+// ToString returns a string representation of the message.
+func (s *ShortData) ToString() string {
+	switch {
+	case s.Defined != nil:
+		return fmt.Sprintf("ShortData{ Defined: %s }", s.Defined.ToString())
+	case s.UDT != nil:
+		return fmt.Sprintf("ShortData{ UDT: %s }", s.UDT.ToString())
+	}
+	return "ShortData{ }"
+}
+
+// ShortDataAssembler reassembles defined short data (a DD_HEAD followed by
+// its appended blocks) and UDT messages (a UDT_HEAD followed by its
+// appended blocks) received on a single timeslot. Use one assembler per
+// timeslot; the zero value is ready to use.
+//
+// CSBK preambles, idle bursts and other data bursts that are not data
+// blocks are skipped. Any other data header or a voice burst abandons the
+// message in progress.
+type ShortDataAssembler struct {
+	// Timeout bounds the time from the header of a message to its last
+	// appended block. Zero uses constants.TDataTxLmt.
+	Timeout time.Duration
+
+	defined  *pdu.DefinedDataHeader
+	udt      *pdu.UDTHeader
+	expected int
+	received int
+	blocks   []byte
+	started  time.Time
+}
+
+// Reset discards any partially received message.
+func (a *ShortDataAssembler) Reset() {
+	a.defined = nil
+	a.udt = nil
+	a.expected = 0
+	a.received = 0
+	a.blocks = a.blocks[:0]
+}
+
+// AddBurst feeds the next burst received on the slot at time now. It
+// returns the message when the burst completes one.
+//
+// Errors report a message that was dropped: a *MissingBlocksError when it
+// was interrupted or timed out, or an error from DecodeDefinedShortData or
+// DecodeUnifiedDataTransport. The assembler carries on with the next
+// message after an error.
+//
+// Data block bursts that failed to decode should still be passed in so
+// that they count towards the appended blocks.
+func (a *ShortDataAssembler) AddBurst(b *Burst, now time.Time) (*ShortData, error) {
+	expired := a.Expire(now)
+	msg, err := a.add(b, now)
+	if expired != nil {
+		return msg, expired
+	}
+	return msg, err
+}
+
+// Expire drops the message in progress if it has been pending for longer
+// than the timeout at time now, and reports it with a *MissingBlocksError.
+// Call it periodically when no bursts are being received.
+func (a *ShortDataAssembler) Expire(now time.Time) error {
+	if a.expected == 0 {
+		return nil
+	}
+	timeout := a.Timeout
+	if timeout == 0 {
+		timeout = constants.TDataTxLmt
+	}
+	if now.Sub(a.started) <= timeout {
+		return nil
+	}
+	err := &MissingBlocksError{Expected: a.expected, Received: a.received, TimedOut: true}
+	a.Reset()
+	return err
+}
+
+func (a *ShortDataAssembler) add(b *Burst, now time.Time) (*ShortData, error) {
+	if header, ok := b.Data.(*pdu.DataHeader); ok {
+		err := a.abandon()
+		switch {
+		case header.DefinedDataHeader != nil:
+			a.defined = header.DefinedDataHeader
+			a.expected = int(header.DefinedDataHeader.AppendedBlocks)
+		case header.UDTHeader != nil:
+			a.udt = header.UDTHeader
+			a.expected = header.UDTHeader.Blocks()
+		}
+		a.started = now
+		return nil, err
+	}
+	if !b.IsData {
+		return nil, a.abandon()
+	}
+
+	octets, ok := dataBlockOctets(b)
+	if !ok || a.expected == 0 {
+		return nil, nil
+	}
+	a.blocks = append(a.blocks, octets...)
+	a.received++
+	if a.received < a.expected {
+		return nil, nil
+	}
+
+	// The message keeps its data; the block buffer is reused.
+	data := append([]byte(nil), a.blocks...)
+	defined, udt := a.defined, a.udt
+	a.Reset()
+	if defined != nil {
+		d, err := decodeDefinedShortData(defined, data)
+		if err != nil {
+			return nil, err
+		}
+		return &ShortData{Defined: d}, nil
+	}
+	u, err := decodeUnifiedDataTransport(udt, data)
+	if err != nil {
+		return nil, err
+	}
+	return &ShortData{UDT: u}, nil
+}
+
+// abandon drops the message in progress, reporting the blocks that were not
+// received.
+func (a *ShortDataAssembler) abandon() error {
+	if a.expected == 0 {
+		return nil
+	}
+	err := &MissingBlocksError{Expected: a.expected, Received: a.received}
+	a.Reset()
+	return err
+}
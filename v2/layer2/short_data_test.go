@@ -0,0 +1,133 @@
+package layer2_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+)
+
+// feedShortData passes bursts to the assembler 60 ms apart from start.
+func feedShortData(a *layer2.ShortDataAssembler, start time.Time, bursts []*layer2.Burst) ([]*layer2.ShortData, []error) {
+	var msgs []*layer2.ShortData
+	var errs []error
+	for i, b := range bursts {
+		msg, err := a.AddBurst(b, start.Add(time.Duration(i)*60*time.Millisecond))
+		if msg != nil {
+			msgs = append(msgs, msg)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return msgs, errs
+}
+
+// udtBursts returns a UDT text message as received over the air.
+func udtBursts(t *testing.T, text string) []*layer2.Burst {
+	t.Helper()
+	msg := &layer2.UnifiedDataTransport{Source: 1, Destination: 2}
+	if err := msg.SetText(enums.UDTFormat8BitCharacter, text); err != nil {
+		t.Fatal(err)
+	}
+	bursts, err := msg.Bursts(enums.MsSourcedData, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return overAir(t, bursts)
+}
+
+// The Hytera capture is preceded by CSBK preambles, which the assembler
+// skips.
+func TestShortDataAssembler_Capture(t *testing.T) {
+	t.Parallel()
+	var rx []*layer2.Burst
+	for i, raw := range loadBursts(t, "testdata/h-sms.bin") {
+		b, err := layer2.NewBurstFromBytes(raw)
+		if err != nil {
+			t.Fatalf("burst %d: %v", i, err)
+		}
+		rx = append(rx, b)
+	}
+	var a layer2.ShortDataAssembler
+	msgs, errs := feedShortData(&a, time.Unix(0, 0), rx)
+	if len(errs) != 0 || len(msgs) != 1 {
+		t.Fatalf("messages %d, errors %v", len(msgs), errs)
+	}
+	d := msgs[0].Defined
+	if d == nil || msgs[0].UDT != nil {
+		t.Fatalf("message = %s, want defined short data", msgs[0].ToString())
+	}
+	if d.Source != 3191868 || d.Destination != 9990 || d.Format != enums.DDFormatBCD || d.BitLength != 26*8 {
+		t.Errorf("message = %s", d.ToString())
+	}
+}
+
+func TestShortDataAssembler_UDT(t *testing.T) {
+	t.Parallel()
+	var a layer2.ShortDataAssembler
+	msgs, errs := feedShortData(&a, time.Unix(0, 0), udtBursts(t, "0123456789a"))
+	if len(errs) != 0 || len(msgs) != 1 || msgs[0].UDT == nil || msgs[0].Defined != nil {
+		t.Fatalf("messages %v, errors %v", msgs, errs)
+	}
+	if text, err := msgs[0].UDT.Text(); err != nil || text != "0123456789a" {
+		t.Errorf("Text = %q, %v", text, err)
+	}
+}
+
+func TestShortDataAssembler_Failures(t *testing.T) {
+	t.Parallel()
+	packet := udtBursts(t, "0123456789a")
+	voice := &layer2.Burst{SyncPattern: enums.BsSourcedVoice}
+
+	tests := []struct {
+		name    string
+		bursts  []*layer2.Burst
+		missing layer2.MissingBlocksError
+	}{
+		{"interrupted by a new header", packet[:2], layer2.MissingBlocksError{Expected: 2, Received: 1}},
+		{"interrupted by voice", []*layer2.Burst{packet[0], voice}, layer2.MissingBlocksError{Expected: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var a layer2.ShortDataAssembler
+			// A complete message after the failure shows the assembler recovers.
+			msgs, errs := feedShortData(&a, time.Unix(0, 0), append(append([]*layer2.Burst{}, tt.bursts...), packet...))
+			if len(msgs) != 1 {
+				t.Errorf("did not recover: %d messages", len(msgs))
+			}
+			var missing *layer2.MissingBlocksError
+			if len(errs) != 1 || !errors.As(errs[0], &missing) || *missing != tt.missing {
+				t.Errorf("errors = %v, want %#v", errs, tt.missing)
+			}
+		})
+	}
+}
+
+func TestShortDataAssembler_Timeout(t *testing.T) {
+	t.Parallel()
+	packet := udtBursts(t, "0123456789a")
+	start := time.Unix(0, 0)
+
+	a := layer2.ShortDataAssembler{Timeout: time.Second}
+	if _, err := a.AddBurst(packet[0], start); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Expire(start.Add(time.Second)); err != nil {
+		t.Fatalf("expired at the timeout: %v", err)
+	}
+
+	// The blocks arrive too late: the message is reported as timed out and
+	// the stray blocks are ignored.
+	msgs, errs := feedShortData(&a, start.Add(2*time.Second), packet[1:])
+	var missing *layer2.MissingBlocksError
+	if len(msgs) != 0 || len(errs) != 1 || !errors.As(errs[0], &missing) {
+		t.Fatalf("messages %d, errors %v", len(msgs), errs)
+	}
+	if !missing.TimedOut || missing.Expected != 2 || missing.Received != 0 {
+		t.Errorf("error = %+v", missing)
+	}
+}
@@ -2,6 +2,7 @@ package layer2
 
 import (
 	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
 	"github.com/USA-RedDragon/dmrgo/v2/fec"
 	"github.com/USA-RedDragon/dmrgo/v2/fec/bptc"
 	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
@@ -18,16 +19,29 @@ import (
 // The 4 × 17-bit payloads form the BPTC transmit matrix, which is
 // deinterleaved and FEC-decoded to recover the 36-bit Short LC PDU.
 
+// ShortLCFragments is the number of CACH signalling payloads that carry one
+// Short LC PDU.
+const ShortLCFragments = 4
+
 // ShortLCAssembler accumulates 4 CACH signalling payloads and decodes
 // the assembled Short LC PDU.
+//
+// AddFragment appends payloads blindly and suits callers that have already
+// delimited the sequence. AddCACH follows the TACT LCSS of each CACH and
+// suits a continuously running outbound channel: it starts on a first
+// fragment, only accepts continuation and last fragments in sequence, and
+// discards a partial sequence that is broken by a missed, duplicated or
+// corrupted CACH.
 type ShortLCAssembler struct {
-	fragments [4][CACHPayloadBits]bit.Bit
+	fragments [ShortLCFragments][CACHPayloadBits]bit.Bit
 	count     int
+	discarded int
 }
 
 // Reset clears the assembler state for reuse.
 func (a *ShortLCAssembler) Reset() {
 	a.count = 0
+	a.discarded = 0
 }
 
 // Count returns the number of fragments accumulated so far.
@@ -35,24 +49,80 @@ func (a *ShortLCAssembler) Count() int {
 	return a.count
 }
 
+// Discarded returns the number of partial sequences dropped by AddCACH
+// since the last Reset.
+func (a *ShortLCAssembler) Discarded() int {
+	return a.discarded
+}
+
+// AddCACH feeds the next CACH received on the outbound channel to the
+// assembler. A first fragment always starts a new sequence; a continuation
+// or last fragment out of sequence, a single fragment, or a TACT that could
+// not be decoded discards the fragments collected so far. Returns true when
+// the last fragment completes a sequence and the assembler is ready for
+// Complete().
+//
+// A completed sequence is dropped by the next call, so a streaming caller
+// does not need to call Reset() between Short LCs.
+func (a *ShortLCAssembler) AddCACH(c *CACH) bool {
+	if a.count >= ShortLCFragments {
+		a.count = 0
+	}
+	if c.FEC.Uncorrectable {
+		a.discard()
+		return false
+	}
+
+	switch c.TACT.LCSS {
+	case enums.FirstFragmentLC:
+		a.discard()
+	case enums.ContinuationFragmentLCorCSBK:
+		if a.count == 0 || a.count == ShortLCFragments-1 {
+			a.discard()
+			return false
+		}
+	case enums.LastFragmentLCorCSBK:
+		if a.count != ShortLCFragments-1 {
+			a.discard()
+			return false
+		}
+	case enums.SingleFragmentLCorCSBK:
+		a.discard()
+		return false
+	}
+
+	a.fragments[a.count] = c.Payload
+	a.count++
+	return a.count == ShortLCFragments
+}
+
+// discard drops a partially collected sequence.
+func (a *ShortLCAssembler) discard() {
+	if a.count > 0 && a.count < ShortLCFragments {
+		a.discarded++
+	}
+	a.count = 0
+}
+
 // AddFragment appends a 17-bit CACH signalling payload to the assembler.
 // Returns true when 4 fragments have been collected and the assembler
 // is ready for Complete().
 func (a *ShortLCAssembler) AddFragment(payload [CACHPayloadBits]bit.Bit) bool {
-	if a.count >= 4 {
+	if a.count >= ShortLCFragments {
 		// Already full — caller should have called Complete() or Reset()
 		return true
 	}
 	a.fragments[a.count] = payload
 	a.count++
-	return a.count >= 4
+	return a.count >= ShortLCFragments
 }
 
 // Complete decodes the assembled Short LC PDU from the 4 accumulated
 // fragments. Returns the decoded ShortLC and a combined FEC result
 // covering both BPTC FEC and CRC-8 verification.
 //
-// The assembler is NOT automatically reset — call Reset() to reuse.
+// The assembler is NOT automatically reset — call Reset() to reuse, or
+// keep feeding AddCACH.
 func (a *ShortLCAssembler) Complete() (pdu.ShortLC, fec.FECResult) {
 	// Decode BPTC to recover the 36-bit Short LC PDU
 	info, bptcResult := bptc.DecodeCACHBPTC(a.fragments)
@@ -75,7 +145,7 @@ func (a *ShortLCAssembler) Complete() (pdu.ShortLC, fec.FECResult) {
 
 // DecodeShortLCFromFragments is a convenience function that decodes a
 // Short LC PDU directly from 4 × 17-bit CACH signalling payloads.
-func DecodeShortLCFromFragments(fragments [4][CACHPayloadBits]bit.Bit) (pdu.ShortLC, fec.FECResult) {
+func DecodeShortLCFromFragments(fragments [ShortLCFragments][CACHPayloadBits]bit.Bit) (pdu.ShortLC, fec.FECResult) {
 	var a ShortLCAssembler
 	for i := range fragments {
 		a.AddFragment(fragments[i])
@@ -212,3 +212,128 @@ func TestShortLCAssembler_AddFragment_OverflowReturnsReady(t *testing.T) {
 		t.Errorf("count should stay at 4, got %d", a.Count())
 	}
 }
+
+// shortLCCACHs returns the four CACHs carrying slc, with the LCSS sequence
+// first, continuation, continuation, last.
+func shortLCCACHs(slc *pdu.ShortLC) [layer2.ShortLCFragments]layer2.CACH {
+	fragments := bptc.EncodeCACHBPTC(pdu.EncodeShortLC(slc))
+	lcss := [layer2.ShortLCFragments]enums.LCSS{
+		enums.FirstFragmentLC,
+		enums.ContinuationFragmentLCorCSBK,
+		enums.ContinuationFragmentLCorCSBK,
+		enums.LastFragmentLCorCSBK,
+	}
+	var cachs [layer2.ShortLCFragments]layer2.CACH
+	for i := range cachs {
+		cachs[i] = layer2.CACH{TACT: pdu.TACT{LCSS: lcss[i]}, Payload: fragments[i]}
+	}
+	return cachs
+}
+
+func testActivityUpdate(ts1 int) *pdu.ShortLC {
+	return &pdu.ShortLC{
+		SLCO: enums.SLCOActivityUpdate,
+		ActivityUpdate: &pdu.ShortLCActivityUpdate{
+			TS1ActivityID: enums.ActivityID(ts1),
+			HashTS1:       0x5A,
+		},
+	}
+}
+
+func TestShortLCAssembler_AddCACH_Stream(t *testing.T) {
+	t.Parallel()
+	first := shortLCCACHs(testActivityUpdate(8))
+	second := shortLCCACHs(testActivityUpdate(9))
+	corrupt := first[1]
+	corrupt.FEC.Uncorrectable = true
+
+	tests := []struct {
+		name          string
+		stream        []layer2.CACH
+		wantTS1       []int
+		wantDiscarded int
+	}{
+		{
+			name:    "back to back",
+			stream:  append(first[:], second[:]...),
+			wantTS1: []int{8, 9},
+		},
+		{
+			name:          "missed continuation",
+			stream:        []layer2.CACH{first[0], first[1], first[3], second[0], second[1], second[2], second[3]},
+			wantTS1:       []int{9},
+			wantDiscarded: 1,
+		},
+		{
+			name:          "duplicated continuation",
+			stream:        []layer2.CACH{first[0], first[1], first[1], first[2], first[3]},
+			wantDiscarded: 1,
+		},
+		{
+			name:          "restart on first",
+			stream:        []layer2.CACH{first[0], first[1], second[0], second[1], second[2], second[3]},
+			wantTS1:       []int{9},
+			wantDiscarded: 1,
+		},
+		{
+			name:    "joins mid-sequence",
+			stream:  []layer2.CACH{first[2], first[3], second[0], second[1], second[2], second[3]},
+			wantTS1: []int{9},
+		},
+		{
+			name:          "uncorrectable TACT",
+			stream:        []layer2.CACH{first[0], corrupt, first[2], first[3], second[0], second[1], second[2], second[3]},
+			wantTS1:       []int{9},
+			wantDiscarded: 1,
+		},
+		{
+			name:          "single fragment",
+			stream:        []layer2.CACH{first[0], {TACT: pdu.TACT{LCSS: enums.SingleFragmentLCorCSBK}}, first[2], first[3]},
+			wantDiscarded: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var a layer2.ShortLCAssembler
+			var got []int
+			for i := range tt.stream {
+				if !a.AddCACH(&tt.stream[i]) {
+					continue
+				}
+				slc, result := a.Complete()
+				if result.Uncorrectable || slc.ActivityUpdate == nil {
+					t.Fatalf("CACH %d: decoded %+v, FEC %+v", i, slc, result)
+				}
+				got = append(got, int(slc.ActivityUpdate.TS1ActivityID))
+			}
+			if len(got) != len(tt.wantTS1) {
+				t.Fatalf("decoded TS1 activity %v, want %v", got, tt.wantTS1)
+			}
+			for i := range got {
+				if got[i] != tt.wantTS1[i] {
+					t.Errorf("decoded TS1 activity %v, want %v", got, tt.wantTS1)
+				}
+			}
+			if a.Discarded() != tt.wantDiscarded {
+				t.Errorf("Discarded = %d, want %d", a.Discarded(), tt.wantDiscarded)
+			}
+		})
+	}
+}
+
+func TestShortLCAssembler_AddCACH_ResetClearsDiscarded(t *testing.T) {
+	t.Parallel()
+	cachs := shortLCCACHs(testActivityUpdate(1))
+	var a layer2.ShortLCAssembler
+	a.AddCACH(&cachs[0])
+	a.AddCACH(&cachs[0])
+	if a.Discarded() != 1 || a.Count() != 1 {
+		t.Fatalf("Discarded = %d, Count = %d, want 1 and 1", a.Discarded(), a.Count())
+	}
+	a.Reset()
+	if a.Discarded() != 0 || a.Count() != 0 {
+		t.Errorf("after Reset: Discarded = %d, Count = %d", a.Discarded(), a.Count())
+	}
+}
@@ -0,0 +1,269 @@
+package layer2
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/constants"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// ETSI TS 102 361-3 §6.3 / §6.4 — Status/precoded short data
+//
+// A status/precoded message is carried entirely in an SP_HEAD data header
+// with no appended blocks: a 10-bit status or precoded message value and
+// 3-bit source and destination ports. When the header requests a response,
+// the destination answers with a short data confirmed response, a C_RHEAD
+// ACK.
+
+const (
+	// maxStatusPrecoded is the largest 10-bit status/precoded value.
+	maxStatusPrecoded = 0x3FF
+	// maxShortDataPort is the largest 3-bit short data port.
+	maxShortDataPort = 0b111
+)
+
+// StatusMessage is a status/precoded short data message.
+type StatusMessage struct {
+	// SAP is the service access point from the data header.
+	SAP pdu.ServiceAccessPointID
+	// Source and Destination are the LLIDs from the data header.
+	Source      int
+	Destination int
+	// Group reports a message addressed to a talkgroup.
+	Group bool
+	// ResponseRequested asks the destination to acknowledge the message.
+	// Only individual messages are acknowledged.
+	ResponseRequested bool
+	// SourcePort and DestinationPort are the 3-bit short data ports.
+	SourcePort      uint8
+	DestinationPort uint8
+	// Status is the 10-bit status or precoded message value.
+	Status uint16
+}
+
+// ToString returns a string representation of the message.
+func (m *StatusMessage) ToString() string {
+	return fmt.Sprintf("StatusMessage{ SAP: %d, Source: %d, Destination: %d, Group: %t, ResponseRequested: %t, SourcePort: %d, DestinationPort: %d, Status: %d }",
+		m.SAP, m.Source, m.Destination, m.Group, m.ResponseRequested, m.SourcePort, m.DestinationPort, m.Status)
+}
+
+// StatusMessageFromHeader returns the message carried by an SP_HEAD.
+func StatusMessageFromHeader(h *pdu.StatusPrecodedHeader) *StatusMessage {
+	return &StatusMessage{
+		SAP:               pdu.ServiceAccessPointID(h.SAP),
+		Source:            h.LLIDSource,
+		Destination:       h.LLIDDestination,
+		Group:             h.Group,
+		ResponseRequested: h.ResponseRequested,
+		SourcePort:        h.SourcePort,
+		DestinationPort:   h.DestinationPort,
+		Status:            h.StatusPrecoded,
+	}
+}
+
+// Burst returns the SP_HEAD burst that carries the message, sent with the
+// data SYNC syncPattern and colorCode. A status above 1023 or a port above
+// 7 returns an *elements.PDUError wrapping elements.ErrInvalidEncoding.
+func (m *StatusMessage) Burst(syncPattern enums.SyncPattern, colorCode int) (Burst, error) {
+	switch {
+	case m.Status > maxStatusPrecoded:
+		return Burst{}, &elements.PDUError{Layer: elements.LayerPacket, Field: "Status", Err: elements.ErrInvalidEncoding}
+	case m.SourcePort > maxShortDataPort:
+		return Burst{}, &elements.PDUError{Layer: elements.LayerPacket, Field: "SourcePort", Err: elements.ErrInvalidEncoding}
+	case m.DestinationPort > maxShortDataPort:
+		return Burst{}, &elements.PDUError{Layer: elements.LayerPacket, Field: "DestinationPort", Err: elements.ErrInvalidEncoding}
+	}
+	return newDataBurst(syncPattern, colorCode, &pdu.DataHeader{
+		DataType: elements.DataTypeDataHeader,
+		Format:   pdu.FormatShortDataRawOrStatusPrecoded,
+		StatusPrecodedHeader: &pdu.StatusPrecodedHeader{
+			Group:             m.Group,
+			ResponseRequested: m.ResponseRequested,
+			SAP:               uint8(m.SAP),
+			LLIDDestination:   m.Destination,
+			LLIDSource:        m.Source,
+			SourcePort:        m.SourcePort,
+			DestinationPort:   m.DestinationPort,
+			StatusPrecoded:    m.Status,
+		},
+	}), nil
+}
+
+// BuildStatusMessage encodes the burst of a status/precoded message.
+func BuildStatusMessage(m *StatusMessage, syncPattern enums.SyncPattern, colorCode int) ([33]byte, error) {
+	b, err := m.Burst(syncPattern, colorCode)
+	if err != nil {
+		return [33]byte{}, err
+	}
+	return b.Encode()
+}
+
+// StatusEventType identifies a StatusEvent.
+type StatusEventType uint8
+
+const (
+	// StatusReceived reports a status message received on the slot.
+	StatusReceived StatusEventType = iota
+	// StatusAcknowledged reports that the destination of the status
+	// message sent acknowledged it.
+	StatusAcknowledged
+)
+
+// StatusEvent is a status message received, or the acknowledgement of one
+// sent.
+type StatusEvent struct {
+	Type    StatusEventType
+	Message *StatusMessage
+	// Meaning is the meaning of Message.Status from StatusService.Meanings,
+	// or empty for a status not in the table.
+	Meaning string
+	// Response holds the short data confirmed response to send for a
+	// received message that requests one.
+	Response []Burst
+}
+
+// StatusService sends and receives status/precoded messages on a single
+// timeslot. It answers received messages that request a response, and
+// retransmits a message sent with ResponseRequested until it is
+// acknowledged, rejected or the retry limit is reached. The zero value is
+// ready to use.
+type StatusService struct {
+	// SyncPattern and ColorCode are set on every burst sent.
+	SyncPattern enums.SyncPattern
+	ColorCode   int
+	// LLID is the station's own LLID. When non-zero, only messages
+	// addressed to it are answered.
+	LLID int
+	// Meanings maps status values to their meaning, e.g. 12 to
+	// "On scene".
+	Meanings map[uint16]string
+	// RetryLimit is the number of times a message is transmitted before it
+	// is given up. Zero uses constants.NRtryLmt.
+	RetryLimit int
+	// ResponseWait bounds the time from the end of a transmission to its
+	// response. Zero uses constants.TRspnsWait.
+	ResponseWait time.Duration
+
+	pending  *StatusMessage
+	burst    Burst
+	attempts int
+	deadline time.Time
+}
+
+// Meaning returns the meaning of a status value, or empty for a status not
+// in the table.
+func (s *StatusService) Meaning(status uint16) string {
+	return s.Meanings[status]
+}
+
+// Busy reports whether a message sent is waiting to be acknowledged.
+func (s *StatusService) Busy() bool {
+	return s.pending != nil
+}
+
+// Reset abandons the message waiting to be acknowledged.
+func (s *StatusService) Reset() {
+	s.pending = nil
+}
+
+// Send returns the burst of a status message at time now, abandoning any
+// message waiting to be acknowledged. An individual message that requests
+// a response is kept until its acknowledgement. Errors are as for
+// StatusMessage.Burst.
+func (s *StatusService) Send(m *StatusMessage, now time.Time) ([]Burst, error) {
+	b, err := m.Burst(s.SyncPattern, s.ColorCode)
+	if err != nil {
+		return nil, err
+	}
+	s.Reset()
+	if !m.ResponseRequested || m.Group {
+		return []Burst{b}, nil
+	}
+	msg := *m
+	s.pending = &msg
+	s.burst = b
+	s.attempts = 0
+	return s.transmit(now)
+}
+
+// AddBurst feeds a burst received on the slot at time now. It returns a
+// StatusReceived event for a status message, with the response to send
+// when one is requested, and a StatusAcknowledged event when the message
+// sent is acknowledged. A NACK for the message sent returns a *NACKError
+// and ends it. Other bursts return nil.
+func (s *StatusService) AddBurst(b *Burst, _ time.Time) (*StatusEvent, error) {
+	header, ok := b.Data.(*pdu.DataHeader)
+	if !ok {
+		return nil, nil
+	}
+	switch {
+	case header.StatusPrecodedHeader != nil:
+		return s.receive(StatusMessageFromHeader(header.StatusPrecodedHeader)), nil
+	case header.ResponsePacketHeader != nil && s.pending != nil:
+		h := header.ResponsePacketHeader
+		if h.LLIDSource != s.pending.Destination || h.LLIDDestination != s.pending.Source {
+			return nil, nil
+		}
+		msg := s.pending
+		t := enums.DataResponseTypeFromFields(h.ResponseClass, h.ResponseType)
+		switch {
+		case t == enums.DataResponseACK:
+			s.Reset()
+			return &StatusEvent{Type: StatusAcknowledged, Message: msg, Meaning: s.Meaning(msg.Status)}, nil
+		case t.IsNACK():
+			s.Reset()
+			return nil, &NACKError{Type: t}
+		}
+	}
+	return nil, nil
+}
+
+// receive returns the event of a received message.
+func (s *StatusService) receive(m *StatusMessage) *StatusEvent {
+	e := &StatusEvent{Type: StatusReceived, Message: m, Meaning: s.Meaning(m.Status)}
+	if m.ResponseRequested && !m.Group && (s.LLID == 0 || m.Destination == s.LLID) {
+		resp := DataResponse{
+			SAP:         m.SAP,
+			Source:      m.Destination,
+			Destination: m.Source,
+			Type:        enums.DataResponseACK,
+		}
+		e.Response = resp.Bursts(s.SyncPattern, s.ColorCode)
+	}
+	return e
+}
+
+// Poll retransmits the message waiting to be acknowledged if its response
+// has not arrived by time now. Call it periodically while Busy. A message
+// that reaches the retry limit returns an *elements.PDUError wrapping
+// elements.ErrRetryLimit.
+func (s *StatusService) Poll(now time.Time) ([]Burst, error) {
+	if s.pending == nil || !now.After(s.deadline) {
+		return nil, nil
+	}
+	return s.transmit(now)
+}
+
+// transmit returns the burst of the message waiting to be acknowledged and
+// starts the response timer.
+func (s *StatusService) transmit(now time.Time) ([]Burst, error) {
+	limit := s.RetryLimit
+	if limit == 0 {
+		limit = constants.NRtryLmt
+	}
+	if s.attempts >= limit {
+		s.Reset()
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "RetryLimit", Err: elements.ErrRetryLimit}
+	}
+	s.attempts++
+
+	wait := s.ResponseWait
+	if wait == 0 {
+		wait = constants.TRspnsWait
+	}
+	s.deadline = now.Add(burstPeriod + wait)
+	return []Burst{s.burst}, nil
+}
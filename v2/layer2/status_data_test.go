@@ -0,0 +1,184 @@
+package layer2_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+func statusMessage(responseRequested bool) *layer2.StatusMessage {
+	return &layer2.StatusMessage{
+		SAP:               pdu.ServiceAccessPointIDShortData,
+		Source:            3120001,
+		Destination:       3120002,
+		ResponseRequested: responseRequested,
+		SourcePort:        1,
+		DestinationPort:   6,
+		Status:            12,
+	}
+}
+
+func TestStatusMessage_RoundTrip(t *testing.T) {
+	t.Parallel()
+	want := statusMessage(true)
+	want.Status = 0x3FF
+	raw, err := layer2.BuildStatusMessage(want, enums.MsSourcedData, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := layer2.NewBurstFromBytes(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := burstData[*pdu.DataHeader](t, b)
+	if header.Format != pdu.FormatShortDataRawOrStatusPrecoded || header.StatusPrecodedHeader == nil || b.SlotType.ColorCode != 3 {
+		t.Fatalf("burst = %s", b.ToString())
+	}
+	if got := layer2.StatusMessageFromHeader(header.StatusPrecodedHeader); *got != *want {
+		t.Errorf("message = %s, want %s", got.ToString(), want.ToString())
+	}
+}
+
+func TestStatusService_Acknowledged(t *testing.T) {
+	t.Parallel()
+	meanings := map[uint16]string{12: "On scene"}
+	sender := layer2.StatusService{SyncPattern: enums.MsSourcedData, ColorCode: 1, Meanings: meanings}
+	receiver := layer2.StatusService{SyncPattern: enums.MsSourcedData, ColorCode: 1, LLID: 3120002, Meanings: meanings}
+	now := time.Unix(0, 0)
+
+	bursts, err := sender.Send(statusMessage(true), now)
+	if err != nil || len(bursts) != 1 || !sender.Busy() {
+		t.Fatalf("Send: %d bursts, busy %t, err %v", len(bursts), sender.Busy(), err)
+	}
+
+	event, err := receiver.AddBurst(overAir(t, bursts)[0], now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != layer2.StatusReceived || event.Meaning != "On scene" || *event.Message != *statusMessage(true) {
+		t.Fatalf("received %+v", event)
+	}
+	if len(event.Response) != 1 {
+		t.Fatalf("response: %d bursts", len(event.Response))
+	}
+	resp := burstData[*pdu.DataHeader](t, &event.Response[0]).ResponsePacketHeader
+	if resp == nil || resp.LLIDSource != 3120002 || resp.LLIDDestination != 3120001 ||
+		enums.DataResponseTypeFromFields(resp.ResponseClass, resp.ResponseType) != enums.DataResponseACK {
+		t.Fatalf("response = %+v", resp)
+	}
+
+	ack, err := sender.AddBurst(overAir(t, event.Response)[0], now)
+	if err != nil || ack == nil || ack.Type != layer2.StatusAcknowledged || ack.Meaning != "On scene" || ack.Message.Status != 12 {
+		t.Fatalf("acknowledgement %+v, err %v", ack, err)
+	}
+	if sender.Busy() {
+		t.Error("still busy after the ACK")
+	}
+}
+
+func TestStatusService_NoResponse(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(0, 0)
+	tests := []struct {
+		name string
+		msg  *layer2.StatusMessage
+		llid int
+	}{
+		{"not requested", statusMessage(false), 0},
+		{"group", &layer2.StatusMessage{Destination: 91, Group: true, ResponseRequested: true, Status: 3}, 0},
+		{"another station", statusMessage(true), 3120099},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sender := layer2.StatusService{SyncPattern: enums.MsSourcedData}
+			receiver := layer2.StatusService{LLID: tt.llid}
+			bursts, err := sender.Send(tt.msg, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.llid == 0 && sender.Busy() {
+				t.Error("waiting for an acknowledgement that is not requested")
+			}
+			event, err := receiver.AddBurst(overAir(t, bursts)[0], now)
+			if err != nil || event == nil || event.Type != layer2.StatusReceived || event.Response != nil || event.Meaning != "" {
+				t.Errorf("event %+v, err %v", event, err)
+			}
+		})
+	}
+}
+
+func TestStatusService_Retry(t *testing.T) {
+	t.Parallel()
+	now := time.Unix(0, 0)
+
+	t.Run("retry limit", func(t *testing.T) {
+		t.Parallel()
+		s := layer2.StatusService{RetryLimit: 2, ResponseWait: time.Second}
+		first, err := s.Send(statusMessage(true), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if retry, err := s.Poll(now); retry != nil || err != nil {
+			t.Fatalf("Poll before the response wait: %d bursts, err %v", len(retry), err)
+		}
+		retry, err := s.Poll(now.Add(2 * time.Second))
+		if err != nil || len(retry) != 1 || retry[0].Data != first[0].Data {
+			t.Fatalf("retry: %d bursts, err %v", len(retry), err)
+		}
+		_, err = s.Poll(now.Add(4 * time.Second))
+		testutil.AssertPDUError(t, err, elements.ErrRetryLimit, elements.LayerPacket, "RetryLimit")
+		if s.Busy() {
+			t.Error("still busy after the retry limit")
+		}
+	})
+
+	t.Run("NACK", func(t *testing.T) {
+		t.Parallel()
+		var s layer2.StatusService
+		if _, err := s.Send(statusMessage(true), now); err != nil {
+			t.Fatal(err)
+		}
+		// A response to another station is ignored.
+		other := layer2.DataResponse{Source: 3120003, Destination: 3120001, Type: enums.DataResponseACK}
+		if event, err := s.AddBurst(overAir(t, other.Bursts(enums.BsSourcedData, 1))[0], now); event != nil || err != nil || !s.Busy() {
+			t.Fatalf("other response: event %+v, err %v", event, err)
+		}
+		nack := layer2.DataResponse{Source: 3120002, Destination: 3120001, Type: enums.DataResponseNACKUndeliverable}
+		_, err := s.AddBurst(overAir(t, nack.Bursts(enums.BsSourcedData, 1))[0], now)
+		var nackErr *layer2.NACKError
+		if !errors.Is(err, elements.ErrNACK) || !errors.As(err, &nackErr) || nackErr.Type != enums.DataResponseNACKUndeliverable {
+			t.Fatalf("err = %v, want NACK Undeliverable", err)
+		}
+		if s.Busy() {
+			t.Error("still busy after a NACK")
+		}
+	})
+}
+
+func TestStatusMessage_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		msg   layer2.StatusMessage
+		field string
+	}{
+		{"status", layer2.StatusMessage{Status: 1024}, "Status"},
+		{"source port", layer2.StatusMessage{SourcePort: 8}, "SourcePort"},
+		{"destination port", layer2.StatusMessage{DestinationPort: 8}, "DestinationPort"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var s layer2.StatusService
+			_, err := s.Send(&tt.msg, time.Unix(0, 0))
+			testutil.AssertPDUError(t, err, elements.ErrInvalidEncoding, elements.LayerPacket, tt.field)
+		})
+	}
+}
@@ -0,0 +1,523 @@
+package layer2
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/netip"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// TCP/IP header compression (SAP 2)
+//
+// Messages sent with SAP TCP/IP header compression carry Van Jacobson
+// compressed TCP/IP (RFC 1144). The packet type is in the first octet, as
+// on a serial line:
+//
+//   - 0x4x, a regular IPv4 datagram, sent unchanged.
+//   - 0x7x, an uncompressed TCP datagram: the IPv4 version nibble is
+//     replaced with 7 and the protocol field with the connection slot, so
+//     the decompressor can save the header.
+//   - 0x80 and above, a compressed TCP datagram: a change mask, the
+//     connection slot when it differs from the last one, the TCP checksum
+//     and the fields that changed since the last datagram of the
+//     connection, as deltas, followed by the TCP data.
+//
+// Both ends keep a copy of the last header sent on each connection slot. A
+// packet lost on the link leaves the decompressor's copy out of date;
+// after Toss, compressed datagrams are discarded until one names its slot
+// again, and TCP's retransmission resynchronises the slot with an
+// uncompressed datagram.
+
+const (
+	// DefaultTCPIPSlots is the number of connection state slots used when
+	// none is configured.
+	DefaultTCPIPSlots = 16
+	// maxTCPIPSlots is the number of slots an 8-bit slot ID can name.
+	maxTCPIPSlots = 256
+
+	vjTypeUncompressedTCP = 0x70
+	vjTypeCompressedTCP   = 0x80
+
+	// Change mask bits of a compressed datagram.
+	vjNewC     = 0x40
+	vjNewI     = 0x20
+	vjPushBit  = 0x10
+	vjNewS     = 0x08
+	vjNewA     = 0x04
+	vjNewW     = 0x02
+	vjNewU     = 0x01
+	vjSpecials = vjNewS | vjNewA | vjNewW | vjNewU
+	// vjSpecialI marks an echoed interactive datagram: sequence and
+	// acknowledgement numbers both advance by the last data length.
+	vjSpecialI = vjNewS | vjNewW | vjNewU
+	// vjSpecialD marks a unidirectional data datagram: the sequence number
+	// advances by the last data length.
+	vjSpecialD = vjNewS | vjNewA | vjNewW | vjNewU
+
+	ipv4ProtocolTCP = 6
+	tcpHeaderOctets = 20
+	tcpFlagFIN      = 0x01
+	tcpFlagSYN      = 0x02
+	tcpFlagRST      = 0x04
+	tcpFlagPSH      = 0x08
+	tcpFlagACK      = 0x10
+	tcpFlagURG      = 0x20
+)
+
+// tcpConnection is the state of one connection slot in the compressor.
+type tcpConnection struct {
+	slot uint8
+	// key holds the addresses and ports that identify the connection.
+	key [12]byte
+	// header is the IPv4 and TCP header last sent on the slot.
+	header []byte
+}
+
+// TCPIPCompressor compresses the TCP/IP headers of datagrams sent to one
+// peer. Its Slots must match the peer's TCPIPDecompressor. The zero value
+// is ready to use.
+type TCPIPCompressor struct {
+	// Addresses maps the datagram's addresses to the LLIDs of the message.
+	Addresses IPv4AddressContext
+	// Slots is the number of connection state slots, up to 256. Zero uses
+	// DefaultTCPIPSlots.
+	Slots int
+
+	// connections holds the slots in use, most recently used first.
+	connections []*tcpConnection
+	lastSlot    int
+}
+
+// Reset forgets every connection. The decompressor must be reset too.
+func (c *TCPIPCompressor) Reset() {
+	c.connections = nil
+	c.lastSlot = 0
+}
+
+// Compress returns a message for SAP TCP/IP header compression carrying an
+// IPv4 datagram, addressed to the LLIDs of its addresses. A TCP datagram
+// that only acknowledges or carries data on an established connection is
+// compressed; any other datagram is sent as a regular IPv4 datagram.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrInvalidLength for
+// a truncated datagram, or elements.ErrInvalidEncoding for another IP
+// version or an address on none of the context's networks.
+func (c *TCPIPCompressor) Compress(datagram []byte) (*PacketData, error) {
+	if len(datagram) < ipv4HeaderOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "IPv4Header", Err: elements.ErrInvalidLength}
+	}
+	if datagram[0]>>4 != ipv4Version {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Version", Err: elements.ErrInvalidEncoding}
+	}
+	total := int(binary.BigEndian.Uint16(datagram[2:]))
+	if total < ipv4HeaderOctets || total > len(datagram) {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "TotalLength", Err: elements.ErrInvalidLength}
+	}
+	datagram = datagram[:total]
+	_, source, err := c.Addresses.SourceID(netip.AddrFrom4([4]byte(datagram[12:16])))
+	if err != nil {
+		return nil, err
+	}
+	daid, destination, err := c.Addresses.DestinationID(netip.AddrFrom4([4]byte(datagram[16:20])))
+	if err != nil {
+		return nil, err
+	}
+
+	payload, ok := c.compress(datagram)
+	if !ok {
+		payload = bytes.Clone(datagram)
+	}
+	return &PacketData{
+		SAP:         pdu.ServiceAccessPointIDTCPIPHeaderCompression,
+		Source:      source,
+		Destination: destination,
+		Group:       daid == enums.DAIDGroupNetwork,
+		Payload:     payload,
+	}, nil
+}
+
+// compress returns the compressed or uncompressed TCP form of a datagram,
+// or false for a datagram to send as regular IPv4.
+func (c *TCPIPCompressor) compress(d []byte) ([]byte, bool) {
+	ihl := int(d[0]&0x0F) * 4
+	if d[9] != ipv4ProtocolTCP || binary.BigEndian.Uint16(d[6:])&(ipv4FlagMoreFragments|ipv4FragmentOffsetMask) != 0 ||
+		ihl < ipv4HeaderOctets || len(d) < ihl+tcpHeaderOctets {
+		return nil, false
+	}
+	th := d[ihl:]
+	thl := int(th[12]>>4) * 4
+	hlen := ihl + thl
+	if thl < tcpHeaderOctets || len(d) < hlen {
+		return nil, false
+	}
+	if th[13]&(tcpFlagSYN|tcpFlagFIN|tcpFlagRST|tcpFlagACK) != tcpFlagACK {
+		return nil, false
+	}
+
+	conn, found := c.connection(d, ihl)
+	if !found {
+		return c.uncompressed(conn, d, hlen), true
+	}
+	o := conn.header
+	// Another IP or TCP header length moves the TCP header, so compare the
+	// lengths before the fields.
+	if d[0] != o[0] || len(o) != hlen {
+		return c.uncompressed(conn, d, hlen), true
+	}
+	oth := o[ihl:]
+	if d[1] != o[1] || !bytes.Equal(d[6:10], o[6:10]) ||
+		!bytes.Equal(d[ipv4HeaderOctets:ihl], o[ipv4HeaderOctets:ihl]) ||
+		!bytes.Equal(th[tcpHeaderOctets:thl], oth[tcpHeaderOctets:thl]) {
+		return c.uncompressed(conn, d, hlen), true
+	}
+
+	var changes byte
+	deltas := make([]byte, 0, 16)
+	if th[13]&tcpFlagURG != 0 {
+		deltas = appendVJDeltaZ(deltas, binary.BigEndian.Uint16(th[18:]))
+		changes |= vjNewU
+	} else if !bytes.Equal(th[18:20], oth[18:20]) {
+		return c.uncompressed(conn, d, hlen), true
+	}
+	if w := binary.BigEndian.Uint16(th[14:]) - binary.BigEndian.Uint16(oth[14:]); w != 0 {
+		deltas = appendVJDelta(deltas, w)
+		changes |= vjNewW
+	}
+	deltaA := binary.BigEndian.Uint32(th[8:]) - binary.BigEndian.Uint32(oth[8:])
+	if deltaA != 0 {
+		if deltaA > 0xFFFF {
+			return c.uncompressed(conn, d, hlen), true
+		}
+		deltas = appendVJDelta(deltas, uint16(deltaA))
+		changes |= vjNewA
+	}
+	deltaS := binary.BigEndian.Uint32(th[4:]) - binary.BigEndian.Uint32(oth[4:])
+	if deltaS != 0 {
+		if deltaS > 0xFFFF {
+			return c.uncompressed(conn, d, hlen), true
+		}
+		deltas = appendVJDelta(deltas, uint16(deltaS))
+		changes |= vjNewS
+	}
+
+	lastData := uint32(binary.BigEndian.Uint16(o[2:])) - uint32(hlen) //nolint:gosec // hlen <= 120
+	switch changes {
+	case 0:
+		// Only a datagram carrying data after a bare acknowledgement is
+		// sent without changes; anything else is likely a retransmission.
+		if binary.BigEndian.Uint16(d[2:]) == binary.BigEndian.Uint16(o[2:]) || lastData != 0 {
+			return c.uncompressed(conn, d, hlen), true
+		}
+	case vjSpecialI, vjSpecialD:
+		// These masks cannot be sent as they are.
+		return c.uncompressed(conn, d, hlen), true
+	case vjNewS | vjNewA:
+		if deltaS == deltaA && deltaS == lastData {
+			changes = vjSpecialI
+			deltas = deltas[:0]
+		}
+	case vjNewS:
+		if deltaS == lastData {
+			changes = vjSpecialD
+			deltas = deltas[:0]
+		}
+	}
+	if id := binary.BigEndian.Uint16(d[4:]) - binary.BigEndian.Uint16(o[4:]); id != 1 {
+		deltas = appendVJDeltaZ(deltas, id)
+		changes |= vjNewI
+	}
+	if th[13]&tcpFlagPSH != 0 {
+		changes |= vjPushBit
+	}
+	conn.header = bytes.Clone(d[:hlen])
+
+	out := make([]byte, 0, 4+len(deltas)+len(d)-hlen)
+	if c.lastSlot != int(conn.slot) {
+		c.lastSlot = int(conn.slot)
+		out = append(out, vjTypeCompressedTCP|changes|vjNewC, conn.slot)
+	} else {
+		out = append(out, vjTypeCompressedTCP|changes)
+	}
+	out = append(out, th[16], th[17])
+	out = append(out, deltas...)
+	return append(out, d[hlen:]...), true
+}
+
+// uncompressed saves the header of a datagram in its connection slot and
+// returns its uncompressed TCP form.
+func (c *TCPIPCompressor) uncompressed(conn *tcpConnection, d []byte, hlen int) []byte {
+	conn.header = bytes.Clone(d[:hlen])
+	c.lastSlot = int(conn.slot)
+	out := bytes.Clone(d)
+	out[0] |= vjTypeUncompressedTCP
+	out[9] = conn.slot
+	return out
+}
+
+// connection returns the slot of the connection a TCP datagram belongs
+// to, and whether the slot already holds it. A new connection takes a
+// free slot, or the least recently used one.
+func (c *TCPIPCompressor) connection(d []byte, ihl int) (*tcpConnection, bool) {
+	var key [12]byte
+	copy(key[:8], d[12:20])
+	copy(key[8:], d[ihl:ihl+4])
+
+	for i, conn := range c.connections {
+		if conn.key == key {
+			copy(c.connections[1:i+1], c.connections[:i])
+			c.connections[0] = conn
+			return conn, true
+		}
+	}
+
+	slots := c.Slots
+	if slots <= 0 || slots > maxTCPIPSlots {
+		slots = DefaultTCPIPSlots
+	}
+	var conn *tcpConnection
+	if len(c.connections) < slots {
+		conn = &tcpConnection{slot: uint8(len(c.connections))} //nolint:gosec // fewer than maxTCPIPSlots
+		c.connections = append(c.connections, nil)
+	} else {
+		conn = c.connections[len(c.connections)-1]
+	}
+	copy(c.connections[1:], c.connections[:len(c.connections)-1])
+	c.connections[0] = conn
+	conn.key = key
+	return conn, false
+}
+
+// appendVJDelta appends a non-zero delta: one octet below 256, or a zero
+// octet and the delta in two octets.
+func appendVJDelta(out []byte, delta uint16) []byte {
+	if delta >= 256 {
+		return append(out, 0, byte(delta>>8), byte(delta))
+	}
+	return append(out, byte(delta))
+}
+
+// appendVJDeltaZ appends a delta that may be zero.
+func appendVJDeltaZ(out []byte, delta uint16) []byte {
+	if delta == 0 {
+		return append(out, 0, 0, 0)
+	}
+	return appendVJDelta(out, delta)
+}
+
+// TCPIPDecompressor rebuilds the datagrams of messages sent with SAP
+// TCP/IP header compression by one peer. Its Slots must match the peer's
+// TCPIPCompressor. The zero value is ready to use.
+type TCPIPDecompressor struct {
+	// Slots is the number of connection state slots, up to 256. Zero uses
+	// DefaultTCPIPSlots.
+	Slots int
+
+	headers  [][]byte
+	lastSlot int
+	toss     bool
+}
+
+// Reset forgets every connection. The compressor must be reset too.
+func (d *TCPIPDecompressor) Reset() {
+	d.headers = nil
+	d.lastSlot = 0
+	d.toss = false
+}
+
+// Toss reports a message lost on the link, e.g. one dropped by the packet
+// data assembler. Compressed datagrams are discarded until one names its
+// connection slot.
+func (d *TCPIPDecompressor) Toss() {
+	d.toss = true
+}
+
+// Decompress returns the IPv4 datagram carried by a message. A compressed
+// datagram discarded after Toss returns nil.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrDataTypeMismatch
+// for another SAP, or elements.ErrInvalidEncoding for a truncated or
+// malformed datagram, or one naming a slot with no saved header; those
+// also Toss.
+func (d *TCPIPDecompressor) Decompress(msg *PacketData) ([]byte, error) {
+	if msg.SAP != pdu.ServiceAccessPointIDTCPIPHeaderCompression {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "SAP", Err: elements.ErrDataTypeMismatch}
+	}
+	p := msg.Payload
+	switch {
+	case len(p) == 0:
+		return nil, d.bad("Type")
+	case p[0]&vjTypeCompressedTCP != 0:
+		return d.compressed(p)
+	case p[0]&0xF0 == vjTypeUncompressedTCP:
+		return d.uncompressed(p)
+	case p[0]>>4 == ipv4Version:
+		return bytes.Clone(p), nil
+	}
+	return nil, d.bad("Type")
+}
+
+// bad discards the datagram in error and tosses until the next one that
+// names its slot.
+func (d *TCPIPDecompressor) bad(field string) error {
+	d.toss = true
+	return &elements.PDUError{Layer: elements.LayerPacket, Field: field, Err: elements.ErrInvalidEncoding}
+}
+
+// slots returns the number of connection slots.
+func (d *TCPIPDecompressor) slots() int {
+	if d.Slots <= 0 || d.Slots > maxTCPIPSlots {
+		return DefaultTCPIPSlots
+	}
+	return d.Slots
+}
+
+// uncompressed saves the header of an uncompressed TCP datagram and
+// restores it.
+func (d *TCPIPDecompressor) uncompressed(p []byte) ([]byte, error) {
+	out := bytes.Clone(p)
+	out[0] = ipv4Version<<4 | out[0]&0x0F
+	ihl := int(out[0]&0x0F) * 4
+	if ihl < ipv4HeaderOctets || len(out) < ihl+tcpHeaderOctets {
+		return nil, d.bad("TCPIPHeader")
+	}
+	hlen := ihl + int(out[ihl+12]>>4)*4
+	if hlen < ihl+tcpHeaderOctets || len(out) < hlen {
+		return nil, d.bad("TCPIPHeader")
+	}
+	slot := int(out[9])
+	if slot >= d.slots() {
+		return nil, d.bad("ConnectionSlot")
+	}
+	out[9] = ipv4ProtocolTCP
+	if len(d.headers) != d.slots() {
+		d.headers = make([][]byte, d.slots())
+	}
+	d.headers[slot] = bytes.Clone(out[:hlen])
+	d.lastSlot = slot
+	d.toss = false
+	return out, nil
+}
+
+// compressed rebuilds a compressed TCP datagram from the saved header of
+// its slot.
+func (d *TCPIPDecompressor) compressed(p []byte) ([]byte, error) {
+	changes := p[0] &^ vjTypeCompressedTCP
+	i := 1
+	if changes&vjNewC != 0 {
+		if len(p) < 2 {
+			return nil, d.bad("ConnectionSlot")
+		}
+		d.lastSlot = int(p[1])
+		d.toss = false
+		i = 2
+	} else if d.toss {
+		return nil, nil
+	}
+	if d.lastSlot >= len(d.headers) || d.headers[d.lastSlot] == nil {
+		return nil, d.bad("ConnectionSlot")
+	}
+	saved := d.headers[d.lastSlot]
+	h := bytes.Clone(saved)
+	ihl := int(h[0]&0x0F) * 4
+	th := h[ihl:]
+
+	if len(p) < i+2 {
+		return nil, d.bad("TCPChecksum")
+	}
+	th[16], th[17] = p[i], p[i+1]
+	i += 2
+	if changes&vjPushBit != 0 {
+		th[13] |= tcpFlagPSH
+	} else {
+		th[13] &^= tcpFlagPSH
+	}
+
+	lastData := uint32(binary.BigEndian.Uint16(saved[2:])) - uint32(len(saved)) //nolint:gosec // len(saved) <= 120
+	seq := binary.BigEndian.Uint32(th[4:])
+	ack := binary.BigEndian.Uint32(th[8:])
+	var ok bool
+	switch changes & vjSpecials {
+	case vjSpecialI:
+		seq += lastData
+		ack += lastData
+	case vjSpecialD:
+		seq += lastData
+	default:
+		if changes&vjNewU != 0 {
+			var urp uint16
+			if urp, ok = readVJDelta(p, &i); !ok {
+				return nil, d.bad("UrgentPointer")
+			}
+			th[13] |= tcpFlagURG
+			binary.BigEndian.PutUint16(th[18:], urp)
+		} else {
+			th[13] &^= tcpFlagURG
+		}
+		if changes&vjNewW != 0 {
+			var w uint16
+			if w, ok = readVJDelta(p, &i); !ok {
+				return nil, d.bad("Window")
+			}
+			binary.BigEndian.PutUint16(th[14:], binary.BigEndian.Uint16(th[14:])+w)
+		}
+		if changes&vjNewA != 0 {
+			var a uint16
+			if a, ok = readVJDelta(p, &i); !ok {
+				return nil, d.bad("Acknowledgement")
+			}
+			ack += uint32(a)
+		}
+		if changes&vjNewS != 0 {
+			var s uint16
+			if s, ok = readVJDelta(p, &i); !ok {
+				return nil, d.bad("Sequence")
+			}
+			seq += uint32(s)
+		}
+	}
+	binary.BigEndian.PutUint32(th[4:], seq)
+	binary.BigEndian.PutUint32(th[8:], ack)
+
+	id := binary.BigEndian.Uint16(h[4:]) + 1
+	if changes&vjNewI != 0 {
+		var delta uint16
+		if delta, ok = readVJDelta(p, &i); !ok {
+			return nil, d.bad("Identification")
+		}
+		id += delta - 1
+	}
+	binary.BigEndian.PutUint16(h[4:], id)
+
+	data := p[i:]
+	if len(h)+len(data) > maxIPv4Octets {
+		return nil, d.bad("TotalLength")
+	}
+	binary.BigEndian.PutUint16(h[2:], uint16(len(h)+len(data))) //nolint:gosec // checked against maxIPv4Octets
+	h[10], h[11] = 0, 0
+	binary.BigEndian.PutUint16(h[10:], ipChecksum(h[:ihl]))
+	d.headers[d.lastSlot] = h
+
+	out := make([]byte, 0, len(h)+len(data))
+	out = append(out, h...)
+	return append(out, data...), nil
+}
+
+// readVJDelta reads a delta at offset *i and advances it.
+func readVJDelta(p []byte, i *int) (uint16, bool) {
+	if *i >= len(p) {
+		return 0, false
+	}
+	if p[*i] != 0 {
+		*i++
+		return uint16(p[*i-1]), true
+	}
+	if *i+3 > len(p) {
+		return 0, false
+	}
+	v := binary.BigEndian.Uint16(p[*i+1:])
+	*i += 3
+	return v, true
+}
@@ -0,0 +1,349 @@
+package layer2_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/netip"
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// tcpSegment describes a TCP/IPv4 datagram for tcpDatagram.
+type tcpSegment struct {
+	src, dst         string
+	srcPort, dstPort uint16
+	id               uint16
+	seq, ack         uint32
+	flags            byte
+	window, urgent   uint16
+	ipOptions        []byte
+	options          []byte
+	data             string
+}
+
+const (
+	tcpFIN = 0x01
+	tcpSYN = 0x02
+	tcpPSH = 0x08
+	tcpACK = 0x10
+	tcpURG = 0x20
+)
+
+// internetChecksum returns the Internet checksum of the concatenated parts.
+func internetChecksum(parts ...[]byte) uint16 {
+	var sum uint32
+	for _, p := range parts {
+		for i := 0; i < len(p); i += 2 {
+			w := uint32(p[i]) << 8
+			if i+1 < len(p) {
+				w |= uint32(p[i+1])
+			}
+			sum += w
+		}
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xFFFF + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// tcpDatagram returns a TCP/IPv4 datagram with valid checksums.
+func tcpDatagram(s *tcpSegment) []byte {
+	src, dst := netip.MustParseAddr(s.src).As4(), netip.MustParseAddr(s.dst).As4()
+	tcp := binary.BigEndian.AppendUint16(nil, s.srcPort)
+	tcp = binary.BigEndian.AppendUint16(tcp, s.dstPort)
+	tcp = binary.BigEndian.AppendUint32(tcp, s.seq)
+	tcp = binary.BigEndian.AppendUint32(tcp, s.ack)
+	tcp = append(tcp, byte(5+len(s.options)/4)<<4, s.flags)
+	tcp = binary.BigEndian.AppendUint16(tcp, s.window)
+	tcp = append(tcp, 0, 0)
+	tcp = binary.BigEndian.AppendUint16(tcp, s.urgent)
+	tcp = append(tcp, s.options...)
+	tcp = append(tcp, s.data...)
+	pseudo := append(append(append([]byte{}, src[:]...), dst[:]...), 0, 6, byte(len(tcp)>>8), byte(len(tcp)))
+	binary.BigEndian.PutUint16(tcp[16:], internetChecksum(pseudo, tcp))
+
+	ihl := 20 + len(s.ipOptions)
+	ip := []byte{0x40 | byte(ihl/4), 0, byte((ihl + len(tcp)) >> 8), byte(ihl + len(tcp)), byte(s.id >> 8), byte(s.id), 0x40, 0, 64, 6, 0, 0}
+	ip = append(append(ip, src[:]...), dst[:]...)
+	ip = append(ip, s.ipOptions...)
+	binary.BigEndian.PutUint16(ip[10:], internetChecksum(ip))
+	return append(ip, tcp...)
+}
+
+// tcpSession returns a datagram sequence of one connection exercising each
+// kind of compressed header, and the packet type each should be sent as.
+func tcpSession() ([][]byte, []byte) {
+	base := tcpSegment{src: "12.0.0.1", dst: "13.0.0.2", srcPort: 40000, dstPort: 443, window: 8192}
+	seg := func(id uint16, seq, ack uint32, flags byte, f func(*tcpSegment)) []byte {
+		s := base
+		s.id, s.seq, s.ack, s.flags = id, seq, ack, flags
+		if f != nil {
+			f(&s)
+		}
+		return tcpDatagram(&s)
+	}
+	const ip, uncompressed, compressed = 0x40, 0x70, 0x80
+	datagrams := [][]byte{
+		seg(1, 1000, 0, tcpSYN, nil),
+		seg(2, 1001, 5000, tcpACK, nil),
+		// Data after a bare ACK: nothing in the header changes but the length.
+		seg(3, 1001, 5000, tcpACK|tcpPSH, func(s *tcpSegment) { s.data = "GET / HTTP/1.0\r\n" }),
+		// Unidirectional data: the sequence number advances by the last data length.
+		seg(4, 1017, 5000, tcpACK, func(s *tcpSegment) { s.data = "Host: example\r\n" }),
+		// Interactive echo: both numbers advance by the last data length.
+		seg(5, 1032, 5015, tcpACK, func(s *tcpSegment) { s.data = "\r\n" }),
+		// Window, acknowledgement and a large sequence delta.
+		seg(6, 1034+300, 5400, tcpACK, func(s *tcpSegment) { s.window = 4096 }),
+		// An identification jump and urgent data.
+		seg(40, 1334, 5400, tcpACK|tcpURG, func(s *tcpSegment) { s.window = 4096; s.urgent = 1; s.data = "!" }),
+		// Clearing the urgent pointer is sent uncompressed.
+		seg(41, 1335, 5400, tcpACK, func(s *tcpSegment) { s.window = 4096; s.data = "?" }),
+		seg(42, 1336, 5400, tcpACK, func(s *tcpSegment) { s.window = 4096; s.data = "?" }),
+		// A retransmission changes nothing and is sent uncompressed.
+		seg(43, 1336, 5400, tcpACK, func(s *tcpSegment) { s.window = 4096; s.data = "?" }),
+		// A sequence delta over 16 bits.
+		seg(44, 200000, 5400, tcpACK, func(s *tcpSegment) { s.window = 4096 }),
+		// Changed TCP options.
+		seg(45, 200000, 5400, tcpACK, func(s *tcpSegment) { s.window = 4096; s.options = []byte{1, 1, 1, 0} }),
+		seg(46, 200000, 5401, tcpACK, func(s *tcpSegment) { s.window = 4096; s.options = []byte{1, 1, 1, 0} }),
+		seg(47, 200000, 5401, tcpACK|tcpFIN, func(s *tcpSegment) { s.window = 4096 }),
+	}
+	types := []byte{ip, uncompressed, compressed, compressed, compressed, compressed, compressed, uncompressed, compressed, uncompressed, uncompressed, uncompressed, compressed, ip}
+	return datagrams, types
+}
+
+func packetType(b byte) byte {
+	switch {
+	case b&0x80 != 0:
+		return 0x80
+	case b&0xF0 == 0x70:
+		return 0x70
+	default:
+		return b & 0xF0
+	}
+}
+
+func TestTCPIPCompression_Session(t *testing.T) {
+	t.Parallel()
+	var c layer2.TCPIPCompressor
+	var d layer2.TCPIPDecompressor
+	datagrams, types := tcpSession()
+	for i, datagram := range datagrams {
+		msg, err := c.Compress(datagram)
+		if err != nil {
+			t.Fatalf("datagram %d: Compress: %v", i, err)
+		}
+		if msg.SAP != pdu.ServiceAccessPointIDTCPIPHeaderCompression || msg.Source != 1 || msg.Destination != 2 {
+			t.Errorf("datagram %d: %s", i, msg.ToString())
+		}
+		if got := packetType(msg.Payload[0]); got != types[i] {
+			t.Errorf("datagram %d: packet type 0x%02X, want 0x%02X", i, got, types[i])
+		}
+		if header := len(msg.Payload) - (len(datagram) - 40); types[i] == 0x80 && header > 16 {
+			t.Errorf("datagram %d: compressed header of %d octets", i, header)
+		}
+
+		got, err := d.Decompress(msg)
+		if err != nil {
+			t.Fatalf("datagram %d: Decompress: %v", i, err)
+		}
+		if !bytes.Equal(got, datagram) {
+			t.Fatalf("datagram %d:\n got % X\nwant % X", i, got, datagram)
+		}
+	}
+}
+
+func TestTCPIPCompression_Connections(t *testing.T) {
+	t.Parallel()
+	c := layer2.TCPIPCompressor{Slots: 2}
+	d := layer2.TCPIPDecompressor{Slots: 2}
+	conn := func(port uint16, n uint32) []byte {
+		return tcpDatagram(&tcpSegment{
+			src: "12.0.0.1", dst: "12.0.0.2", srcPort: port, dstPort: 4001,
+			id: uint16(n), seq: 100 + n*10, ack: 1, flags: tcpACK, window: 512, data: "0123456789",
+		})
+	}
+	// Three connections share two slots: the third takes the least
+	// recently used slot, and each switch names its slot.
+	sequence := []struct {
+		port    uint16
+		n       uint32
+		slot    int // -1 when the packet does not name a slot
+		pktType byte
+	}{
+		{1, 0, 0, 0x70},
+		{2, 0, 1, 0x70},
+		{1, 1, 0, 0x80},
+		{1, 2, -1, 0x80},
+		{3, 0, 1, 0x70},
+		{1, 3, 0, 0x80},
+		{2, 1, 1, 0x70},
+		{2, 2, -1, 0x80},
+	}
+	for i, s := range sequence {
+		datagram := conn(s.port, s.n)
+		msg, err := c.Compress(datagram)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p := msg.Payload
+		if packetType(p[0]) != s.pktType {
+			t.Errorf("packet %d: type 0x%02X, want 0x%02X", i, packetType(p[0]), s.pktType)
+		}
+		switch {
+		case s.pktType == 0x70 && int(p[9]) != s.slot:
+			t.Errorf("packet %d: uncompressed on slot %d, want %d", i, p[9], s.slot)
+		case s.pktType == 0x80 && s.slot >= 0 && (p[0]&0x40 == 0 || int(p[1]) != s.slot):
+			t.Errorf("packet %d: compressed % X, want slot %d", i, p[:2], s.slot)
+		case s.pktType == 0x80 && s.slot < 0 && p[0]&0x40 != 0:
+			t.Errorf("packet %d: names its slot without a switch", i)
+		}
+		got, err := d.Decompress(msg)
+		if err != nil || !bytes.Equal(got, datagram) {
+			t.Fatalf("packet %d: err %v\n got % X\nwant % X", i, err, got, datagram)
+		}
+	}
+}
+
+// A connection whose IP header grows or shrinks is sent uncompressed until
+// its header length settles.
+func TestTCPIPCompression_HeaderLength(t *testing.T) {
+	t.Parallel()
+	var c layer2.TCPIPCompressor
+	var d layer2.TCPIPDecompressor
+	options := bytes.Repeat([]byte{1}, 28)
+	sequence := []struct {
+		ipOptions []byte
+		pktType   byte
+	}{
+		{nil, 0x70},
+		{options, 0x70},
+		{options, 0x80},
+		{nil, 0x70},
+		{nil, 0x80},
+	}
+	for i, s := range sequence {
+		datagram := tcpDatagram(&tcpSegment{
+			src: "12.0.0.1", dst: "12.0.0.2", srcPort: 1, dstPort: 2, id: uint16(i),
+			seq: 100 + uint32(i)*4, ack: 1, flags: tcpACK, window: 512, ipOptions: s.ipOptions, data: "data",
+		})
+		msg, err := c.Compress(datagram)
+		if err != nil {
+			t.Fatalf("datagram %d: Compress: %v", i, err)
+		}
+		if got := packetType(msg.Payload[0]); got != s.pktType {
+			t.Errorf("datagram %d: packet type 0x%02X, want 0x%02X", i, got, s.pktType)
+		}
+		got, err := d.Decompress(msg)
+		if err != nil || !bytes.Equal(got, datagram) {
+			t.Fatalf("datagram %d: err %v\n got % X\nwant % X", i, err, got, datagram)
+		}
+	}
+}
+
+func TestTCPIPDecompressor_Toss(t *testing.T) {
+	t.Parallel()
+	var c layer2.TCPIPCompressor
+	var d layer2.TCPIPDecompressor
+	seg := func(id uint16, seq uint32) []byte {
+		return tcpDatagram(&tcpSegment{src: "12.0.0.1", dst: "12.0.0.2", srcPort: 1, dstPort: 2, id: id, seq: seq, ack: 1, flags: tcpACK, window: 512, data: "data"})
+	}
+	msgs := make([]*layer2.PacketData, 0, 4)
+	for i, seq := range []uint32{10, 14, 18, 22} {
+		msg, err := c.Compress(seg(uint16(i), seq))
+		if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, msg)
+	}
+	if _, err := d.Decompress(msgs[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// msgs[1] is lost: the packet data assembler reports the error, and
+	// the compressed datagrams after it are discarded.
+	d.Toss()
+	for _, msg := range msgs[2:] {
+		if got, err := d.Decompress(msg); got != nil || err != nil {
+			t.Fatalf("after Toss: % X, %v", got, err)
+		}
+	}
+
+	// TCP retransmits msgs[1]; its sequence number goes backwards, so it is
+	// sent uncompressed and resynchronises the slot.
+	retry := seg(4, 14)
+	msg, err := c.Compress(retry)
+	if err != nil || packetType(msg.Payload[0]) != 0x70 {
+		t.Fatalf("retransmission: % X, %v", msg.Payload[:1], err)
+	}
+	if got, err := d.Decompress(msg); err != nil || !bytes.Equal(got, retry) {
+		t.Fatalf("retransmission: err %v", err)
+	}
+	next := seg(5, 18)
+	msg, err = c.Compress(next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := d.Decompress(msg); err != nil || !bytes.Equal(got, next) {
+		t.Fatalf("after resynchronising: err %v\n got % X\nwant % X", err, got, next)
+	}
+}
+
+func TestTCPIPCompression_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("compress", func(t *testing.T) {
+		t.Parallel()
+		var c layer2.TCPIPCompressor
+		good := tcpDatagram(&tcpSegment{src: "12.0.0.1", dst: "12.0.0.2", flags: tcpACK})
+		_, err := c.Compress(good[:19])
+		testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "IPv4Header")
+		ipv6 := bytes.Clone(good)
+		ipv6[0] = 0x60
+		_, err = c.Compress(ipv6)
+		testutil.AssertPDUError(t, err, elements.ErrInvalidEncoding, elements.LayerPacket, "Version")
+		_, err = c.Compress(good[:30])
+		testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "TotalLength")
+		foreign := tcpDatagram(&tcpSegment{src: "10.0.0.1", dst: "12.0.0.2", flags: tcpACK})
+		_, err = c.Compress(foreign)
+		testutil.AssertPDUError(t, err, elements.ErrInvalidEncoding, elements.LayerPacket, "SourceAddress")
+	})
+
+	t.Run("decompress", func(t *testing.T) {
+		t.Parallel()
+		tests := []struct {
+			name    string
+			sap     pdu.ServiceAccessPointID
+			payload []byte
+			err     error
+			field   string
+		}{
+			{"SAP", pdu.ServiceAccessPointIDIPBasedPacketData, []byte{0x45}, elements.ErrDataTypeMismatch, "SAP"},
+			{"empty", pdu.ServiceAccessPointIDTCPIPHeaderCompression, nil, elements.ErrInvalidEncoding, "Type"},
+			{"type", pdu.ServiceAccessPointIDTCPIPHeaderCompression, []byte{0x60}, elements.ErrInvalidEncoding, "Type"},
+			{"unknown slot", pdu.ServiceAccessPointIDTCPIPHeaderCompression, []byte{0xC0, 3, 0, 0}, elements.ErrInvalidEncoding, "ConnectionSlot"},
+			{"short uncompressed", pdu.ServiceAccessPointIDTCPIPHeaderCompression, []byte{0x75, 0, 0}, elements.ErrInvalidEncoding, "TCPIPHeader"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Parallel()
+				var d layer2.TCPIPDecompressor
+				_, err := d.Decompress(&layer2.PacketData{SAP: tt.sap, Payload: tt.payload})
+				testutil.AssertPDUError(t, err, tt.err, elements.LayerPacket, tt.field)
+			})
+		}
+
+		// A slot beyond the configured number.
+		d := layer2.TCPIPDecompressor{Slots: 2}
+		uncompressed := tcpDatagram(&tcpSegment{src: "12.0.0.1", dst: "12.0.0.2", flags: tcpACK})
+		uncompressed[0] |= 0x70
+		uncompressed[9] = 2
+		_, err := d.Decompress(&layer2.PacketData{SAP: pdu.ServiceAccessPointIDTCPIPHeaderCompression, Payload: uncompressed})
+		testutil.AssertPDUError(t, err, elements.ErrInvalidEncoding, elements.LayerPacket, "ConnectionSlot")
+	})
+}
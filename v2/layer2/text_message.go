@@ -0,0 +1,270 @@
+package layer2
+
+import (
+	"encoding/binary"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/utf16text"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// Standard text messaging
+//
+// Text messages between radios of different manufacturers are sent as
+// defined short data, with the character set given by the DD format, or on
+// trunked systems as UDT, with the character set given by the UDT format.
+// Radios that send text as packet data use a UDP datagram to the Text
+// Message port, 5016, with UTF-16LE text. TextMessage gives all three the
+// same form; UDT character formats are reported as the DD format that
+// encodes the same characters.
+//
+// Raw short data (R_HEAD) carries application octets with no character
+// set, so it is not decoded as text.
+
+// Text Message port layout, as sent by the radio in testdata/d-sms.bin: a
+// 16-bit length in UTF-16 code units of the rest of the payload, a 16-bit
+// header word, then the UTF-16LE text and a NUL terminator. The header
+// word is 0x000A in every message seen and is not interpreted.
+const (
+	ipTextLengthOctets = 2
+	ipTextHeaderOctets = 2
+	ipTextHeader       = 0x000A
+	ipTextMaxUnits     = 0xFFFF
+)
+
+// compactTextFormats are the character formats CompactTextFormat chooses
+// from, in order of preference when two encode text in the same number of
+// bits.
+//
+//nolint:gochecknoglobals
+var compactTextFormats = [...]enums.DDFormat{
+	enums.DDFormat7BitCharacter,
+	enums.DDFormatISO8859_1,
+	enums.DDFormatISO8859_2,
+	enums.DDFormatISO8859_3,
+	enums.DDFormatISO8859_4,
+	enums.DDFormatISO8859_5,
+	enums.DDFormatISO8859_6,
+	enums.DDFormatISO8859_7,
+	enums.DDFormatISO8859_8,
+	enums.DDFormatISO8859_9,
+	enums.DDFormatISO8859_10,
+	enums.DDFormatISO8859_11,
+	enums.DDFormatISO8859_13,
+	enums.DDFormatISO8859_14,
+	enums.DDFormatISO8859_15,
+	enums.DDFormatISO8859_16,
+	enums.DDFormatUTF8,
+	enums.DDFormatUTF16,
+}
+
+// udtTextFormats are the UDT character formats, smallest characters first.
+//
+//nolint:gochecknoglobals
+var udtTextFormats = [...]enums.UDTFormat{
+	enums.UDTFormat7BitCharacter,
+	enums.UDTFormat8BitCharacter,
+	enums.UDTFormatUnicode,
+}
+
+// TextMessage is a text message sent as defined short data or UDT.
+type TextMessage struct {
+	// Source and Destination are the LLIDs of the sender and of the radio
+	// or talkgroup it is sent to.
+	Source      int
+	Destination int
+	// Group reports a message addressed to a talkgroup.
+	Group bool
+	// ResponseRequested asks the destination to acknowledge the message.
+	ResponseRequested bool
+	// Format is the character set of Text: a 7-bit, ISO/IEC 8859 or
+	// Unicode DD format.
+	Format enums.DDFormat
+	Text   string
+}
+
+// NewTextMessage returns a text message in the character set that encodes
+// it most compactly, from CompactTextFormat.
+func NewTextMessage(source, destination int, group bool, text string) *TextMessage {
+	return &TextMessage{
+		Source:      source,
+		Destination: destination,
+		Group:       group,
+		Format:      CompactTextFormat(text),
+		Text:        text,
+	}
+}
+
+// ToString returns a string representation of the message.
+func (m *TextMessage) ToString() string {
+	return fmt.Sprintf("TextMessage{ Source: %d, Destination: %d, Group: %t, ResponseRequested: %t, Format: %s, Text: %q }",
+		m.Source, m.Destination, m.Group, m.ResponseRequested, enums.DDFormatToName(m.Format), m.Text)
+}
+
+// CompactTextFormat returns the DD format that encodes text in the fewest
+// bits: 7-bit characters for ASCII, then the first part of ISO/IEC 8859
+// that holds every character, then the shorter of UTF-8 and UTF-16.
+// Text that is not valid UTF-8 returns DDFormatUTF8, which rejects it.
+func CompactTextFormat(text string) enums.DDFormat {
+	best, bestBits := enums.DDFormatUTF8, -1
+	for _, f := range compactTextFormats {
+		if _, bits, err := encodeDDText(f, text); err == nil && (bestBits < 0 || bits < bestBits) {
+			best, bestBits = f, bits
+		}
+	}
+	return best
+}
+
+// TextMessage returns the text message carried by defined short data.
+// Errors are as for DefinedShortData.Text.
+func (d *DefinedShortData) TextMessage() (*TextMessage, error) {
+	text, err := d.Text()
+	if err != nil {
+		return nil, err
+	}
+	return &TextMessage{
+		Source:            d.Source,
+		Destination:       d.Destination,
+		Group:             d.Group,
+		ResponseRequested: d.ResponseRequested,
+		Format:            d.Format,
+		Text:              text,
+	}, nil
+}
+
+// TextMessage returns the text message carried by a UDT message, with the
+// DD format of its character format. Errors are as for
+// UnifiedDataTransport.Text.
+func (u *UnifiedDataTransport) TextMessage() (*TextMessage, error) {
+	text, err := u.Text()
+	if err != nil {
+		return nil, err
+	}
+	f, _ := u.Format.DDFormat()
+	return &TextMessage{
+		Source:            u.Source,
+		Destination:       u.Destination,
+		Group:             u.Group,
+		ResponseRequested: u.ResponseRequested,
+		Format:            f,
+		Text:              text,
+	}, nil
+}
+
+// TextMessage returns the text message carried by a reassembled message.
+// Errors are as for DefinedShortData.Text or UnifiedDataTransport.Text.
+func (s *ShortData) TextMessage() (*TextMessage, error) {
+	switch {
+	case s.Defined != nil:
+		return s.Defined.TextMessage()
+	case s.UDT != nil:
+		return s.UDT.TextMessage()
+	}
+	return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "ShortData", Err: elements.ErrInvalidLength}
+}
+
+// DecodeTextMessageDatagram returns the text message carried by a UDP/IPv4
+// datagram to the Text Message port, taking the radio IDs from its
+// addresses on the networks of addresses. Format is DDFormatUTF16LE.
+//
+// Errors are an *elements.PDUError: as for
+// IPv4AddressContext.DecodeRadioDatagram, or wrapping
+// elements.ErrInvalidLength for a truncated message.
+func DecodeTextMessageDatagram(datagram []byte, addresses *IPv4AddressContext) (*TextMessage, error) {
+	d, err := addresses.DecodeRadioDatagram(datagram, enums.DPIDToPort(enums.DPIDTextMessage))
+	if err != nil {
+		return nil, err
+	}
+	if len(d.Payload) < ipTextLengthOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	n := 2 * int(binary.BigEndian.Uint16(d.Payload))
+	if n < ipTextHeaderOctets || ipTextLengthOctets+n > len(d.Payload) {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	data := d.Payload[ipTextLengthOctets+ipTextHeaderOctets : ipTextLengthOctets+n]
+	// The length counts whole code units, so the text always decodes.
+	text, _ := utf16text.DecodeTerminated(data, binary.LittleEndian)
+	return &TextMessage{
+		Source:      d.Source,
+		Destination: d.Destination,
+		Group:       d.Group,
+		Format:      enums.DDFormatUTF16LE,
+		Text:        text,
+	}, nil
+}
+
+// Datagram returns the UDP/IPv4 datagram that carries the message from
+// Source to Destination on the networks of addresses, between Text Message
+// ports, with the IPv4 identification given. The text is sent as UTF-16LE
+// whatever Format is, and ResponseRequested is not carried.
+//
+// Text that is not valid UTF-8 returns an *elements.PDUError wrapping
+// elements.ErrInvalidEncoding, and text too long for the message one
+// wrapping elements.ErrInvalidLength; other errors are as for
+// IPv4AddressContext.EncodeRadioDatagram.
+func (m *TextMessage) Datagram(addresses *IPv4AddressContext, identification uint16) ([]byte, error) {
+	if !utf8.ValidString(m.Text) {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Text", Err: elements.ErrInvalidEncoding}
+	}
+	payload := make([]byte, ipTextLengthOctets, ipTextLengthOctets+ipTextHeaderOctets+2*len(m.Text)+2)
+	payload = binary.BigEndian.AppendUint16(payload, ipTextHeader)
+	payload = utf16text.Append(payload, m.Text, binary.LittleEndian)
+	payload = append(payload, 0, 0)
+	n := (len(payload) - ipTextLengthOctets) / 2
+	if n > ipTextMaxUnits {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Text", Err: elements.ErrInvalidLength}
+	}
+	binary.BigEndian.PutUint16(payload, uint16(n)) //nolint:gosec // checked against ipTextMaxUnits
+	d := RadioDatagram{Source: m.Source, Destination: m.Destination, Group: m.Group, Payload: payload}
+	return addresses.EncodeRadioDatagram(&d, enums.DPIDToPort(enums.DPIDTextMessage), identification)
+}
+
+// DefinedShortData returns the defined short data that carries the
+// message, a full message with SAP ShortData. Errors are as for
+// DefinedShortData.SetText.
+func (m *TextMessage) DefinedShortData() (*DefinedShortData, error) {
+	d := &DefinedShortData{
+		SAP:               pdu.ServiceAccessPointIDShortData,
+		Source:            m.Source,
+		Destination:       m.Destination,
+		Group:             m.Group,
+		ResponseRequested: m.ResponseRequested,
+		FullMessage:       true,
+	}
+	if err := d.SetText(m.Format, m.Text); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// UnifiedDataTransport returns the UDT message that carries the message.
+// Format is kept when a UDT character format encodes it; otherwise the
+// smallest UDT character format that holds the text is used. Errors are
+// as for UnifiedDataTransport.SetText.
+func (m *TextMessage) UnifiedDataTransport() (*UnifiedDataTransport, error) {
+	u := &UnifiedDataTransport{
+		Source:            m.Source,
+		Destination:       m.Destination,
+		Group:             m.Group,
+		ResponseRequested: m.ResponseRequested,
+	}
+	for _, f := range udtTextFormats {
+		if dd, _ := f.DDFormat(); dd == m.Format {
+			if err := u.SetText(f, m.Text); err != nil {
+				return nil, err
+			}
+			return u, nil
+		}
+	}
+	var err error
+	for _, f := range udtTextFormats {
+		if err = u.SetText(f, m.Text); err == nil {
+			return u, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,229 @@
+package layer2_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+)
+
+func TestCompactTextFormat(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		text string
+		want enums.DDFormat
+	}{
+		{"", enums.DDFormat7BitCharacter},
+		{"HELLO", enums.DDFormat7BitCharacter},
+		{"café", enums.DDFormatISO8859_1},
+		{"Привет", enums.DDFormatISO8859_5},
+		{"Łódź", enums.DDFormatISO8859_2},
+		// İ and ğ are also in part 3, which is preferred; ÿ is not.
+		{"İğ", enums.DDFormatISO8859_3},
+		{"İğÿ", enums.DDFormatISO8859_9},
+		{"Γειά", enums.DDFormatISO8859_7},
+		{"สวัสดี", enums.DDFormatISO8859_11},
+		// The euro sign is also in part 7, which lacks Š.
+		{"5€Š", enums.DDFormatISO8859_15},
+		{"Șț", enums.DDFormatISO8859_16},
+		// Cyrillic and Turkish share no part of ISO/IEC 8859; the spaces
+		// make UTF-8 shorter than UTF-16.
+		{"Привет İğ", enums.DDFormatUTF8},
+		{"日本語", enums.DDFormatUTF16},
+		// Four octets in either; UTF-8 is preferred on a tie.
+		{"📡", enums.DDFormatUTF8},
+		{"\xff", enums.DDFormatUTF8},
+	}
+	for _, tt := range tests {
+		if got := layer2.CompactTextFormat(tt.text); got != tt.want {
+			t.Errorf("CompactTextFormat(%q) = %s, want %s", tt.text, enums.DDFormatToName(got), enums.DDFormatToName(tt.want))
+		}
+	}
+}
+
+func TestTextMessage_RoundTrip(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		msg  *layer2.TextMessage
+		// udtFormat is the DD format of the text received over UDT.
+		udtFormat enums.DDFormat
+	}{
+		{"7-bit", layer2.NewTextMessage(3120001, 3120002, false, "QSL?"), enums.DDFormat7BitCharacter},
+		{"ISO 8859-1", layer2.NewTextMessage(3120001, 91, true, "Grüße"), enums.DDFormatISO8859_1},
+		{"ISO 8859-5", layer2.NewTextMessage(3120001, 3120002, false, "Привет"), enums.DDFormatUTF16BE},
+		{"UTF-16", layer2.NewTextMessage(3120001, 3120002, false, "日本語"), enums.DDFormatUTF16BE},
+		{"response requested", &layer2.TextMessage{
+			Source: 1, Destination: 2, ResponseRequested: true, Format: enums.DDFormatUTF16BE, Text: "ok",
+		}, enums.DDFormatUTF16BE},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d, err := tt.msg.DefinedShortData()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bursts, err := d.Bursts(enums.MsSourcedData, 1, elements.DataTypeRate12)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := receiveText(t, bursts)
+			if !reflect.DeepEqual(got, tt.msg) {
+				t.Errorf("DD: got %s\nwant %s", got.ToString(), tt.msg.ToString())
+			}
+
+			u, err := tt.msg.UnifiedDataTransport()
+			if err != nil {
+				t.Fatal(err)
+			}
+			bursts, err = u.Bursts(enums.MsSourcedData, 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := *tt.msg
+			want.Format = tt.udtFormat
+			if got := receiveText(t, bursts); !reflect.DeepEqual(got, &want) {
+				t.Errorf("UDT: got %s\nwant %s", got.ToString(), want.ToString())
+			}
+		})
+	}
+}
+
+// receiveText passes bursts over the air to a ShortDataAssembler and
+// returns the text message they carry.
+func receiveText(t *testing.T, bursts []layer2.Burst) *layer2.TextMessage {
+	t.Helper()
+	var a layer2.ShortDataAssembler
+	msgs, errs := feedShortData(&a, time.Unix(0, 0), overAir(t, bursts))
+	if len(errs) != 0 || len(msgs) != 1 {
+		t.Fatalf("messages %d, errors %v", len(msgs), errs)
+	}
+	m, err := msgs[0].TextMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestTextMessage_Errors(t *testing.T) {
+	t.Parallel()
+	_, err := layer2.NewTextMessage(1, 2, false, "\xff").DefinedShortData()
+	if !errors.Is(err, elements.ErrInvalidEncoding) {
+		t.Errorf("DefinedShortData: err = %v", err)
+	}
+	_, err = layer2.NewTextMessage(1, 2, false, "\xff").UnifiedDataTransport()
+	if !errors.Is(err, elements.ErrInvalidEncoding) {
+		t.Errorf("UnifiedDataTransport: err = %v", err)
+	}
+
+	var empty layer2.ShortData
+	_, err = empty.TextMessage()
+	testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "ShortData")
+
+	digits := &layer2.DefinedShortData{Source: 1, Destination: 2}
+	if err := digits.SetDigits("911"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = (&layer2.ShortData{Defined: digits}).TextMessage()
+	testutil.AssertPDUError(t, err, elements.ErrInvalidEncoding, elements.LayerPacket, "Format")
+
+	long := layer2.NewTextMessage(1, 2, false, strings.Repeat("x", 60))
+	u, err := long.UnifiedDataTransport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = u.Bursts(enums.MsSourcedData, 1)
+	testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "Data")
+}
+
+// captureIPv4Datagram returns the IPv4 datagram reassembled from a capture.
+func captureIPv4Datagram(t *testing.T, file string) []byte {
+	t.Helper()
+	var a layer2.UnconfirmedDataAssembler
+	for i, raw := range loadBursts(t, file) {
+		burst, err := layer2.NewBurstFromBytes(raw)
+		if err != nil {
+			t.Fatalf("%s burst %d: %v", file, i, err)
+		}
+		msg, err := a.AddBurst(burst, time.Unix(0, 0))
+		if err != nil {
+			t.Fatalf("%s burst %d: %v", file, i, err)
+		}
+		if msg != nil {
+			return msg.Payload
+		}
+	}
+	t.Fatalf("%s: no message reassembled", file)
+	return nil
+}
+
+func TestDecodeTextMessageDatagram_Capture(t *testing.T) {
+	t.Parallel()
+	datagram := captureIPv4Datagram(t, "testdata/d-sms.bin")
+	var addresses layer2.IPv4AddressContext
+
+	m, err := layer2.DecodeTextMessageDatagram(datagram, &addresses)
+	if err != nil {
+		t.Fatalf("DecodeTextMessageDatagram: %v", err)
+	}
+	want := &layer2.TextMessage{
+		Source:      3191868,
+		Destination: 0x7A3906,
+		Format:      enums.DDFormatUTF16LE,
+		Text:        "TEST KI5VMF",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("DecodeTextMessageDatagram = %s\nwant %s", m.ToString(), want.ToString())
+	}
+
+	got, err := m.Datagram(&addresses, 0)
+	if err != nil {
+		t.Fatalf("Datagram: %v", err)
+	}
+	// The radio sent a TTL of 1, so only the UDP datagram is compared.
+	if string(got[20:]) != string(datagram[20:]) {
+		t.Errorf("Datagram = % X\nwant       % X", got[20:], datagram[20:])
+	}
+}
+
+func TestDecodeTextMessageDatagram_Errors(t *testing.T) {
+	t.Parallel()
+	var addresses layer2.IPv4AddressContext
+	datagram := func(payload []byte) []byte {
+		d := layer2.RadioDatagram{Source: 1, Destination: 2, Payload: payload}
+		b, err := addresses.EncodeRadioDatagram(&d, 5016, 0)
+		if err != nil {
+			t.Fatalf("EncodeRadioDatagram: %v", err)
+		}
+		return b
+	}
+	tests := []struct {
+		name     string
+		datagram []byte
+		sentinel error
+		field    string
+	}{
+		{"empty", datagram(nil), elements.ErrInvalidLength, "Length"},
+		{"no header", datagram([]byte{0x00, 0x00}), elements.ErrInvalidLength, "Length"},
+		{"truncated", datagram([]byte{0x00, 0x03, 0x00, 0x0A, 0x41, 0x00}), elements.ErrInvalidLength, "Length"},
+	}
+	for _, tt := range tests {
+		_, err := layer2.DecodeTextMessageDatagram(tt.datagram, &addresses)
+		testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerApplication, tt.field)
+	}
+
+	d := layer2.RadioDatagram{Source: 1, Destination: 2, Payload: []byte{0x00, 0x01, 0x00, 0x0A}}
+	other, err := addresses.EncodeRadioDatagram(&d, 4007, 0)
+	if err != nil {
+		t.Fatalf("EncodeRadioDatagram: %v", err)
+	}
+	_, err = layer2.DecodeTextMessageDatagram(other, &addresses)
+	testutil.AssertPDUError(t, err, elements.ErrDataTypeMismatch, elements.LayerApplication, "Port")
+}
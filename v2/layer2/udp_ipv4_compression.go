@@ -0,0 +1,182 @@
+package layer2
+
+import (
+	"net/netip"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// ETSI TS 102 361-3 §5.6 — UDP/IPv4 header compression
+//
+// A message sent with SAP UDP/IP header compression starts with a 5 octet
+// compressed header in place of the IPv4 and UDP headers. A SPID or DPID
+// of 0 sends its port in an extended header of 2 octets after it, the
+// source port first. The other IPv4 and UDP fields are rebuilt: the
+// addresses from the SAID, DAID and data header LLIDs, the lengths from
+// the message length and the checksums from the rebuilt datagram.
+
+const (
+	// udpIPv4CompressedOctets is the size of a compressed header without
+	// extended headers.
+	udpIPv4CompressedOctets = 5
+	// udpIPv4ExtendedOctets is the size of an extended header.
+	udpIPv4ExtendedOctets = 2
+	// udpIPv4Opcode is the Header Compression Opcode of UDP/IPv4.
+	udpIPv4Opcode = 0
+	// maxIPv4Octets is the largest IPv4 total length.
+	maxIPv4Octets = 0xFFFF
+)
+
+// DecompressUDPIPv4 rebuilds the IPv4 datagram of a message sent with SAP
+// UDP/IP header compression, using the context to address the source and
+// destination LLIDs of the data header.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrDataTypeMismatch
+// for another SAP, elements.ErrInvalidLength for a truncated header, or
+// elements.ErrNotImplemented for a header compression opcode, SPID, DPID,
+// SAID or DAID with no mapping.
+func (c *IPv4AddressContext) DecompressUDPIPv4(msg *PacketData) ([]byte, error) {
+	if msg.SAP != pdu.ServiceAccessPointIDUDPIPHeaderCompression {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "SAP", Err: elements.ErrDataTypeMismatch}
+	}
+	if len(msg.Payload) < udpIPv4CompressedOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "UDPIPv4CompressedHeader", Err: elements.ErrInvalidLength}
+	}
+	var raw [9]byte
+	copy(raw[:], msg.Payload)
+	var bits [72]bit.Bit
+	copy(bits[:], bit.UnpackBits(raw[:]))
+	h, _ := pdu.DecodeUDPIPv4CompressedHeader(bits)
+	if h.HeaderCompressionOpcode != udpIPv4Opcode {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "HeaderCompressionOpcode", Err: elements.ErrNotImplemented}
+	}
+
+	n := udpIPv4CompressedOctets
+	extended := []uint16{h.ExtendedHeader1, h.ExtendedHeader2}
+	srcPort := enums.SPIDToPort(enums.SPID(h.SPID))
+	if enums.SPID(h.SPID) == enums.SPIDExtendedHeader {
+		srcPort, extended = extended[0], extended[1:]
+		n += udpIPv4ExtendedOctets
+	} else if srcPort == 0 {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "SPID", Err: elements.ErrNotImplemented}
+	}
+	dstPort := enums.DPIDToPort(enums.DPID(h.DPID))
+	if enums.DPID(h.DPID) == enums.DPIDExtendedHeader {
+		dstPort = extended[0]
+		n += udpIPv4ExtendedOctets
+	} else if dstPort == 0 {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "DPID", Err: elements.ErrNotImplemented}
+	}
+	if len(msg.Payload) < n {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "ExtendedHeader", Err: elements.ErrInvalidLength}
+	}
+	data := msg.Payload[n:]
+	if ipv4HeaderOctets+udpHeaderOctets+len(data) > maxIPv4Octets {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Payload", Err: elements.ErrInvalidLength}
+	}
+
+	src, err := c.SourceAddress(enums.SAID(h.SAID), msg.Source)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := c.DestinationAddress(enums.DAID(h.DAID), msg.Destination)
+	if err != nil {
+		return nil, err
+	}
+	d := UDPDatagram{
+		Source:         netip.AddrPortFrom(src, srcPort),
+		Destination:    netip.AddrPortFrom(dst, dstPort),
+		Identification: h.IPv4Identification,
+		Payload:        data,
+	}
+	return d.Encode()
+}
+
+// CompressUDPIPv4 compresses a UDP/IPv4 datagram into a message for SAP
+// UDP/IP header compression. The well-known ports have a SPID or DPID;
+// other ports are sent in extended headers. The message is addressed to
+// the LLIDs of the datagram's addresses, and to a talkgroup when the
+// destination is on the group network.
+//
+// The type of service, TTL and Don't Fragment flag of the datagram are not
+// sent. Errors are an *elements.PDUError wrapping
+// elements.ErrInvalidLength for a truncated datagram,
+// elements.ErrDataTypeMismatch for another protocol, or
+// elements.ErrInvalidEncoding for IPv4 options, a fragment or an address
+// on none of the context's networks.
+func (c *IPv4AddressContext) CompressUDPIPv4(datagram []byte) (*PacketData, error) {
+	d, err := ParseUDPDatagram(datagram)
+	if err != nil {
+		return nil, err
+	}
+	said, source, err := c.SourceID(d.Source.Addr())
+	if err != nil {
+		return nil, err
+	}
+	daid, destination, err := c.DestinationID(d.Destination.Addr())
+	if err != nil {
+		return nil, err
+	}
+
+	srcPort := d.Source.Port()
+	dstPort := d.Destination.Port()
+	h := pdu.UDPIPv4CompressedHeader{
+		IPv4Identification:      d.Identification,
+		SAID:                    uint8(said),
+		DAID:                    uint8(daid),
+		HeaderCompressionOpcode: udpIPv4Opcode,
+		SPID:                    uint8(spidForPort(srcPort)),
+		DPID:                    uint8(dpidForPort(dstPort)),
+	}
+	extended := make([]uint16, 0, 2)
+	if enums.SPID(h.SPID) == enums.SPIDExtendedHeader {
+		extended = append(extended, srcPort)
+	}
+	if enums.DPID(h.DPID) == enums.DPIDExtendedHeader {
+		extended = append(extended, dstPort)
+	}
+	if len(extended) > 0 {
+		h.ExtendedHeader1 = extended[0]
+	}
+	if len(extended) > 1 {
+		h.ExtendedHeader2 = extended[1]
+	}
+
+	bits := pdu.EncodeUDPIPv4CompressedHeader(&h)
+	header := bit.PackBits(bits[:])[:udpIPv4CompressedOctets+udpIPv4ExtendedOctets*len(extended)]
+	out := make([]byte, 0, len(header)+len(d.Payload))
+	out = append(out, header...)
+	out = append(out, d.Payload...)
+	return &PacketData{
+		SAP:         pdu.ServiceAccessPointIDUDPIPHeaderCompression,
+		Source:      source,
+		Destination: destination,
+		Group:       daid == enums.DAIDGroupNetwork,
+		Payload:     out,
+	}, nil
+}
+
+// spidForPort returns the SPID of a well-known port, or
+// enums.SPIDExtendedHeader.
+func spidForPort(port uint16) enums.SPID {
+	for spid := enums.SPIDTextMessage; spid < enums.SPIDManufacturerSpecific; spid++ {
+		if enums.SPIDToPort(spid) == port {
+			return spid
+		}
+	}
+	return enums.SPIDExtendedHeader
+}
+
+// dpidForPort returns the DPID of a well-known port, or
+// enums.DPIDExtendedHeader.
+func dpidForPort(port uint16) enums.DPID {
+	for dpid := enums.DPIDTextMessage; dpid < enums.DPIDManufacturerSpecific; dpid++ {
+		if enums.DPIDToPort(dpid) == port {
+			return dpid
+		}
+	}
+	return enums.DPIDExtendedHeader
+}
@@ -0,0 +1,281 @@
+package layer2_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net/netip"
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// checksumValid reports whether the Internet checksum of the parts, with
+// their checksum fields filled in, verifies.
+func checksumValid(parts ...[]byte) bool {
+	var sum uint32
+	for _, p := range parts {
+		for i := 0; i < len(p); i += 2 {
+			w := uint32(p[i]) << 8
+			if i+1 < len(p) {
+				w |= uint32(p[i+1])
+			}
+			sum += w
+		}
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xFFFF + sum>>16
+	}
+	return sum == 0xFFFF
+}
+
+// checkUDPIPv4 verifies the lengths and checksums of a datagram.
+func checkUDPIPv4(t *testing.T, datagram []byte) {
+	t.Helper()
+	if len(datagram) < 28 || int(binary.BigEndian.Uint16(datagram[2:])) != len(datagram) ||
+		int(binary.BigEndian.Uint16(datagram[24:])) != len(datagram)-20 {
+		t.Fatalf("lengths: % X", datagram)
+	}
+	if !checksumValid(datagram[:20]) {
+		t.Errorf("IPv4 header checksum: % X", datagram[:20])
+	}
+	pseudo := append(append([]byte{}, datagram[12:20]...), 0, 17, datagram[24], datagram[25])
+	if !checksumValid(pseudo, datagram[20:]) {
+		t.Errorf("UDP checksum: % X", datagram[20:])
+	}
+}
+
+func TestDecompressUDPIPv4_Vector(t *testing.T) {
+	t.Parallel()
+	var c layer2.IPv4AddressContext
+	msg := &layer2.PacketData{
+		SAP:         pdu.ServiceAccessPointIDUDPIPHeaderCompression,
+		Source:      3120001,
+		Destination: 3120002,
+		Payload:     []byte{0x12, 0x34, 0x00, 0x01, 0x01, 'h', 'i'},
+	}
+	got, err := c.DecompressUDPIPv4(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		0x45, 0x00, 0x00, 0x1E, 0x12, 0x34, 0x00, 0x00, 0x40, 0x11, 0x19, 0x3A,
+		12, 0x2F, 0x9B, 0x81, 12, 0x2F, 0x9B, 0x82,
+		0x13, 0x98, 0x13, 0x98, 0x00, 0x0A, 0x20, 0xDF, 'h', 'i',
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("datagram:\n got % X\nwant % X", got, want)
+	}
+
+	back, err := c.CompressUDPIPv4(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(back.Payload, msg.Payload) || back.Source != msg.Source || back.Destination != msg.Destination ||
+		back.Group || back.SAP != msg.SAP {
+		t.Errorf("compressed = %s", back.ToString())
+	}
+}
+
+func TestUDPIPv4Compression_RoundTrip(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		src, dst  netip.Addr
+		srcPort   uint16
+		dstPort   uint16
+		header    int
+		spid      enums.SPID
+		dpid      enums.DPID
+		group     bool
+		extended1 uint16
+		extended2 uint16
+	}{
+		{"well-known ports", netip.MustParseAddr("12.0.0.1"), netip.MustParseAddr("12.0.0.2"), 5016, 5017, 5, enums.SPIDTextMessage, enums.DPIDLocationProtocol, false, 0, 0},
+		{"source port extended", netip.MustParseAddr("13.0.0.1"), netip.MustParseAddr("12.0.0.2"), 40000, 5016, 7, enums.SPIDExtendedHeader, enums.DPIDTextMessage, false, 40000, 0},
+		{"destination port extended", netip.MustParseAddr("12.0.0.1"), netip.MustParseAddr("13.0.0.2"), 5017, 4001, 7, enums.SPIDLocationProtocol, enums.DPIDExtendedHeader, false, 4001, 0},
+		{"both ports extended", netip.MustParseAddr("12.47.155.129"), netip.MustParseAddr("225.0.0.91"), 4005, 4007, 9, enums.SPIDExtendedHeader, enums.DPIDExtendedHeader, true, 4005, 4007},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var c layer2.IPv4AddressContext
+			data := []byte("position report")
+			// Build the datagram from a first compression so that its
+			// checksums are valid, then check every field.
+			seed := &layer2.PacketData{
+				SAP:         pdu.ServiceAccessPointIDUDPIPHeaderCompression,
+				Source:      addrLLID(tt.src),
+				Destination: addrLLID(tt.dst),
+				Payload:     compressedHeader(t, &c, tt.src, tt.dst, tt.spid, tt.dpid, tt.extended1, tt.extended2, data),
+			}
+			datagram, err := c.DecompressUDPIPv4(seed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkUDPIPv4(t, datagram)
+			if netip.AddrFrom4([4]byte(datagram[12:16])) != tt.src || netip.AddrFrom4([4]byte(datagram[16:20])) != tt.dst ||
+				binary.BigEndian.Uint16(datagram[20:]) != tt.srcPort || binary.BigEndian.Uint16(datagram[22:]) != tt.dstPort ||
+				binary.BigEndian.Uint16(datagram[4:]) != 0xBEEF || !bytes.Equal(datagram[28:], data) {
+				t.Fatalf("datagram % X", datagram)
+			}
+
+			msg, err := c.CompressUDPIPv4(datagram)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(msg.Payload) != tt.header+len(data) || !bytes.Equal(msg.Payload, seed.Payload) {
+				t.Errorf("compressed % X, want % X", msg.Payload, seed.Payload)
+			}
+			if msg.Group != tt.group || msg.Source != seed.Source || msg.Destination != seed.Destination {
+				t.Errorf("compressed = %s", msg.ToString())
+			}
+		})
+	}
+}
+
+func addrLLID(a netip.Addr) int {
+	b := a.As4()
+	return int(b[1])<<16 | int(b[2])<<8 | int(b[3])
+}
+
+// compressedHeader returns a compressed header and data with IPv4
+// Identification 0xBEEF.
+func compressedHeader(t *testing.T, c *layer2.IPv4AddressContext, src, dst netip.Addr, spid enums.SPID, dpid enums.DPID, ext1, ext2 uint16, data []byte) []byte {
+	t.Helper()
+	said, _, err := c.SourceID(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	daid, _, err := c.DestinationID(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := []byte{0xBE, 0xEF, byte(said)<<4 | byte(daid), byte(spid), byte(dpid)}
+	if spid == enums.SPIDExtendedHeader {
+		out = binary.BigEndian.AppendUint16(out, ext1)
+		ext1 = ext2
+	}
+	if dpid == enums.DPIDExtendedHeader {
+		out = binary.BigEndian.AppendUint16(out, ext1)
+	}
+	return append(out, data...)
+}
+
+func TestIPv4AddressContext_Networks(t *testing.T) {
+	t.Parallel()
+	c := layer2.IPv4AddressContext{
+		Sources:      map[enums.SAID]byte{enums.SAIDRadioNetwork: 10},
+		Destinations: map[enums.DAID]byte{enums.DAIDGroupNetwork: 239},
+	}
+	if a, err := c.SourceAddress(enums.SAIDRadioNetwork, 3120001); err != nil || a != netip.MustParseAddr("10.47.155.129") {
+		t.Errorf("SourceAddress = %s, %v", a, err)
+	}
+	if a, err := c.DestinationAddress(enums.DAIDGroupNetwork, 91); err != nil || a != netip.MustParseAddr("239.0.0.91") {
+		t.Errorf("DestinationAddress = %s, %v", a, err)
+	}
+	if said, llid, err := c.SourceID(netip.MustParseAddr("13.0.1.0")); err != nil || said != enums.SAIDUSBEthernetNetwork || llid != 256 {
+		t.Errorf("SourceID = %d, %d, %v", said, llid, err)
+	}
+	_, err := c.SourceAddress(enums.SAIDManufacturerSpecific, 1)
+	testutil.AssertPDUError(t, err, elements.ErrNotImplemented, elements.LayerPacket, "SAID")
+	_, _, err = c.DestinationID(netip.MustParseAddr("192.168.1.1"))
+	testutil.AssertPDUError(t, err, elements.ErrInvalidEncoding, elements.LayerPacket, "DestinationAddress")
+}
+
+func TestIPv4AddressContext_RadioDatagram(t *testing.T) {
+	t.Parallel()
+	var c layer2.IPv4AddressContext
+	for _, want := range []layer2.RadioDatagram{
+		{Source: 3120001, Destination: 3120002, Payload: []byte("radio")},
+		{Source: 3120001, Destination: 91, Group: true, Payload: []byte("group")},
+	} {
+		datagram, err := c.EncodeRadioDatagram(&want, 4007, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		d, err := layer2.ParseUDPDatagram(datagram)
+		if err != nil || d.Source.Port() != 4007 || d.Destination.Port() != 4007 {
+			t.Fatalf("ParseUDPDatagram = %+v, %v", d, err)
+		}
+		got, err := c.DecodeRadioDatagram(datagram, 4007)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Source != want.Source || got.Destination != want.Destination || got.Group != want.Group || !bytes.Equal(got.Payload, want.Payload) {
+			t.Errorf("DecodeRadioDatagram = %+v, want %+v", got, want)
+		}
+		_, err = c.DecodeRadioDatagram(datagram, 4001)
+		testutil.AssertPDUError(t, err, elements.ErrDataTypeMismatch, elements.LayerApplication, "Port")
+	}
+}
+
+func TestUDPIPv4Compression_Errors(t *testing.T) {
+	t.Parallel()
+	var c layer2.IPv4AddressContext
+	valid, err := c.DecompressUDPIPv4(&layer2.PacketData{
+		SAP:     pdu.ServiceAccessPointIDUDPIPHeaderCompression,
+		Payload: []byte{0, 1, 0, 1, 1, 'x'},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	modified := func(f func([]byte)) []byte {
+		d := bytes.Clone(valid)
+		f(d)
+		return d
+	}
+
+	compress := []struct {
+		name     string
+		datagram []byte
+		sentinel error
+		field    string
+	}{
+		{"truncated", valid[:19], elements.ErrInvalidLength, "IPv4Header"},
+		{"IPv6", modified(func(d []byte) { d[0] = 0x65 }), elements.ErrInvalidEncoding, "Version"},
+		{"options", modified(func(d []byte) { d[0] = 0x46 }), elements.ErrInvalidEncoding, "IPv4Options"},
+		{"total length", modified(func(d []byte) { d[3]++ }), elements.ErrInvalidLength, "TotalLength"},
+		{"fragment", modified(func(d []byte) { d[6] = 0x20 }), elements.ErrInvalidEncoding, "FragmentOffset"},
+		{"TCP", modified(func(d []byte) { d[9] = 6 }), elements.ErrDataTypeMismatch, "Protocol"},
+		{"UDP length", modified(func(d []byte) { d[25] = 7 }), elements.ErrInvalidLength, "UDPLength"},
+		{"source address", modified(func(d []byte) { d[12] = 192 }), elements.ErrInvalidEncoding, "SourceAddress"},
+	}
+	for _, tt := range compress {
+		t.Run("compress "+tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := c.CompressUDPIPv4(tt.datagram)
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerPacket, tt.field)
+		})
+	}
+	// The Don't Fragment flag is not carried but does not stop compression.
+	if _, err := c.CompressUDPIPv4(modified(func(d []byte) { d[6] = 0x40 })); err != nil {
+		t.Errorf("DF: %v", err)
+	}
+
+	decompress := []struct {
+		name     string
+		msg      layer2.PacketData
+		sentinel error
+		field    string
+	}{
+		{"SAP", layer2.PacketData{SAP: pdu.ServiceAccessPointIDShortData, Payload: make([]byte, 5)}, elements.ErrDataTypeMismatch, "SAP"},
+		{"short header", layer2.PacketData{SAP: pdu.ServiceAccessPointIDUDPIPHeaderCompression, Payload: make([]byte, 4)}, elements.ErrInvalidLength, "UDPIPv4CompressedHeader"},
+		{"missing extended headers", layer2.PacketData{SAP: pdu.ServiceAccessPointIDUDPIPHeaderCompression, Payload: make([]byte, 8)}, elements.ErrInvalidLength, "ExtendedHeader"},
+		{"opcode", layer2.PacketData{SAP: pdu.ServiceAccessPointIDUDPIPHeaderCompression, Payload: []byte{0, 0, 0, 0x81, 1}}, elements.ErrNotImplemented, "HeaderCompressionOpcode"},
+		{"reserved SPID", layer2.PacketData{SAP: pdu.ServiceAccessPointIDUDPIPHeaderCompression, Payload: []byte{0, 0, 0, 3, 1}}, elements.ErrNotImplemented, "SPID"},
+		{"reserved DPID", layer2.PacketData{SAP: pdu.ServiceAccessPointIDUDPIPHeaderCompression, Payload: []byte{0, 0, 0, 1, 3}}, elements.ErrNotImplemented, "DPID"},
+		{"reserved DAID", layer2.PacketData{SAP: pdu.ServiceAccessPointIDUDPIPHeaderCompression, Payload: []byte{0, 0, 0x05, 1, 1}}, elements.ErrNotImplemented, "DAID"},
+	}
+	for _, tt := range decompress {
+		t.Run("decompress "+tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := c.DecompressUDPIPv4(&tt.msg)
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerPacket, tt.field)
+		})
+	}
+}
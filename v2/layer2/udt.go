@@ -0,0 +1,219 @@
+package layer2
+
+import (
+	"fmt"
+
+	"github.com/USA-RedDragon/dmrgo/v2/crc"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// ETSI TS 102 361-4 — Unified Data Transport (UDT)
+//
+// A UDT message is a UDT_HEAD data header followed by one to four appended
+// Rate 1/2 blocks. The header gives the UDT format of the appended data and
+// the number of pad nibbles between the data and the CRC-CCITT in the last
+// two octets of the last block. The CRC covers the data and pad nibbles.
+
+const (
+	// maxUDTBlocks is the largest number of blocks a UDT_HEAD appends.
+	maxUDTBlocks = 4
+	udtCRCOctets = 2
+	nibbleBits   = 4
+)
+
+// UnifiedDataTransport is a UDT message.
+type UnifiedDataTransport struct {
+	// Source and Destination are the LLIDs from the data header.
+	Source      int
+	Destination int
+	// Group reports a message addressed to a talkgroup.
+	Group bool
+	// ResponseRequested and Emergency are the flags from the data header.
+	ResponseRequested bool
+	Emergency         bool
+	// Supplementary and Opcode are the supplementary flag and UDT opcode
+	// from the data header.
+	Supplementary bool
+	Opcode        uint8
+	// Format is the UDT format of Data.
+	Format enums.UDTFormat
+	// Data holds BitLength bits of user data, most significant bit first,
+	// a whole number of nibbles. The bits of the last octet past BitLength
+	// are zero.
+	Data      []byte
+	BitLength int
+}
+
+// ToString returns a string representation of the message.
+func (u *UnifiedDataTransport) ToString() string {
+	return fmt.Sprintf("UnifiedDataTransport{ Source: %d, Destination: %d, Group: %t, ResponseRequested: %t, Emergency: %t, Supplementary: %t, Opcode: %d, Format: %s, BitLength: %d, Data: % X }",
+		u.Source, u.Destination, u.Group, u.ResponseRequested, u.Emergency, u.Supplementary, u.Opcode, enums.UDTFormatToName(u.Format), u.BitLength, u.Data)
+}
+
+// DecodeUnifiedDataTransport returns the message carried by a UDT_HEAD and
+// its appended blocks. Data block bursts that failed to decode should
+// still be passed in; they fail the CRC.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrInvalidLength when
+// the number of blocks or the pad nibbles do not match the header, or
+// elements.ErrCRCMismatch.
+func DecodeUnifiedDataTransport(h *pdu.UDTHeader, blocks []*Burst) (*UnifiedDataTransport, error) {
+	if len(blocks) != h.Blocks() {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "AppendedBlocks", Err: elements.ErrInvalidLength}
+	}
+	var data []byte
+	for i, b := range blocks {
+		octets, ok := dataBlockOctets(b)
+		if !ok {
+			return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: fmt.Sprintf("Block[%d]", i), Err: elements.ErrDataTypeMismatch}
+		}
+		data = append(data, octets...)
+	}
+	return decodeUnifiedDataTransport(h, data)
+}
+
+// decodeUnifiedDataTransport returns the message carried by a UDT_HEAD and
+// the octets of its appended blocks.
+func decodeUnifiedDataTransport(h *pdu.UDTHeader, data []byte) (*UnifiedDataTransport, error) {
+	if !crc.CheckCRCCCITT(data) {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "CRC", Err: elements.ErrCRCMismatch}
+	}
+	body := data[:len(data)-udtCRCOctets]
+	bitLength := len(body)*8 - int(h.PadNibble)*nibbleBits
+	if bitLength < 0 {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "PadNibble", Err: elements.ErrInvalidLength}
+	}
+
+	body = body[:(bitLength+7)/8]
+	if bitLength%8 != 0 {
+		body[len(body)-1] &= 0xF0
+	}
+	return &UnifiedDataTransport{
+		Source:            h.LLIDSource,
+		Destination:       h.LLIDDestination,
+		Group:             h.Group,
+		ResponseRequested: h.ResponseRequested,
+		Emergency:         h.Emergency,
+		Supplementary:     h.SupplementaryFlag,
+		Opcode:            h.Opcode,
+		Format:            enums.UDTFormat(h.UDTFormat),
+		Data:              body,
+		BitLength:         bitLength,
+	}, nil
+}
+
+// Text returns the characters of a message in a 7-bit, 8-bit or 16-bit
+// Unicode character format. Errors are an *elements.PDUError wrapping
+// elements.ErrInvalidEncoding when the format does not carry characters
+// or the data is not valid in it.
+func (u *UnifiedDataTransport) Text() (string, error) {
+	f, ok := u.Format.DDFormat()
+	if !ok {
+		return "", &elements.PDUError{Layer: elements.LayerPacket, Field: "Format", Err: elements.ErrInvalidEncoding}
+	}
+	if err := u.checkLength(); err != nil {
+		return "", err
+	}
+	return decodeDDText(f, u.Data, u.BitLength)
+}
+
+// Digits returns the decimal digits of a BCD message. Errors are an
+// *elements.PDUError wrapping elements.ErrInvalidEncoding when the format
+// is not BCD or a digit is out of range.
+func (u *UnifiedDataTransport) Digits() (string, error) {
+	if u.Format != enums.UDTFormatBCD {
+		return "", &elements.PDUError{Layer: elements.LayerPacket, Field: "Format", Err: elements.ErrInvalidEncoding}
+	}
+	if err := u.checkLength(); err != nil {
+		return "", err
+	}
+	return decodeBCD(u.Data, u.BitLength)
+}
+
+// SetText sets the Format, Data and BitLength of the message to text in a
+// 7-bit, 8-bit or 16-bit Unicode character format. Errors are as for Text.
+func (u *UnifiedDataTransport) SetText(format enums.UDTFormat, text string) error {
+	f, ok := format.DDFormat()
+	if !ok {
+		return &elements.PDUError{Layer: elements.LayerPacket, Field: "Format", Err: elements.ErrInvalidEncoding}
+	}
+	data, bitLength, err := encodeDDText(f, text)
+	if err != nil {
+		return err
+	}
+	// Round up to whole nibbles; the bits added are zero.
+	u.Format, u.Data, u.BitLength = format, data, (bitLength+nibbleBits-1)/nibbleBits*nibbleBits
+	return nil
+}
+
+// SetDigits sets the message to a string of decimal digits in BCD. A
+// non-digit returns an *elements.PDUError wrapping
+// elements.ErrInvalidEncoding.
+func (u *UnifiedDataTransport) SetDigits(digits string) error {
+	data, bitLength, err := encodeBCD(digits)
+	if err != nil {
+		return err
+	}
+	u.Format, u.Data, u.BitLength = enums.UDTFormatBCD, data, bitLength
+	return nil
+}
+
+// checkLength verifies that Data holds BitLength bits, a whole number of
+// nibbles.
+func (u *UnifiedDataTransport) checkLength() error {
+	if u.BitLength < 0 || u.BitLength%nibbleBits != 0 || len(u.Data) != (u.BitLength+7)/8 {
+		return &elements.PDUError{Layer: elements.LayerPacket, Field: "BitLength", Err: elements.ErrInvalidLength}
+	}
+	return nil
+}
+
+// Bursts returns the UDT_HEAD and appended Rate 1/2 blocks of the message,
+// sent with the data SYNC syncPattern and colorCode. Data that does not
+// hold BitLength bits in whole nibbles, or needs more than four blocks,
+// returns an *elements.PDUError wrapping elements.ErrInvalidLength.
+func (u *UnifiedDataTransport) Bursts(syncPattern enums.SyncPattern, colorCode int) ([]Burst, error) {
+	if err := u.checkLength(); err != nil {
+		return nil, err
+	}
+	blockOctets := PacketBlockOctets(elements.DataTypeRate12, false)
+	blockNibbles := blockOctets * 8 / nibbleBits
+	crcNibbles := udtCRCOctets * 8 / nibbleBits
+	nibbles := u.BitLength / nibbleBits
+	n := max((nibbles+crcNibbles+blockNibbles-1)/blockNibbles, 1)
+	if n > maxUDTBlocks {
+		return nil, &elements.PDUError{Layer: elements.LayerPacket, Field: "Data", Err: elements.ErrInvalidLength}
+	}
+
+	data := make([]byte, n*blockOctets)
+	copy(data, u.Data)
+	sum := crc.CalculateCRCCCITT(data[:len(data)-udtCRCOctets])
+	data[len(data)-2], data[len(data)-1] = byte(sum>>8), byte(sum)
+
+	header := &pdu.DataHeader{
+		DataType: elements.DataTypeDataHeader,
+		Format:   pdu.FormatUnifiedDataTransport,
+		UDTHeader: &pdu.UDTHeader{
+			Group:             u.Group,
+			ResponseRequested: u.ResponseRequested,
+			Emergency:         u.Emergency,
+			SAP:               uint8(pdu.ServiceAccessPointIDUnifiedDataTransport),
+			UDTFormat:         uint8(u.Format),
+			LLIDDestination:   u.Destination,
+			LLIDSource:        u.Source,
+			PadNibble:         uint8(n*blockNibbles - crcNibbles - nibbles), //nolint:gosec // less than one block of nibbles
+			AppendedBlocks:    uint8(n - 1),                                 //nolint:gosec // n <= maxUDTBlocks
+			SupplementaryFlag: u.Supplementary,
+			Opcode:            u.Opcode,
+		},
+	}
+
+	bursts := make([]Burst, 0, 1+n)
+	bursts = append(bursts, newDataBurst(syncPattern, colorCode, header))
+	for i := range n {
+		block := data[i*blockOctets : (i+1)*blockOctets]
+		bursts = append(bursts, newDataBurst(syncPattern, colorCode, dataBlockData(elements.DataTypeRate12, block)))
+	}
+	return bursts, nil
+}
@@ -0,0 +1,170 @@
+package layer2_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// sendUDT passes a UDT message over the air and decodes it, checking the
+// header against the expected number of blocks and pad nibbles.
+func sendUDT(t *testing.T, msg *layer2.UnifiedDataTransport, blocks, pad int) *layer2.UnifiedDataTransport {
+	t.Helper()
+	bursts, err := msg.Bursts(enums.MsSourcedData, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rx := overAir(t, bursts)
+	header := burstData[*pdu.DataHeader](t, rx[0])
+	if header.Format != pdu.FormatUnifiedDataTransport || header.UDTHeader == nil {
+		t.Fatalf("header = %+v", header)
+	}
+	if len(rx)-1 != blocks || header.UDTHeader.Blocks() != blocks || int(header.UDTHeader.PadNibble) != pad {
+		t.Fatalf("%d blocks, header %s; want %d blocks and %d pad nibbles", len(rx)-1, header.UDTHeader.ToString(), blocks, pad)
+	}
+	got, err := layer2.DecodeUnifiedDataTransport(header.UDTHeader, rx[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestUnifiedDataTransport_Text_RoundTrip(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		format enums.UDTFormat
+		text   string
+		blocks int
+		pad    int
+	}{
+		// 21 bits round up to 6 nibbles; 24 - 4 CRC - 6 leaves 14 pad.
+		{"7-bit", enums.UDTFormat7BitCharacter, "Hi!", 1, 14},
+		{"7-bit empty", enums.UDTFormat7BitCharacter, "", 1, 20},
+		{"8-bit", enums.UDTFormat8BitCharacter, "café", 1, 12},
+		{"8-bit fills a block", enums.UDTFormat8BitCharacter, "0123456789", 1, 0},
+		{"8-bit spills a block", enums.UDTFormat8BitCharacter, "0123456789a", 2, 22},
+		{"Unicode", enums.UDTFormatUnicode, "Добро", 1, 0},
+		{"Unicode four blocks", enums.UDTFormatUnicode, strings.Repeat("я", 23), 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			msg := &layer2.UnifiedDataTransport{
+				Source:            3120001,
+				Destination:       91,
+				Group:             true,
+				ResponseRequested: true,
+				Supplementary:     true,
+				Opcode:            0x05,
+			}
+			if err := msg.SetText(tt.format, tt.text); err != nil {
+				t.Fatal(err)
+			}
+			got := sendUDT(t, msg, tt.blocks, tt.pad)
+			if got.Source != msg.Source || got.Destination != msg.Destination || !got.Group ||
+				!got.ResponseRequested || got.Emergency || !got.Supplementary || got.Opcode != msg.Opcode ||
+				got.Format != tt.format || got.BitLength != msg.BitLength || !bytes.Equal(got.Data, msg.Data) {
+				t.Fatalf("got %s\nwant %s", got.ToString(), msg.ToString())
+			}
+			text, err := got.Text()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if text != tt.text {
+				t.Errorf("Text = %q, want %q", text, tt.text)
+			}
+		})
+	}
+}
+
+func TestUnifiedDataTransport_Digits_RoundTrip(t *testing.T) {
+	t.Parallel()
+	msg := &layer2.UnifiedDataTransport{Source: 1, Destination: 2, Emergency: true}
+	if err := msg.SetDigits("0123456789"); err != nil {
+		t.Fatal(err)
+	}
+	got := sendUDT(t, msg, 1, 10)
+	digits, err := got.Digits()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digits != "0123456789" || !got.Emergency {
+		t.Errorf("got %q from %s", digits, got.ToString())
+	}
+}
+
+func TestUnifiedDataTransport_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("encode", func(t *testing.T) {
+		t.Parallel()
+		var msg layer2.UnifiedDataTransport
+		testutil.AssertPDUError(t, msg.SetText(enums.UDTFormatBinary, "a"), elements.ErrInvalidEncoding, elements.LayerPacket, "Format")
+		testutil.AssertPDUError(t, msg.SetText(enums.UDTFormat7BitCharacter, "é"), elements.ErrInvalidEncoding, elements.LayerPacket, "Data")
+		testutil.AssertPDUError(t, msg.SetDigits("12a"), elements.ErrInvalidEncoding, elements.LayerPacket, "Data")
+
+		if err := msg.SetText(enums.UDTFormatUnicode, strings.Repeat("я", 24)); err != nil {
+			t.Fatal(err)
+		}
+		_, err := msg.Bursts(enums.MsSourcedData, 1)
+		testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "Data")
+
+		odd := layer2.UnifiedDataTransport{Data: []byte{0xFF}, BitLength: 6}
+		_, err = odd.Bursts(enums.MsSourcedData, 1)
+		testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "BitLength")
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		t.Parallel()
+		msg := &layer2.UnifiedDataTransport{Source: 1, Destination: 2}
+		if err := msg.SetText(enums.UDTFormat8BitCharacter, "0123456789a"); err != nil {
+			t.Fatal(err)
+		}
+		bursts, err := msg.Bursts(enums.MsSourcedData, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rx := overAir(t, bursts)
+		header := burstData[*pdu.DataHeader](t, rx[0]).UDTHeader
+
+		_, err = layer2.DecodeUnifiedDataTransport(header, rx[1:2])
+		testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "AppendedBlocks")
+
+		_, err = layer2.DecodeUnifiedDataTransport(header, []*layer2.Burst{rx[1], rx[0]})
+		testutil.AssertPDUError(t, err, elements.ErrDataTypeMismatch, elements.LayerPacket, "Block[1]")
+
+		corrupt := burstData[*pdu.Rate12Data](t, rx[1])
+		corrupt.Data[0] ^= 0x01
+		_, err = layer2.DecodeUnifiedDataTransport(header, rx[1:])
+		testutil.AssertPDUError(t, err, elements.ErrCRCMismatch, elements.LayerPacket, "CRC")
+		corrupt.Data[0] ^= 0x01
+
+		padded := *header
+		padded.PadNibble = 47
+		_, err = layer2.DecodeUnifiedDataTransport(&padded, rx[1:])
+		testutil.AssertPDUError(t, err, elements.ErrInvalidLength, elements.LayerPacket, "PadNibble")
+	})
+
+	t.Run("format", func(t *testing.T) {
+		t.Parallel()
+		binary := &layer2.UnifiedDataTransport{Format: enums.UDTFormatBinary, Data: []byte{0x41}, BitLength: 8}
+		_, err := binary.Text()
+		testutil.AssertPDUError(t, err, elements.ErrInvalidEncoding, elements.LayerPacket, "Format")
+		_, err = binary.Digits()
+		testutil.AssertPDUError(t, err, elements.ErrInvalidEncoding, elements.LayerPacket, "Format")
+
+		short := &layer2.UnifiedDataTransport{Format: enums.UDTFormat8BitCharacter, Data: []byte{0x41}, BitLength: 16}
+		_, err = short.Text()
+		if !errors.Is(err, elements.ErrInvalidLength) {
+			t.Errorf("Text of short data: err = %v", err)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package layer2
+
+import (
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/constants"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// UnconfirmedDataAssembler reassembles unconfirmed packet data messages
+// (a U_HEAD data header followed by its data blocks) received on a single
+// timeslot. Use one assembler per timeslot; the zero value is ready to use.
+//
+// CSBK preambles, idle bursts and other data bursts that are not data
+// blocks are skipped. A data header of another format or a voice burst
+// abandons the packet in progress.
+type UnconfirmedDataAssembler struct {
+	// Timeout bounds the time from the first header of a message to its
+	// last data block. Zero uses constants.TDataTxLmt.
+	Timeout time.Duration
+
+	header   *pdu.UnconfirmedDataHeader
+	blocks   []byte
+	received int
+	message  *PacketData
+	nextFSN  uint8
+	started  time.Time
+}
+
+// Reset discards any partially received packet and message.
+func (a *UnconfirmedDataAssembler) Reset() {
+	a.header = nil
+	a.blocks = a.blocks[:0]
+	a.received = 0
+	a.message = nil
+}
+
+// AddBurst feeds the next burst received on the slot at time now. It
+// returns the message when the burst completes one.
+//
+// Errors report a packet or message that was dropped: a
+// *MissingBlocksError when a packet was interrupted or timed out, or an
+// *elements.PDUError wrapping elements.ErrCRCMismatch,
+// elements.ErrFragmentSequence or elements.ErrInvalidLength. The assembler
+// carries on with the next packet after an error.
+//
+// Data block bursts that failed to decode should still be passed in so
+// that they count towards BlocksToFollow.
+func (a *UnconfirmedDataAssembler) AddBurst(b *Burst, now time.Time) (*PacketData, error) {
+	expired := a.Expire(now)
+	msg, err := a.add(b, now)
+	if expired != nil {
+		return msg, expired
+	}
+	return msg, err
+}
+
+// Expire drops the message in progress if it has been pending for longer
+// than the timeout at time now, and reports it with a *MissingBlocksError.
+// Call it periodically when no bursts are being received.
+func (a *UnconfirmedDataAssembler) Expire(now time.Time) error {
+	if a.header == nil && a.message == nil {
+		return nil
+	}
+	timeout := a.Timeout
+	if timeout == 0 {
+		timeout = constants.TDataTxLmt
+	}
+	if now.Sub(a.started) <= timeout {
+		return nil
+	}
+	err := &MissingBlocksError{Received: a.received, TimedOut: true}
+	if a.header != nil {
+		err.Expected = int(a.header.BlocksToFollow)
+	}
+	a.Reset()
+	return err
+}
+
+func (a *UnconfirmedDataAssembler) add(b *Burst, now time.Time) (*PacketData, error) {
+	if header, ok := b.Data.(*pdu.DataHeader); ok {
+		if header.UnconfirmedDataHeader == nil {
+			return nil, a.abandon()
+		}
+		return nil, a.startPacket(header.UnconfirmedDataHeader, now)
+	}
+	if !b.IsData {
+		return nil, a.abandon()
+	}
+
+	octets, ok := dataBlockOctets(b)
+	if !ok || a.header == nil {
+		return nil, nil
+	}
+	a.blocks = append(a.blocks, octets...)
+	a.received++
+	if a.received < int(a.header.BlocksToFollow) {
+		return nil, nil
+	}
+	return a.finishPacket()
+}
+
+// abandon drops the packet in progress, and with it the message, reporting
+// the blocks that were not received.
+func (a *UnconfirmedDataAssembler) abandon() error {
+	if a.header == nil {
+		return nil
+	}
+	err := &MissingBlocksError{Expected: int(a.header.BlocksToFollow), Received: a.received}
+	a.Reset()
+	return err
+}
+
+// startPacket begins collecting the data blocks that follow a U_HEAD.
+func (a *UnconfirmedDataAssembler) startPacket(h *pdu.UnconfirmedDataHeader, now time.Time) error {
+	err := a.abandon()
+
+	if h.FullMessage {
+		if err == nil && a.message != nil {
+			err = &elements.PDUError{Layer: elements.LayerPacket, Field: "FullMessage", Err: elements.ErrFragmentSequence}
+		}
+		a.message = &PacketData{
+			SAP:               pdu.ServiceAccessPointID(h.SAP),
+			Source:            llid(h.LLIDSource),
+			Destination:       llid(h.LLIDDestination),
+			Group:             h.Group,
+			ResponseRequested: h.ResponseRequested,
+		}
+		a.started = now
+	} else if a.message == nil || h.FragmentSequenceNumber&fsnSequenceMask != a.nextFSN {
+		a.Reset()
+		if err != nil {
+			return err
+		}
+		return &elements.PDUError{Layer: elements.LayerPacket, Field: "FragmentSequenceNumber", Err: elements.ErrFragmentSequence}
+	}
+
+	if h.BlocksToFollow == 0 {
+		a.Reset()
+		if err != nil {
+			return err
+		}
+		return &elements.PDUError{Layer: elements.LayerPacket, Field: "BlocksToFollow", Err: elements.ErrInvalidLength}
+	}
+
+	a.header = h
+	a.blocks = a.blocks[:0]
+	a.received = 0
+	return err
+}
+
+// finishPacket checks the CRC of a completed packet and adds its user data
+// to the message, returning the message if this was its last fragment.
+func (a *UnconfirmedDataAssembler) finishPacket() (*PacketData, error) {
+	h := a.header
+	payload, err := checkPacketCRC(a.blocks, int(h.PadOctetCount))
+	if err != nil {
+		a.Reset()
+		return nil, err
+	}
+	a.header = nil
+
+	msg := a.message
+	msg.FragmentSequenceNumbers = append(msg.FragmentSequenceNumbers, h.FragmentSequenceNumber)
+	msg.Payload = append(msg.Payload, payload...)
+
+	fsn := h.FragmentSequenceNumber
+	if fsn&fsnLastFragment != 0 || (h.FullMessage && fsn == 0) {
+		a.message = nil
+		return msg, nil
+	}
+	a.nextFSN = (fsn + 1) & fsnSequenceMask
+	return nil, nil
+}
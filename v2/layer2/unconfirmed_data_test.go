@@ -0,0 +1,247 @@
+package layer2_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/crc"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// unconfirmedPacket builds the bursts of an unconfirmed Rate 1/2 packet
+// carrying payload from source 3120001 to destination 9990.
+func unconfirmedPacket(fullMessage bool, fsn uint8, payload []byte) []*layer2.Burst {
+	const blockOctets = 12
+	blocks := (len(payload) + 4 + blockOctets - 1) / blockOctets
+	pad := blocks*blockOctets - len(payload) - 4
+
+	data := append(append([]byte{}, payload...), make([]byte, pad)...)
+	sum := crc.CalculateCRC32(data)
+	data = append(data, byte(sum), byte(sum>>8), byte(sum>>16), byte(sum>>24))
+
+	header := &pdu.UnconfirmedDataHeader{
+		PadOctetCount:          uint8(pad),
+		SAP:                    uint8(pdu.ServiceAccessPointIDShortData),
+		FullMessage:            fullMessage,
+		BlocksToFollow:         uint8(blocks),
+		FragmentSequenceNumber: fsn,
+	}
+	copy(header.LLIDDestination[:], bit.BitsFromUint32(9990, 24))
+	copy(header.LLIDSource[:], bit.BitsFromUint32(3120001, 24))
+
+	bursts := []*layer2.Burst{{
+		IsData: true,
+		Data:   &pdu.DataHeader{Format: pdu.FormatUnconfirmed, UnconfirmedDataHeader: header},
+	}}
+	for i := range blocks {
+		block := &pdu.Rate12Data{DataType: elements.DataTypeRate12}
+		copy(block.Data[:], data[i*blockOctets:])
+		bursts = append(bursts, &layer2.Burst{
+			IsData:      true,
+			HasSlotType: true,
+			SlotType:    pdu.SlotType{DataType: elements.DataTypeRate12},
+			Data:        block,
+		})
+	}
+	return bursts
+}
+
+// feedBursts passes bursts to the assembler 60 ms apart from start and
+// returns the messages and errors it reported.
+func feedBursts(a *layer2.UnconfirmedDataAssembler, start time.Time, bursts []*layer2.Burst) ([]*layer2.PacketData, []error) {
+	var msgs []*layer2.PacketData
+	var errs []error
+	for i, b := range bursts {
+		msg, err := a.AddBurst(b, start.Add(time.Duration(i)*60*time.Millisecond))
+		if msg != nil {
+			msgs = append(msgs, msg)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return msgs, errs
+}
+
+func TestUnconfirmedDataAssembler_Captures(t *testing.T) {
+	t.Parallel()
+	for _, file := range []string{"testdata/d-sms.bin", "testdata/m-sms.bin"} {
+		var a layer2.UnconfirmedDataAssembler
+		var msgs []*layer2.PacketData
+		for i, raw := range loadBursts(t, file) {
+			burst, err := layer2.NewBurstFromBytes(raw)
+			if err != nil {
+				t.Fatalf("%s burst %d: %v", file, i, err)
+			}
+			msg, err := a.AddBurst(burst, time.Unix(0, 0))
+			if err != nil {
+				t.Fatalf("%s burst %d: %v", file, i, err)
+			}
+			if msg != nil {
+				msgs = append(msgs, msg)
+			}
+		}
+
+		if len(msgs) != 1 {
+			t.Fatalf("%s: reassembled %d messages, want 1", file, len(msgs))
+		}
+		msg := msgs[0]
+		if msg.SAP != pdu.ServiceAccessPointIDIPBasedPacketData || msg.Source != 3191868 || msg.Destination != 9990 || msg.Group {
+			t.Errorf("%s: %s", file, msg.ToString())
+		}
+		// The payload is an IPv4 datagram whose total length field matches.
+		if len(msg.Payload) < 20 || msg.Payload[0] != 0x45 || int(msg.Payload[2])<<8|int(msg.Payload[3]) != len(msg.Payload) {
+			t.Errorf("%s: payload is not a whole IPv4 datagram: % X", file, msg.Payload)
+		}
+	}
+}
+
+func TestUnconfirmedDataAssembler_Fragments(t *testing.T) {
+	t.Parallel()
+	first := []byte("Hello, ")
+	second := []byte("fragmented ")
+	third := []byte("world")
+
+	var bursts []*layer2.Burst
+	bursts = append(bursts, unconfirmedPacket(true, 0b0001, first)...)
+	bursts = append(bursts, unconfirmedPacket(false, 0b0010, second)...)
+	bursts = append(bursts, unconfirmedPacket(false, 0b1011, third)...)
+
+	var a layer2.UnconfirmedDataAssembler
+	msgs, errs := feedBursts(&a, time.Unix(0, 0), bursts)
+	if len(errs) != 0 || len(msgs) != 1 {
+		t.Fatalf("got %d messages, errors %v", len(msgs), errs)
+	}
+	msg := msgs[0]
+	if string(msg.Payload) != "Hello, fragmented world" {
+		t.Errorf("Payload = %q", msg.Payload)
+	}
+	if !bytes.Equal(msg.FragmentSequenceNumbers, []byte{0b0001, 0b0010, 0b1011}) {
+		t.Errorf("FragmentSequenceNumbers = %v", msg.FragmentSequenceNumbers)
+	}
+	if msg.SAP != pdu.ServiceAccessPointIDShortData || msg.Source != 3120001 || msg.Destination != 9990 {
+		t.Errorf("%s", msg.ToString())
+	}
+}
+
+func TestUnconfirmedDataAssembler_Errors(t *testing.T) {
+	t.Parallel()
+	payload := bytes.Repeat([]byte{0xA5}, 30)
+	packet := unconfirmedPacket(true, 0, payload)
+	corrupt := unconfirmedPacket(true, 0, payload)
+	corrupt[2].Data.(*pdu.Rate12Data).Data[0] ^= 0x01
+	undecodable := unconfirmedPacket(true, 0, payload)
+	undecodable[1].Data = nil
+
+	tests := []struct {
+		name     string
+		bursts   []*layer2.Burst
+		sentinel error
+		field    string
+		missing  *layer2.MissingBlocksError
+	}{
+		{
+			name:     "CRC mismatch",
+			bursts:   corrupt,
+			sentinel: elements.ErrCRCMismatch,
+			field:    "CRC",
+		},
+		{
+			name:     "undecodable block",
+			bursts:   undecodable,
+			sentinel: elements.ErrCRCMismatch,
+			field:    "CRC",
+		},
+		{
+			name:     "interrupted by a new header",
+			bursts:   packet[:2],
+			sentinel: elements.ErrMissingBlocks,
+			missing:  &layer2.MissingBlocksError{Expected: 3, Received: 1},
+		},
+		{
+			name:     "fragment out of sequence",
+			bursts:   append(unconfirmedPacket(true, 0b0001, payload), unconfirmedPacket(false, 0b1011, payload)...),
+			sentinel: elements.ErrFragmentSequence,
+			field:    "FragmentSequenceNumber",
+		},
+		{
+			name:     "continuation without a first fragment",
+			bursts:   unconfirmedPacket(false, 0b1001, payload),
+			sentinel: elements.ErrFragmentSequence,
+			field:    "FragmentSequenceNumber",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var a layer2.UnconfirmedDataAssembler
+			// A complete packet after the failure shows the assembler recovers.
+			msgs, errs := feedBursts(&a, time.Unix(0, 0), append(append([]*layer2.Burst{}, tt.bursts...), packet...))
+			if len(errs) != 1 {
+				t.Fatalf("errors = %v, want one", errs)
+			}
+			if len(msgs) != 1 || !bytes.Equal(msgs[0].Payload, payload) {
+				t.Errorf("did not recover: %d messages", len(msgs))
+			}
+			err := errs[0]
+			if !errors.Is(err, tt.sentinel) {
+				t.Fatalf("error %v does not wrap %v", err, tt.sentinel)
+			}
+			if tt.missing != nil {
+				var missing *layer2.MissingBlocksError
+				if !errors.As(err, &missing) || *missing != *tt.missing {
+					t.Errorf("error = %#v, want %#v", err, tt.missing)
+				}
+				return
+			}
+			var pduErr *elements.PDUError
+			if !errors.As(err, &pduErr) || pduErr.Layer != elements.LayerPacket || pduErr.Field != tt.field {
+				t.Errorf("error = %v, want %s field %s", err, elements.LayerPacket, tt.field)
+			}
+		})
+	}
+}
+
+func TestUnconfirmedDataAssembler_Timeout(t *testing.T) {
+	t.Parallel()
+	payload := bytes.Repeat([]byte{0x5A}, 30)
+	packet := unconfirmedPacket(true, 0, payload)
+	start := time.Unix(0, 0)
+
+	a := layer2.UnconfirmedDataAssembler{Timeout: time.Second}
+	if _, err := a.AddBurst(packet[0], start); err != nil {
+		t.Fatal(err)
+	}
+	if err := a.Expire(start.Add(time.Second)); err != nil {
+		t.Fatalf("expired at the timeout: %v", err)
+	}
+
+	// The remaining blocks arrive too late: the packet is reported as timed
+	// out and the stray blocks are ignored.
+	msgs, errs := feedBursts(&a, start.Add(2*time.Second), packet[1:])
+	var missing *layer2.MissingBlocksError
+	if len(msgs) != 0 || len(errs) != 1 || !errors.As(errs[0], &missing) {
+		t.Fatalf("messages %d, errors %v", len(msgs), errs)
+	}
+	if !missing.TimedOut || missing.Expected != 3 || missing.Received != 0 {
+		t.Errorf("error = %+v", missing)
+	}
+
+	// A fragmented message also times out between fragments.
+	msgs, errs = feedBursts(&a, start, unconfirmedPacket(true, 0b0001, payload))
+	if len(msgs) != 0 || len(errs) != 0 {
+		t.Fatalf("first fragment: messages %d, errors %v", len(msgs), errs)
+	}
+	if err := a.Expire(start.Add(time.Minute)); !errors.Is(err, elements.ErrMissingBlocks) {
+		t.Errorf("Expire = %v, want missing blocks", err)
+	}
+	if err := a.Expire(start.Add(2 * time.Minute)); err != nil {
+		t.Errorf("second Expire = %v, want nil", err)
+	}
+}
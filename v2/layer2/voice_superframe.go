@@ -0,0 +1,213 @@
+package layer2
+
+import (
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+)
+
+// ETSI TS 102 361-1 §7.1.3 — Embedded signalling
+//
+// A voice superframe is six bursts, A through F. Burst A carries the voice
+// SYNC; bursts B–F carry the EMB in its place. When the superframe carries
+// an embedded LC, its fragments are sent in bursts B–E and the EMB LCSS
+// follows the sequence:
+//   - B: LCSS=01 (first fragment)
+//   - C: LCSS=11 (continuation fragment)
+//   - D: LCSS=11 (continuation fragment)
+//   - E: LCSS=10 (last fragment)
+//   - F: LCSS=00 (single fragment — null embedded LC or RC)
+//
+// A superframe without an embedded LC carries LCSS=00 in all of B–F.
+//
+// The tracker counts bursts on from the last voice SYNC and uses the LCSS to
+// check that count, to detect lost bursts, and to recover the position when
+// the voice SYNC of burst A was not received.
+
+// VoiceSuperframeBursts is the number of bursts, A–F, in a voice superframe.
+const VoiceSuperframeBursts = 6
+
+// VoiceBurstPosition describes where a voice burst sits in the superframe,
+// as determined by VoiceSuperframeTracker.Track.
+type VoiceBurstPosition struct {
+	// Burst is the superframe position of the burst, or
+	// enums.VoiceBurstUnknown if the tracker could not place it.
+	Burst enums.VoiceBurstType
+	// Missing is the number of superframe positions skipped between the
+	// previous voice burst on the slot and this one.
+	Missing int
+	// OutOfOrder reports a burst whose position precedes that of the
+	// previous burst in the current superframe.
+	OutOfOrder bool
+	// Resynchronized reports that the tracker had no position before this
+	// burst and recovered one from it.
+	Resynchronized bool
+}
+
+// VoiceSuperframeTracker follows the six-burst voice superframe on a single
+// timeslot and labels each voice burst A–F. Use one tracker per timeslot.
+// The zero value is ready to use and has no position until it sees either a
+// voice SYNC or an EMB whose LCSS identifies the burst unambiguously.
+type VoiceSuperframeTracker struct {
+	position int
+	synced   bool
+	// embeddedLC reports an LC fragment seen in the current superframe.
+	embeddedLC bool
+}
+
+// Reset discards the current superframe position, e.g. at the end of a
+// call.
+func (t *VoiceSuperframeTracker) Reset() {
+	t.position = 0
+	t.synced = false
+	t.embeddedLC = false
+}
+
+// Synchronized reports whether the tracker currently knows the superframe
+// position.
+func (t *VoiceSuperframeTracker) Synchronized() bool {
+	return t.synced
+}
+
+// Track places the next burst received on the slot in the voice superframe
+// and stores the result in b.VoiceBurst. Data bursts end the superframe and
+// reset the tracker. MS-sourced RC bursts are not part of a superframe and
+// leave the tracker unchanged. Both are reported as
+// enums.VoiceBurstUnknown.
+//
+// A burst that arrives late is labelled with its own position, but the
+// tracker keeps counting from the furthest burst seen, so the positions it
+// skipped over are reported as Missing before it arrives.
+func (t *VoiceSuperframeTracker) Track(b *Burst) VoiceBurstPosition {
+	var pos VoiceBurstPosition
+	switch {
+	case b.IsData:
+		t.Reset()
+		pos.Burst = enums.VoiceBurstUnknown
+	case b.SyncPattern == enums.MsSourcedRcSync:
+		pos.Burst = enums.VoiceBurstUnknown
+	case isVoiceSync(b.SyncPattern):
+		pos = t.advance(0)
+	case b.HasEmbeddedSignalling:
+		pos = t.trackEmbedded(b)
+	default:
+		pos = t.trackUnidentified()
+	}
+	b.VoiceBurst = pos.Burst
+	return pos
+}
+
+// trackEmbedded places a burst carrying an EMB. The LCSS is only trusted
+// when the EMB decoded cleanly.
+func (t *VoiceSuperframeTracker) trackEmbedded(b *Burst) VoiceBurstPosition {
+	if b.EmbeddedSignalling.FEC.Uncorrectable {
+		return t.trackUnidentified()
+	}
+	pos := t.placeEmbedded(b.EmbeddedSignalling.LCSS)
+	if b.EmbeddedSignalling.LCSS != enums.SingleFragmentLCorCSBK && pos.Burst != enums.VoiceBurstUnknown && !pos.OutOfOrder {
+		t.embeddedLC = true
+	}
+	return pos
+}
+
+// placeEmbedded places a burst by the LCSS of its EMB.
+func (t *VoiceSuperframeTracker) placeEmbedded(lcss enums.LCSS) VoiceBurstPosition {
+	expected := (t.position + 1) % VoiceSuperframeBursts
+	if t.synced && lcssMatchesPosition(lcss, expected) {
+		return t.advance(expected)
+	}
+	if t.synced && lcss == enums.SingleFragmentLCorCSBK && expected != 0 && !t.embeddedLC {
+		// A superframe without an embedded LC, which identifies none of
+		// B–E; count on as for a burst without an EMB.
+		return t.advance(expected)
+	}
+	if lcss == enums.SingleFragmentLCorCSBK && (!t.synced || !t.embeddedLC) {
+		// LCSS=00 only marks F after the fragments of an embedded LC; a
+		// superframe without one carries it in all of B–F.
+		return VoiceBurstPosition{Burst: enums.VoiceBurstUnknown}
+	}
+
+	next, ok := positionFromLCSS(lcss, t.position, t.synced)
+	if !ok {
+		if !t.synced {
+			return VoiceBurstPosition{Burst: enums.VoiceBurstUnknown}
+		}
+		// A continuation fragment that can only belong earlier in this
+		// superframe; C and D cannot be told apart.
+		return VoiceBurstPosition{Burst: enums.VoiceBurstUnknown, OutOfOrder: true}
+	}
+	if t.synced && next != 0 && next <= t.position && t.position != VoiceSuperframeBursts-1 {
+		return VoiceBurstPosition{Burst: voiceBurstAt(next), OutOfOrder: true}
+	}
+	return t.advance(next)
+}
+
+// trackUnidentified places a voice burst that carries nothing identifying
+// its position by counting on from the previous burst.
+func (t *VoiceSuperframeTracker) trackUnidentified() VoiceBurstPosition {
+	if !t.synced {
+		return VoiceBurstPosition{Burst: enums.VoiceBurstUnknown}
+	}
+	return t.advance((t.position + 1) % VoiceSuperframeBursts)
+}
+
+// advance moves the tracker to position next and reports the burst there.
+func (t *VoiceSuperframeTracker) advance(next int) VoiceBurstPosition {
+	pos := VoiceBurstPosition{Burst: voiceBurstAt(next)}
+	if !t.synced || next <= t.position {
+		t.embeddedLC = false
+	}
+	if t.synced {
+		pos.Missing = (next - t.position - 1 + VoiceSuperframeBursts) % VoiceSuperframeBursts
+	} else {
+		pos.Resynchronized = true
+	}
+	t.position = next
+	t.synced = true
+	return pos
+}
+
+// lcssMatchesPosition reports whether lcss is the value carried by the
+// superframe position index (0 = A).
+func lcssMatchesPosition(lcss enums.LCSS, index int) bool {
+	switch index {
+	case 1:
+		return lcss == enums.FirstFragmentLC
+	case 2, 3:
+		return lcss == enums.ContinuationFragmentLCorCSBK
+	case 4:
+		return lcss == enums.LastFragmentLCorCSBK
+	case 5:
+		return lcss == enums.SingleFragmentLCorCSBK
+	}
+	return false
+}
+
+// positionFromLCSS returns the superframe position implied by lcss. A
+// continuation fragment could be C or D; when the tracker has a position it
+// picks whichever comes next after current, otherwise it cannot decide.
+func positionFromLCSS(lcss enums.LCSS, current int, synced bool) (int, bool) {
+	switch lcss {
+	case enums.FirstFragmentLC:
+		return 1, true
+	case enums.LastFragmentLCorCSBK:
+		return 4, true
+	case enums.SingleFragmentLCorCSBK:
+		return 5, true
+	case enums.ContinuationFragmentLCorCSBK:
+		if !synced {
+			return 0, false
+		}
+		switch current {
+		case 0, 1, 5:
+			return 2, true
+		case 2:
+			return 3, true
+		}
+		return 0, false
+	}
+	return 0, false
+}
+
+// voiceBurstAt returns the VoiceBurstType for superframe position index.
+func voiceBurstAt(index int) enums.VoiceBurstType {
+	return enums.VoiceBurstA + enums.VoiceBurstType(index)
+}
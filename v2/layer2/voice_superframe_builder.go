@@ -0,0 +1,105 @@
+package layer2
+
+import (
+	"github.com/USA-RedDragon/dmrgo/v2/bit"
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+	"github.com/USA-RedDragon/dmrgo/v2/vocoder"
+)
+
+// VoiceSuperframeFrames is the number of vocoder frames carried by one voice
+// superframe (three per burst).
+const VoiceSuperframeFrames = 3 * VoiceSuperframeBursts
+
+// VoiceSuperframe describes one six-burst voice superframe to be built by
+// BuildVoiceSuperframe.
+type VoiceSuperframe struct {
+	// SyncPattern is the voice SYNC sent in burst A, e.g.
+	// enums.BsSourcedVoice or enums.MsSourcedVoice.
+	SyncPattern enums.SyncPattern
+	// ColorCode is sent in the EMB of bursts B–F.
+	ColorCode int
+	// PreemptionAndPowerControlIndicator is sent in the EMB of bursts B–E,
+	// and of burst F unless it carries an RC.
+	PreemptionAndPowerControlIndicator bool
+	// LC is the Full LC embedded in bursts B–E. When nil, bursts B–E carry
+	// the null embedded LC with LCSS single fragment.
+	LC *pdu.FullLinkControl
+	// ReverseChannel, when set, is carried in burst F (§6.4.2). Otherwise
+	// burst F carries the null embedded LC.
+	ReverseChannel *pdu.ReverseChannel
+	// Frames are the 18 vocoder frames of the superframe, three per burst
+	// starting with burst A.
+	Frames [VoiceSuperframeFrames]vocoder.VocoderFrame
+}
+
+// Bursts returns the six bursts A–F of the superframe. Bursts B–E carry the
+// embedded LC fragments with LCSS first, continuation, continuation and last;
+// burst F carries either the RC, with PI set and LCSS single fragment, or
+// the null embedded LC.
+func (sf *VoiceSuperframe) Bursts() [VoiceSuperframeBursts]Burst {
+	var bursts [VoiceSuperframeBursts]Burst
+
+	var fragments [EmbeddedLCFragments][32]bit.Bit
+	lcss := [EmbeddedLCFragments]enums.LCSS{
+		enums.FirstFragmentLC,
+		enums.ContinuationFragmentLCorCSBK,
+		enums.ContinuationFragmentLCorCSBK,
+		enums.LastFragmentLCorCSBK,
+	}
+	if sf.LC != nil {
+		fragments = EncodeEmbeddedLCToFragments(sf.LC)
+	} else {
+		for i := range lcss {
+			fragments[i] = NullEmbeddedLCBits
+			lcss[i] = enums.SingleFragmentLCorCSBK
+		}
+	}
+
+	for i := range bursts {
+		b := &bursts[i]
+		b.VoiceBurst = voiceBurstAt(i)
+		copy(b.VoiceData.Frames[:], sf.Frames[i*3:i*3+3])
+		if i == 0 {
+			b.SyncPattern = sf.SyncPattern
+			continue
+		}
+
+		b.SyncPattern = enums.EmbeddedSignallingPattern
+		b.HasEmbeddedSignalling = true
+		b.EmbeddedSignalling = pdu.EmbeddedSignalling{
+			ColorCode:                          sf.ColorCode,
+			PreemptionAndPowerControlIndicator: sf.PreemptionAndPowerControlIndicator,
+			LCSS:                               enums.SingleFragmentLCorCSBK,
+		}
+		if i <= EmbeddedLCFragments {
+			b.EmbeddedSignalling.LCSS = lcss[i-1]
+			b.EmbeddedSignallingData = fragments[i-1]
+			continue
+		}
+
+		if sf.ReverseChannel != nil {
+			b.EmbeddedSignalling.PreemptionAndPowerControlIndicator = true
+			b.HasReverseChannel = true
+			b.ReverseChannel = sf.ReverseChannel
+		} else {
+			b.EmbeddedSignallingData = NullEmbeddedLCBits
+		}
+	}
+
+	return bursts
+}
+
+// BuildVoiceSuperframe encodes the six bursts A–F of a voice superframe.
+func BuildVoiceSuperframe(sf *VoiceSuperframe) ([VoiceSuperframeBursts][33]byte, error) {
+	var out [VoiceSuperframeBursts][33]byte
+	bursts := sf.Bursts()
+	for i := range bursts {
+		encoded, err := bursts[i].Encode()
+		if err != nil {
+			return out, err
+		}
+		out[i] = encoded
+	}
+	return out, nil
+}
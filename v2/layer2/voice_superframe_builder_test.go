@@ -0,0 +1,164 @@
+package layer2_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// capturedSuperframe decodes the first complete voice superframe in
+// voice.bin and returns its raw bursts with a VoiceSuperframe describing it.
+func capturedSuperframe(t *testing.T) ([6][33]byte, *layer2.VoiceSuperframe) {
+	t.Helper()
+	header := firstDataBurst(t, "testdata/voice.bin", elements.DataTypeVoiceLCHeader)
+	lc, ok := header.Data.(*pdu.FullLinkControl)
+	if !ok {
+		t.Fatalf("voice LC header Data = %T", header.Data)
+	}
+
+	raws := loadBursts(t, "testdata/voice.bin")
+	for start := range raws {
+		first, err := layer2.NewBurstFromBytes(raws[start])
+		if err != nil || first.VoiceBurst != enums.VoiceBurstA || start+6 > len(raws) {
+			continue
+		}
+		var raw [6][33]byte
+		sf := &layer2.VoiceSuperframe{SyncPattern: first.SyncPattern, LC: lc}
+		for i := range raw {
+			raw[i] = raws[start+i]
+			burst, err := layer2.NewBurstFromBytes(raw[i])
+			if err != nil {
+				t.Fatalf("burst %d: %v", start+i, err)
+			}
+			copy(sf.Frames[i*3:], burst.VoiceData.Frames[:])
+			if i == 1 {
+				sf.ColorCode = burst.EmbeddedSignalling.ColorCode
+				sf.PreemptionAndPowerControlIndicator = burst.EmbeddedSignalling.PreemptionAndPowerControlIndicator
+			}
+		}
+		return raw, sf
+	}
+	t.Fatal("no voice superframe in testdata/voice.bin")
+	return [6][33]byte{}, nil
+}
+
+func TestBuildVoiceSuperframe_MatchesCapture(t *testing.T) {
+	t.Parallel()
+	raw, sf := capturedSuperframe(t)
+
+	built, err := layer2.BuildVoiceSuperframe(sf)
+	if err != nil {
+		t.Fatalf("BuildVoiceSuperframe: %v", err)
+	}
+	for i := range built {
+		if built[i] != raw[i] {
+			t.Errorf("burst %s:\n got %x\nwant %x", enums.VoiceBurstTypeToName(enums.VoiceBurstA+enums.VoiceBurstType(i)), built[i], raw[i])
+		}
+	}
+}
+
+func TestEncodeEmbeddedLCToFragments_RoundTrip(t *testing.T) {
+	t.Parallel()
+	flc := testGroupVoiceFLC()
+	fragments := layer2.EncodeEmbeddedLCToFragments(flc)
+	if fragments != buildEmbeddedLCFragments(flc) {
+		t.Error("fragments do not match the reference layout")
+	}
+
+	decoded, result := layer2.DecodeEmbeddedLCFromFragments(fragments)
+	if result.Uncorrectable || result.ErrorsCorrected != 0 {
+		t.Fatalf("FEC = %+v, want clean", result)
+	}
+	if decoded.GroupVoice == nil || *decoded.GroupVoice != *flc.GroupVoice {
+		t.Errorf("decoded %s", decoded.ToString())
+	}
+}
+
+func TestBuildVoiceSuperframe_DecodesBack(t *testing.T) {
+	t.Parallel()
+	rc := &pdu.ReverseChannel{RCCommand: enums.RCCeaseTransmissionCommand}
+	sf := &layer2.VoiceSuperframe{
+		SyncPattern:    enums.MsSourcedVoice,
+		ColorCode:      5,
+		LC:             testGroupVoiceFLC(),
+		ReverseChannel: rc,
+	}
+	sf.Frames[7].DecodedBits[0] = 1
+
+	built, err := layer2.BuildVoiceSuperframe(sf)
+	if err != nil {
+		t.Fatalf("BuildVoiceSuperframe: %v", err)
+	}
+
+	wantLCSS := []enums.LCSS{
+		enums.FirstFragmentLC,
+		enums.ContinuationFragmentLCorCSBK,
+		enums.ContinuationFragmentLCorCSBK,
+		enums.LastFragmentLCorCSBK,
+		enums.SingleFragmentLCorCSBK,
+	}
+	var tracker layer2.VoiceSuperframeTracker
+	var assembler layer2.EmbeddedLCAssembler
+	embeddedLCs := 0
+	for i, raw := range built {
+		burst, err := layer2.NewBurstFromBytes(raw)
+		if err != nil {
+			t.Fatalf("burst %d: %v", i, err)
+		}
+		pos := tracker.Track(burst)
+		if want := enums.VoiceBurstA + enums.VoiceBurstType(i); pos.Burst != want || pos.Missing != 0 {
+			t.Errorf("burst %d: tracked as %+v, want %s", i, pos, enums.VoiceBurstTypeToName(want))
+		}
+		// Frame 7 is the second frame of burst C.
+		if got := burst.VoiceData.Frames[1].DecodedBits[0]; (got == 1) != (i == 2) {
+			t.Errorf("burst %d: vocoder frames out of place", i)
+		}
+
+		if i == 0 {
+			if burst.SyncPattern != enums.MsSourcedVoice {
+				t.Errorf("burst A SYNC = %s", enums.SyncPatternToName(burst.SyncPattern))
+			}
+			continue
+		}
+		emb := burst.EmbeddedSignalling
+		if emb.ColorCode != 5 || emb.LCSS != wantLCSS[i-1] {
+			t.Errorf("burst %d: EMB = %s", i, emb.ToString())
+		}
+		if i == 5 {
+			if !emb.PreemptionAndPowerControlIndicator || !burst.HasReverseChannel || burst.ReverseChannel.RCCommand != rc.RCCommand {
+				t.Errorf("burst F: EMB = %s, RC = %v", emb.ToString(), burst.ReverseChannel)
+			}
+		} else if emb.PreemptionAndPowerControlIndicator {
+			t.Errorf("burst %d: PI set", i)
+		}
+
+		if assembler.AddBurst(burst) {
+			embeddedLCs++
+			flc, result := assembler.Complete()
+			if result.Uncorrectable || flc.GroupVoice == nil || flc.GroupVoice.GroupAddress != 91 {
+				t.Errorf("embedded LC %s, FEC %+v", flc.ToString(), result)
+			}
+		}
+	}
+	if embeddedLCs != 1 {
+		t.Errorf("decoded %d embedded LCs, want 1", embeddedLCs)
+	}
+}
+
+func TestBuildVoiceSuperframe_NullEmbeddedLC(t *testing.T) {
+	t.Parallel()
+	sf := &layer2.VoiceSuperframe{SyncPattern: enums.BsSourcedVoice, ColorCode: 1}
+	bursts := sf.Bursts()
+	for i := 1; i < len(bursts); i++ {
+		b := bursts[i]
+		if b.EmbeddedSignalling.LCSS != enums.SingleFragmentLCorCSBK || !layer2.IsNullEmbeddedLC(b.EmbeddedSignallingData) {
+			t.Errorf("burst %d: EMB = %s, want null embedded LC", i, b.EmbeddedSignalling.ToString())
+		}
+		if b.VoiceBurst != enums.VoiceBurstA+enums.VoiceBurstType(i) {
+			t.Errorf("burst %d: VoiceBurst = %s", i, enums.VoiceBurstTypeToName(b.VoiceBurst))
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package layer2_test
+
+import (
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/enums"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/pdu"
+)
+
+// superframeBurst builds the voice burst for position (0 = A) of a superframe
+// carrying an embedded LC.
+func superframeBurst(position int) *layer2.Burst {
+	if position == 0 {
+		return &layer2.Burst{SyncPattern: enums.BsSourcedVoice}
+	}
+	lcss := [...]enums.LCSS{
+		1: enums.FirstFragmentLC,
+		2: enums.ContinuationFragmentLCorCSBK,
+		3: enums.ContinuationFragmentLCorCSBK,
+		4: enums.LastFragmentLCorCSBK,
+		5: enums.SingleFragmentLCorCSBK,
+	}[position]
+	return &layer2.Burst{
+		SyncPattern:           enums.EmbeddedSignallingPattern,
+		HasEmbeddedSignalling: true,
+		EmbeddedSignalling:    pdu.EmbeddedSignalling{LCSS: lcss},
+	}
+}
+
+func voiceBurstName(position int) string {
+	return enums.VoiceBurstTypeToName(enums.VoiceBurstA + enums.VoiceBurstType(position))
+}
+
+// The voice.bin capture is missing the burst A that should follow burst 48,
+// so the tracker must recover the superframe from the LCSS of burst 49.
+func TestVoiceSuperframeTracker_CapturedVoice(t *testing.T) {
+	t.Parallel()
+	const lostSyncBurst = 49
+	var tracker layer2.VoiceSuperframeTracker
+	next := 0
+	voiceBursts := 0
+	for i, raw := range loadBursts(t, "testdata/voice.bin") {
+		burst, err := layer2.NewBurstFromBytes(raw)
+		if err != nil {
+			t.Fatalf("burst %d: %v", i, err)
+		}
+		pos := tracker.Track(burst)
+		if burst.IsData {
+			if pos.Burst != enums.VoiceBurstUnknown || tracker.Synchronized() {
+				t.Errorf("burst %d: data burst labelled %s", i, enums.VoiceBurstTypeToName(pos.Burst))
+			}
+			next = 0
+			continue
+		}
+		voiceBursts++
+		wantMissing := 0
+		if i == lostSyncBurst {
+			wantMissing = 1
+			next = (next + 1) % 6
+		}
+		want := enums.VoiceBurstA + enums.VoiceBurstType(next)
+		if pos.Burst != want || burst.VoiceBurst != want {
+			t.Errorf("burst %d: labelled %s, want %s", i, enums.VoiceBurstTypeToName(pos.Burst), enums.VoiceBurstTypeToName(want))
+		}
+		if pos.Missing != wantMissing || pos.OutOfOrder {
+			t.Errorf("burst %d: %+v, want Missing = %d in order", i, pos, wantMissing)
+		}
+		next = (next + 1) % 6
+	}
+	if voiceBursts == 0 {
+		t.Fatal("no voice bursts in testdata/voice.bin")
+	}
+}
+
+func TestVoiceSuperframeTracker_Sequences(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		positions []int
+		// want holds the expected label position for each burst, or -1 for
+		// enums.VoiceBurstUnknown.
+		want       []int
+		missing    []int
+		outOfOrder []bool
+	}{
+		{
+			name:       "in order across superframes",
+			positions:  []int{0, 1, 2, 3, 4, 5, 0, 1},
+			want:       []int{0, 1, 2, 3, 4, 5, 0, 1},
+			missing:    []int{0, 0, 0, 0, 0, 0, 0, 0},
+			outOfOrder: []bool{false, false, false, false, false, false, false, false},
+		},
+		{
+			name:       "missing burst",
+			positions:  []int{0, 1, 2, 4, 5},
+			want:       []int{0, 1, 2, 4, 5},
+			missing:    []int{0, 0, 0, 1, 0},
+			outOfOrder: []bool{false, false, false, false, false},
+		},
+		{
+			name:       "lost voice sync",
+			positions:  []int{0, 1, 2, 3, 4, 5, 1, 2},
+			want:       []int{0, 1, 2, 3, 4, 5, 1, 2},
+			missing:    []int{0, 0, 0, 0, 0, 0, 1, 0},
+			outOfOrder: []bool{false, false, false, false, false, false, false, false},
+		},
+		{
+			name:       "lost fragments before F",
+			positions:  []int{0, 1, 5, 0},
+			want:       []int{0, 1, 5, 0},
+			missing:    []int{0, 0, 3, 0},
+			outOfOrder: []bool{false, false, false, false},
+		},
+		{
+			name:       "voice sync after missing tail",
+			positions:  []int{0, 1, 2, 0},
+			want:       []int{0, 1, 2, 0},
+			missing:    []int{0, 0, 0, 3},
+			outOfOrder: []bool{false, false, false, false},
+		},
+		{
+			name:       "late burst",
+			positions:  []int{0, 1, 4, 2, 5},
+			want:       []int{0, 1, 4, -1, 5},
+			missing:    []int{0, 0, 2, 0, 0},
+			outOfOrder: []bool{false, false, false, true, false},
+		},
+		{
+			name:       "late first fragment",
+			positions:  []int{0, 2, 1, 3},
+			want:       []int{0, 2, 1, 3},
+			missing:    []int{0, 1, 0, 0},
+			outOfOrder: []bool{false, false, true, false},
+		},
+		{
+			name:       "late entry",
+			positions:  []int{3, 4, 5, 0},
+			want:       []int{-1, 4, 5, 0},
+			missing:    []int{0, 0, 0, 0},
+			outOfOrder: []bool{false, false, false, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var tracker layer2.VoiceSuperframeTracker
+			for i, p := range tt.positions {
+				pos := tracker.Track(superframeBurst(p))
+				want := enums.VoiceBurstUnknown
+				if tt.want[i] >= 0 {
+					want = enums.VoiceBurstA + enums.VoiceBurstType(tt.want[i])
+				}
+				if pos.Burst != want {
+					t.Errorf("burst %d (%s): labelled %s, want %s", i, voiceBurstName(p),
+						enums.VoiceBurstTypeToName(pos.Burst), enums.VoiceBurstTypeToName(want))
+				}
+				if pos.Missing != tt.missing[i] {
+					t.Errorf("burst %d (%s): Missing = %d, want %d", i, voiceBurstName(p), pos.Missing, tt.missing[i])
+				}
+				if pos.OutOfOrder != tt.outOfOrder[i] {
+					t.Errorf("burst %d (%s): OutOfOrder = %v, want %v", i, voiceBurstName(p), pos.OutOfOrder, tt.outOfOrder[i])
+				}
+			}
+		})
+	}
+}
+
+func TestVoiceSuperframeTracker_Resynchronized(t *testing.T) {
+	t.Parallel()
+	var tracker layer2.VoiceSuperframeTracker
+	if pos := tracker.Track(superframeBurst(2)); pos.Resynchronized || tracker.Synchronized() {
+		t.Errorf("continuation fragment without a position: %+v", pos)
+	}
+	if pos := tracker.Track(superframeBurst(1)); !pos.Resynchronized || pos.Burst != enums.VoiceBurstB {
+		t.Errorf("first fragment: %+v, want resynchronized at B", pos)
+	}
+	if pos := tracker.Track(superframeBurst(2)); pos.Resynchronized {
+		t.Errorf("second burst after resync: %+v", pos)
+	}
+
+	tracker.Track(&layer2.Burst{IsData: true})
+	if tracker.Synchronized() {
+		t.Error("data burst did not reset the tracker")
+	}
+	if pos := tracker.Track(superframeBurst(0)); !pos.Resynchronized || pos.Burst != enums.VoiceBurstA {
+		t.Errorf("voice sync after data: %+v, want resynchronized at A", pos)
+	}
+}
+
+func TestVoiceSuperframeTracker_UncorrectableEMBCountsOn(t *testing.T) {
+	t.Parallel()
+	var tracker layer2.VoiceSuperframeTracker
+	tracker.Track(superframeBurst(0))
+
+	burst := superframeBurst(1)
+	burst.EmbeddedSignalling.LCSS = enums.LastFragmentLCorCSBK
+	burst.EmbeddedSignalling.FEC.Uncorrectable = true
+	if pos := tracker.Track(burst); pos.Burst != enums.VoiceBurstB || pos.Missing != 0 {
+		t.Errorf("uncorrectable EMB: %+v, want B counted on from A", pos)
+	}
+}
+
+func TestVoiceSuperframeTracker_IgnoresRCBurst(t *testing.T) {
+	t.Parallel()
+	var tracker layer2.VoiceSuperframeTracker
+	tracker.Track(superframeBurst(0))
+
+	rc := &layer2.Burst{SyncPattern: enums.MsSourcedRcSync, HasEmbeddedSignalling: true}
+	if pos := tracker.Track(rc); pos.Burst != enums.VoiceBurstUnknown {
+		t.Errorf("RC burst labelled %s", enums.VoiceBurstTypeToName(pos.Burst))
+	}
+	if pos := tracker.Track(superframeBurst(1)); pos.Burst != enums.VoiceBurstB || pos.Missing != 0 {
+		t.Errorf("burst after RC: %+v, want B", pos)
+	}
+}
+
+// Without an embedded LC every EMB burst carries LCSS=00, so B–E are
+// placed by counting on from burst A.
+func TestVoiceSuperframeTracker_NullEmbeddedLC(t *testing.T) {
+	t.Parallel()
+	raw, err := layer2.BuildVoiceSuperframe(&layer2.VoiceSuperframe{SyncPattern: enums.BsSourcedVoice, ColorCode: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tracker layer2.VoiceSuperframeTracker
+	for superframe := range 2 {
+		for i := range raw {
+			burst, err := layer2.NewBurstFromBytes(raw[i])
+			if err != nil {
+				t.Fatalf("burst %d: %v", i, err)
+			}
+			pos := tracker.Track(burst)
+			want := enums.VoiceBurstA + enums.VoiceBurstType(i)
+			if pos.Burst != want || pos.Missing != 0 || pos.OutOfOrder {
+				t.Errorf("superframe %d burst %d: %+v, want %s with none missing", superframe, i, pos, enums.VoiceBurstTypeToName(want))
+			}
+		}
+	}
+}
+
+// Joining a superframe without an embedded LC mid-way, LCSS=00 does not
+// identify F, so the bursts before the next voice SYNC stay unplaced.
+func TestVoiceSuperframeTracker_NullEmbeddedLCLateEntry(t *testing.T) {
+	t.Parallel()
+	raw, err := layer2.BuildVoiceSuperframe(&layer2.VoiceSuperframe{SyncPattern: enums.BsSourcedVoice, ColorCode: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tracker layer2.VoiceSuperframeTracker
+	for i := 2; i < 2*len(raw); i++ {
+		burst, err := layer2.NewBurstFromBytes(raw[i%len(raw)])
+		if err != nil {
+			t.Fatalf("burst %d: %v", i, err)
+		}
+		pos := tracker.Track(burst)
+		want := layer2.VoiceBurstPosition{Burst: enums.VoiceBurstUnknown}
+		if i >= len(raw) {
+			want.Burst = enums.VoiceBurstA + enums.VoiceBurstType(i%len(raw))
+			want.Resynchronized = i == len(raw)
+		}
+		if pos != want {
+			t.Errorf("burst %d: %+v, want %+v", i, pos, want)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+// Package ars decodes and encodes Motorola Automatic Registration Service
+// (ARS) messages, carried as UDP datagrams to port 4005 over DMR packet
+// data, and tracks which radios are registered.
+package ars
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+)
+
+// Port is the UDP port of the registration service.
+const Port = 4005
+
+// Type is the PDU type of a message, from the low 4 bits of its first
+// header octet.
+type Type uint8
+
+const (
+	// TypeDeviceRegistration announces a radio that has powered on or is
+	// refreshing its registration.
+	TypeDeviceRegistration Type = 0x0
+	// TypeDeviceDeregistration announces a radio that is powering off.
+	TypeDeviceDeregistration Type = 0x1
+	// TypeQuery asks a radio to register again.
+	TypeQuery Type = 0x4
+	// TypeRegistrationAck accepts or refuses a registration.
+	TypeRegistrationAck Type = 0xF
+)
+
+// TypeToName returns the name of a PDU type.
+func TypeToName(t Type) string {
+	switch t {
+	case TypeDeviceRegistration:
+		return "Device Registration"
+	case TypeDeviceDeregistration:
+		return "Device Deregistration"
+	case TypeQuery:
+		return "Query"
+	case TypeRegistrationAck:
+		return "Registration Acknowledgement"
+	}
+	return fmt.Sprintf("Unknown (0x%X)", uint8(t))
+}
+
+// Event is the event of a registration, from its second header octet.
+type Event uint8
+
+const (
+	// EventUnqualified is a registration that gives no reason.
+	EventUnqualified Event = 0
+	// EventInitial is the first registration after the radio powers on.
+	EventInitial Event = 1
+	// EventRefresh renews a registration.
+	EventRefresh Event = 2
+)
+
+// EventToName returns the name of a registration event.
+func EventToName(e Event) string {
+	switch e {
+	case EventUnqualified:
+		return "Unqualified"
+	case EventInitial:
+		return "Initial"
+	case EventRefresh:
+		return "Refresh"
+	}
+	return fmt.Sprintf("Unknown (%d)", uint8(e))
+}
+
+// Header layout. Each header octet has an extension bit announcing the
+// next one. The second octet of a registration carries its event and
+// encoding, and that of an acknowledgement its result.
+const (
+	lengthOctets = 2
+
+	headerExtension = 0x80
+
+	header1AckRequired = 0x40
+	header1Priority    = 0x20
+	header1Control     = 0x10
+	header1TypeMask    = 0x0F
+
+	registrationEventShift = 5
+	registrationEventMask  = 0x60
+	registrationEncoding   = 0x1F
+
+	ackRefused    = 0x40
+	ackReasonMask = 0x3F
+
+	maxFieldOctets = 0xFF
+)
+
+// Message is an ARS message.
+type Message struct {
+	// Source and Destination are the radio IDs of the sender and of the
+	// radio or server the datagram is addressed to, from its IPv4
+	// addresses.
+	Source      int
+	Destination int
+	Type        Type
+	// AckRequired asks the destination to acknowledge a registration.
+	AckRequired bool
+	// Priority and Control are the flags of the first header octet,
+	// carried as received.
+	Priority bool
+	Control  bool
+	// Event and Encoding are the event of a registration and the code of
+	// the character encoding of its identifiers, carried as received.
+	Event    Event
+	Encoding uint8
+	// DeviceID, UserID and Password are the identifiers a registration or
+	// deregistration carries; radios send their radio ID in decimal as
+	// DeviceID.
+	DeviceID string
+	UserID   string
+	Password string
+	// Refused and Reason are the result of an acknowledgement: whether
+	// the registration was refused, and the 6-bit reason code, carried as
+	// received.
+	Refused bool
+	Reason  uint8
+}
+
+// NewQuery returns a query from a server asking a radio to register.
+func NewQuery(source, destination int) *Message {
+	return &Message{Source: source, Destination: destination, Type: TypeQuery}
+}
+
+// ToString returns a string representation of the message.
+func (m *Message) ToString() string {
+	return fmt.Sprintf("Message{ Source: %d, Destination: %d, Type: %s, AckRequired: %t, Priority: %t, Control: %t, Event: %s, Encoding: %d, DeviceID: %q, UserID: %q, Refused: %t, Reason: %d }",
+		m.Source, m.Destination, TypeToName(m.Type), m.AckRequired, m.Priority, m.Control, EventToName(m.Event), m.Encoding, m.DeviceID, m.UserID, m.Refused, m.Reason)
+}
+
+// Ack returns the acknowledgement that accepts a registration, sent back
+// to its source.
+func (m *Message) Ack() *Message {
+	return &Message{
+		Source:      m.Destination,
+		Destination: m.Source,
+		Type:        TypeRegistrationAck,
+	}
+}
+
+// DecodeDatagram returns the message carried by a UDP/IPv4 datagram to
+// Port, taking the radio IDs from its addresses on the networks of
+// addresses.
+//
+// Errors are an *elements.PDUError: as for
+// IPv4AddressContext.DecodeRadioDatagram, or as for Decode.
+func DecodeDatagram(datagram []byte, addresses *layer2.IPv4AddressContext) (*Message, error) {
+	d, err := addresses.DecodeRadioDatagram(datagram, Port)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Decode(d.Payload)
+	if err != nil {
+		return nil, err
+	}
+	m.Source = d.Source
+	m.Destination = d.Destination
+	return m, nil
+}
+
+// Decode returns the message carried by a UDP payload, with Source and
+// Destination zero.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrInvalidLength for a
+// truncated message.
+func Decode(payload []byte) (*Message, error) {
+	if len(payload) < lengthOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	n := int(binary.BigEndian.Uint16(payload))
+	if n == 0 || lengthOctets+n > len(payload) {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	data := payload[lengthOctets : lengthOctets+n]
+
+	h := data[0]
+	m := &Message{
+		Type:        Type(h & header1TypeMask),
+		AckRequired: h&header1AckRequired != 0,
+		Priority:    h&header1Priority != 0,
+		Control:     h&header1Control != 0,
+	}
+	extended := h&headerExtension != 0
+	data = data[1:]
+
+	if extended {
+		if len(data) < 1 {
+			return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Header", Err: elements.ErrInvalidLength}
+		}
+		h = data[0]
+		switch m.Type {
+		case TypeDeviceRegistration:
+			m.Event = Event((h & registrationEventMask) >> registrationEventShift)
+			m.Encoding = h & registrationEncoding
+		case TypeRegistrationAck:
+			m.Refused = h&ackRefused != 0
+			m.Reason = h & ackReasonMask
+		}
+		extended = h&headerExtension != 0
+		data = data[1:]
+	}
+	// Further header octets are not defined; skip them.
+	for extended {
+		if len(data) < 1 {
+			return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Header", Err: elements.ErrInvalidLength}
+		}
+		extended = data[0]&headerExtension != 0
+		data = data[1:]
+	}
+
+	if m.Type != TypeDeviceRegistration && m.Type != TypeDeviceDeregistration {
+		return m, nil
+	}
+	// The identifiers are each a length octet and that many octets; those
+	// at the end may be left out.
+	for _, field := range []struct {
+		name string
+		s    *string
+	}{{"DeviceID", &m.DeviceID}, {"UserID", &m.UserID}, {"Password", &m.Password}} {
+		if len(data) == 0 {
+			break
+		}
+		if 1+int(data[0]) > len(data) {
+			return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: field.name, Err: elements.ErrInvalidLength}
+		}
+		*field.s = string(data[1 : 1+int(data[0])])
+		data = data[1+int(data[0]):]
+	}
+	return m, nil
+}
+
+// Encode returns the UDP payload that carries the message. Errors are an
+// *elements.PDUError wrapping elements.ErrInvalidEncoding for a type,
+// event, encoding or reason too large for its field, or
+// elements.ErrInvalidLength for an identifier longer than 255 octets.
+func (m *Message) Encode() ([]byte, error) {
+	switch {
+	case uint8(m.Type) > header1TypeMask:
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Type", Err: elements.ErrInvalidEncoding}
+	case m.Event > registrationEventMask>>registrationEventShift:
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Event", Err: elements.ErrInvalidEncoding}
+	case m.Encoding > registrationEncoding:
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Encoding", Err: elements.ErrInvalidEncoding}
+	case m.Reason > ackReasonMask:
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Reason", Err: elements.ErrInvalidEncoding}
+	}
+
+	h1 := byte(m.Type)
+	if m.AckRequired {
+		h1 |= header1AckRequired
+	}
+	if m.Priority {
+		h1 |= header1Priority
+	}
+	if m.Control {
+		h1 |= header1Control
+	}
+	data := []byte{h1}
+	switch m.Type {
+	case TypeDeviceRegistration:
+		data[0] |= headerExtension
+		data = append(data, byte(m.Event)<<registrationEventShift|m.Encoding)
+	case TypeRegistrationAck:
+		h2 := m.Reason
+		if m.Refused {
+			h2 |= ackRefused
+		}
+		data[0] |= headerExtension
+		data = append(data, h2)
+	}
+
+	if m.Type == TypeDeviceRegistration || m.Type == TypeDeviceDeregistration {
+		// Identifiers are sent up to the last one that is set.
+		fields := []struct {
+			name, s string
+		}{{"DeviceID", m.DeviceID}, {"UserID", m.UserID}, {"Password", m.Password}}
+		for len(fields) > 0 && fields[len(fields)-1].s == "" {
+			fields = fields[:len(fields)-1]
+		}
+		for _, f := range fields {
+			if len(f.s) > maxFieldOctets {
+				return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: f.name, Err: elements.ErrInvalidLength}
+			}
+			data = append(data, byte(len(f.s)))
+			data = append(data, f.s...)
+		}
+	}
+
+	payload := make([]byte, 0, lengthOctets+len(data))
+	payload = binary.BigEndian.AppendUint16(payload, uint16(len(data))) //nolint:gosec // at most 3 identifiers of 255 octets
+	return append(payload, data...), nil
+}
+
+// Datagram returns the UDP/IPv4 datagram that carries the message from
+// Source to Destination on the networks of addresses, between Port at both
+// ends, with the IPv4 identification given. Errors are as for Encode and
+// IPv4AddressContext.EncodeRadioDatagram.
+func (m *Message) Datagram(addresses *layer2.IPv4AddressContext, identification uint16) ([]byte, error) {
+	payload, err := m.Encode()
+	if err != nil {
+		return nil, err
+	}
+	d := layer2.RadioDatagram{Source: m.Source, Destination: m.Destination, Payload: payload}
+	return addresses.EncodeRadioDatagram(&d, Port, identification)
+}
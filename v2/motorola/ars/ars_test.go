@@ -0,0 +1,181 @@
+package ars_test
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/motorola/ars"
+)
+
+// registration is a radio's first registration after power on, asking to
+// be acknowledged.
+func registration() *ars.Message {
+	return &ars.Message{
+		Source:      3120001,
+		Destination: 9990,
+		Type:        ars.TypeDeviceRegistration,
+		AckRequired: true,
+		Event:       ars.EventInitial,
+		DeviceID:    "3120001",
+	}
+}
+
+func TestMessage_Vectors(t *testing.T) {
+	t.Parallel()
+	refused := registration().Ack()
+	refused.Refused, refused.Reason = true, 5
+	tests := []struct {
+		name    string
+		msg     *ars.Message
+		payload []byte
+	}{
+		{
+			"registration",
+			registration(),
+			[]byte{0x00, 0x0A, 0xC0, 0x20, 0x07, '3', '1', '2', '0', '0', '0', '1'},
+		},
+		{
+			"deregistration",
+			&ars.Message{Type: ars.TypeDeviceDeregistration, DeviceID: "3120001"},
+			[]byte{0x00, 0x09, 0x01, 0x07, '3', '1', '2', '0', '0', '0', '1'},
+		},
+		{"query", ars.NewQuery(9990, 3120001), []byte{0x00, 0x01, 0x04}},
+		{"ack", registration().Ack(), []byte{0x00, 0x02, 0x8F, 0x00}},
+		{"refused", refused, []byte{0x00, 0x02, 0x8F, 0x45}},
+		{
+			// The user ID is sent empty to reach the password.
+			"password",
+			&ars.Message{Type: ars.TypeDeviceRegistration, Priority: true, Control: true, Event: ars.EventRefresh, DeviceID: "7", Password: "pw"},
+			[]byte{0x00, 0x08, 0xB0, 0x40, 0x01, '7', 0x00, 0x02, 'p', 'w'},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			payload, err := tt.msg.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(payload, tt.payload) {
+				t.Errorf("Encode = % X\nwant     % X", payload, tt.payload)
+			}
+			got, err := ars.Decode(tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := *tt.msg
+			want.Source, want.Destination = 0, 0
+			if !reflect.DeepEqual(got, &want) {
+				t.Errorf("Decode = %s\nwant     %s", got.ToString(), want.ToString())
+			}
+		})
+	}
+}
+
+func TestMessage_RoundTrip(t *testing.T) {
+	t.Parallel()
+	var addresses layer2.IPv4AddressContext
+	tests := []struct {
+		name string
+		msg  *ars.Message
+	}{
+		{"registration", registration()},
+		{"user", &ars.Message{
+			Source: 3120001, Destination: 9990, Type: ars.TypeDeviceRegistration,
+			Encoding: 3, DeviceID: "3120001", UserID: "ki5vmf", Password: "secret",
+		}},
+		{"deregistration", &ars.Message{Source: 3120001, Destination: 9990, Type: ars.TypeDeviceDeregistration, DeviceID: "3120001"}},
+		{"query", ars.NewQuery(9990, 3120001)},
+		{"ack", registration().Ack()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			datagram, err := tt.msg.Datagram(&addresses, 1)
+			if err != nil {
+				t.Fatalf("Datagram: %v", err)
+			}
+			got, err := ars.DecodeDatagram(datagram, &addresses)
+			if err != nil {
+				t.Fatalf("DecodeDatagram: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.msg) {
+				t.Errorf("got %s\nwant %s", got.ToString(), tt.msg.ToString())
+			}
+		})
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		payload  []byte
+		sentinel error
+		field    string
+	}{
+		{"no length", []byte{0x00}, elements.ErrInvalidLength, "Length"},
+		{"zero length", []byte{0x00, 0x00}, elements.ErrInvalidLength, "Length"},
+		{"length past end", []byte{0x00, 0x03, 0xC0, 0x20}, elements.ErrInvalidLength, "Length"},
+		{"no second header", []byte{0x00, 0x01, 0xC0}, elements.ErrInvalidLength, "Header"},
+		{"no third header", []byte{0x00, 0x02, 0xC0, 0xA0}, elements.ErrInvalidLength, "Header"},
+		{"device ID past end", []byte{0x00, 0x04, 0xC0, 0x20, 0x07, '3'}, elements.ErrInvalidLength, "DeviceID"},
+		{"user ID past end", []byte{0x00, 0x05, 0x01, 0x01, '3', 0x02, 'a'}, elements.ErrInvalidLength, "UserID"},
+		{"password past end", []byte{0x00, 0x05, 0x01, 0x00, 0x00, 0x02, 'a'}, elements.ErrInvalidLength, "Password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ars.Decode(tt.payload)
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerApplication, tt.field)
+		})
+	}
+}
+
+func TestEncode_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		msg      *ars.Message
+		sentinel error
+		field    string
+	}{
+		{"type", &ars.Message{Type: 0x10}, elements.ErrInvalidEncoding, "Type"},
+		{"event", &ars.Message{Event: 4}, elements.ErrInvalidEncoding, "Event"},
+		{"encoding", &ars.Message{Encoding: 0x20}, elements.ErrInvalidEncoding, "Encoding"},
+		{"reason", &ars.Message{Type: ars.TypeRegistrationAck, Reason: 0x40}, elements.ErrInvalidEncoding, "Reason"},
+		{"device ID", &ars.Message{DeviceID: strings.Repeat("1", 256)}, elements.ErrInvalidLength, "DeviceID"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.msg.Encode()
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerApplication, tt.field)
+		})
+	}
+}
+
+func TestDecodeDatagram_Errors(t *testing.T) {
+	t.Parallel()
+	var addresses layer2.IPv4AddressContext
+	datagram, err := registration().Datagram(&addresses, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Move the destination port off 4005; the UDP checksum is not checked.
+	wrongPort := append([]byte{}, datagram...)
+	wrongPort[23]++
+	_, err = ars.DecodeDatagram(wrongPort, &addresses)
+	testutil.AssertPDUError(t, err, elements.ErrDataTypeMismatch, elements.LayerApplication, "Port")
+
+	_, err = ars.DecodeDatagram(datagram[:10], &addresses)
+	if !errors.Is(err, elements.ErrInvalidLength) {
+		t.Errorf("truncated datagram: err = %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package ars
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Presence is the registration state of a radio.
+type Presence struct {
+	// Online reports a radio that is registered.
+	Online bool
+	// Since is when the radio last came online or went offline.
+	Since time.Time
+	// LastSeen is when the radio last registered or deregistered.
+	LastSeen time.Time
+	// DeviceID is the device identifier of its last registration.
+	DeviceID string
+}
+
+// Tracker keeps the registration state of the radios heard through ARS,
+// so that a gateway knows which radios are reachable. Its methods may be
+// called from several goroutines; set the fields before first use. The
+// zero value is ready to use.
+type Tracker struct {
+	// Timeout is how long a radio stays online without refreshing its
+	// registration. Zero keeps it online until it deregisters.
+	Timeout time.Duration
+
+	mu     sync.Mutex
+	radios map[int]Presence
+}
+
+// Receive updates the state of the radio that sent a message at time now.
+// A registration brings the radio online and a deregistration takes it
+// offline; other messages are ignored. It returns the acknowledgement to
+// send for a registration that asks for one.
+func (t *Tracker) Receive(m *Message, now time.Time) *Message {
+	switch m.Type {
+	case TypeDeviceRegistration:
+		t.set(m.Source, true, m.DeviceID, now)
+		if m.AckRequired {
+			return m.Ack()
+		}
+	case TypeDeviceDeregistration:
+		t.set(m.Source, false, m.DeviceID, now)
+	}
+	return nil
+}
+
+// set records the state of a radio heard at time now.
+func (t *Tracker) set(id int, online bool, deviceID string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.radios == nil {
+		t.radios = make(map[int]Presence)
+	}
+	p, ok := t.radios[id]
+	if !ok || p.Online != online {
+		p.Online, p.Since = online, now
+	}
+	p.LastSeen = now
+	if deviceID != "" {
+		p.DeviceID = deviceID
+	}
+	t.radios[id] = p
+}
+
+// Presence returns the state of a radio, and false for a radio that has
+// not been heard.
+func (t *Tracker) Presence(id int) (Presence, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.radios[id]
+	return p, ok
+}
+
+// Online returns the IDs of the radios that are online, in increasing
+// order.
+func (t *Tracker) Online() []int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var ids []int
+	for id, p := range t.radios {
+		if p.Online {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Expire takes offline the radios that have not registered for longer
+// than Timeout at time now, and returns their IDs in increasing order.
+// Call it periodically when Timeout is set.
+func (t *Tracker) Expire(now time.Time) []int {
+	if t.Timeout == 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var ids []int
+	for id, p := range t.radios {
+		if p.Online && now.Sub(p.LastSeen) > t.Timeout {
+			p.Online, p.Since = false, now
+			t.radios[id] = p
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
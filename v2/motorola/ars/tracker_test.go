@@ -0,0 +1,115 @@
+package ars_test
+
+import (
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/motorola/ars"
+)
+
+func TestTracker_Receive(t *testing.T) {
+	t.Parallel()
+	var tr ars.Tracker
+	start := time.Unix(1000, 0)
+
+	if _, ok := tr.Presence(3120001); ok {
+		t.Fatal("Presence of an unknown radio")
+	}
+	reg := registration()
+	ack := tr.Receive(reg, start)
+	if want := reg.Ack(); !reflect.DeepEqual(ack, want) {
+		t.Errorf("ack = %v, want %s", ack, want.ToString())
+	}
+	want := ars.Presence{Online: true, Since: start, LastSeen: start, DeviceID: "3120001"}
+	if p, ok := tr.Presence(3120001); !ok || p != want {
+		t.Errorf("after registration: %+v", p)
+	}
+
+	// A refresh keeps the radio online since its first registration.
+	refresh := &ars.Message{Source: 3120001, Type: ars.TypeDeviceRegistration, Event: ars.EventRefresh}
+	if ack := tr.Receive(refresh, start.Add(time.Minute)); ack != nil {
+		t.Errorf("ack = %s for a registration that does not ask for one", ack.ToString())
+	}
+	want.LastSeen = start.Add(time.Minute)
+	if p, _ := tr.Presence(3120001); p != want {
+		t.Errorf("after refresh: %+v", p)
+	}
+
+	// Queries and acknowledgements do not change the state.
+	tr.Receive(ars.NewQuery(3120001, 9990), start.Add(2*time.Minute))
+	tr.Receive(reg.Ack(), start.Add(2*time.Minute))
+	if p, _ := tr.Presence(3120001); p != want {
+		t.Errorf("after query and ack: %+v", p)
+	}
+
+	off := &ars.Message{Source: 3120001, Type: ars.TypeDeviceDeregistration}
+	if ack := tr.Receive(off, start.Add(3*time.Minute)); ack != nil {
+		t.Errorf("ack = %s for a deregistration", ack.ToString())
+	}
+	want = ars.Presence{Since: start.Add(3 * time.Minute), LastSeen: start.Add(3 * time.Minute), DeviceID: "3120001"}
+	if p, _ := tr.Presence(3120001); p != want {
+		t.Errorf("after deregistration: %+v", p)
+	}
+	if ids := tr.Online(); len(ids) != 0 {
+		t.Errorf("Online = %v, want none", ids)
+	}
+}
+
+func TestTracker_Expire(t *testing.T) {
+	t.Parallel()
+	start := time.Unix(1000, 0)
+	register := func(tr *ars.Tracker, id int, now time.Time) {
+		tr.Receive(&ars.Message{Source: id, Type: ars.TypeDeviceRegistration}, now)
+	}
+
+	tr := ars.Tracker{Timeout: 30 * time.Minute}
+	register(&tr, 3120003, start)
+	register(&tr, 3120001, start)
+	register(&tr, 3120002, start.Add(20*time.Minute))
+	if ids := tr.Online(); !slices.Equal(ids, []int{3120001, 3120002, 3120003}) {
+		t.Fatalf("Online = %v", ids)
+	}
+
+	if ids := tr.Expire(start.Add(30 * time.Minute)); len(ids) != 0 {
+		t.Errorf("expired at the timeout: %v", ids)
+	}
+	now := start.Add(31 * time.Minute)
+	if ids := tr.Expire(now); !slices.Equal(ids, []int{3120001, 3120003}) {
+		t.Errorf("Expire = %v", ids)
+	}
+	if ids := tr.Online(); !slices.Equal(ids, []int{3120002}) {
+		t.Errorf("Online = %v", ids)
+	}
+	if p, _ := tr.Presence(3120001); p.Online || !p.Since.Equal(now) || !p.LastSeen.Equal(start) {
+		t.Errorf("expired radio: %+v", p)
+	}
+
+	var forever ars.Tracker
+	register(&forever, 3120001, start)
+	if ids := forever.Expire(start.Add(24 * time.Hour)); len(ids) != 0 {
+		t.Errorf("Expire without a timeout = %v", ids)
+	}
+}
+
+func TestTracker_Concurrent(t *testing.T) {
+	t.Parallel()
+	var tr ars.Tracker
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			now := time.Unix(int64(i), 0)
+			tr.Receive(&ars.Message{Source: 3120000 + i, Type: ars.TypeDeviceRegistration}, now)
+			tr.Online()
+			tr.Expire(now)
+		}()
+	}
+	wg.Wait()
+	if ids := tr.Online(); len(ids) != 8 {
+		t.Errorf("Online = %v, want 8 radios", ids)
+	}
+}
@@ -0,0 +1,301 @@
+// Package lrrp decodes and encodes Motorola Location Request/Response
+// Protocol (LRRP) documents, carried as UDP datagrams to port 4001 over DMR
+// packet data.
+//
+// An LRRP document is a type octet, a length octet and a sequence of
+// tokens. Each token is one octet followed by parameters whose size the
+// token implies, so a document can only be read as far as its first
+// unknown token; a response is returned as read that far. Token values depend on the document type: 0x34 is a
+// periodic trigger in a request and a timestamp in a response.
+package lrrp
+
+import (
+	"fmt"
+
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+)
+
+// Port is the UDP port of the location service.
+const Port = 4001
+
+// Type is the document type, from the first octet of a document.
+type Type uint8
+
+const (
+	// TypeImmediateRequest asks for the current location.
+	TypeImmediateRequest Type = 0x05
+	// TypeImmediateResponse answers an immediate request.
+	TypeImmediateResponse Type = 0x07
+	// TypeTriggeredStartRequest asks for location reports until stopped.
+	TypeTriggeredStartRequest Type = 0x09
+	// TypeTriggeredStartResponse accepts or refuses a triggered request.
+	TypeTriggeredStartResponse Type = 0x0B
+	// TypeTriggeredReport is a location report of a triggered request.
+	TypeTriggeredReport Type = 0x0D
+	// TypeTriggeredStopRequest ends a triggered request.
+	TypeTriggeredStopRequest Type = 0x0F
+	// TypeTriggeredStopResponse answers a stop request.
+	TypeTriggeredStopResponse Type = 0x11
+)
+
+// TypeToName returns the name of a document type.
+func TypeToName(t Type) string {
+	switch t {
+	case TypeImmediateRequest:
+		return "Immediate Location Request"
+	case TypeImmediateResponse:
+		return "Immediate Location Response"
+	case TypeTriggeredStartRequest:
+		return "Triggered Location Start Request"
+	case TypeTriggeredStartResponse:
+		return "Triggered Location Start Response"
+	case TypeTriggeredReport:
+		return "Triggered Location Data"
+	case TypeTriggeredStopRequest:
+		return "Triggered Location Stop Request"
+	case TypeTriggeredStopResponse:
+		return "Triggered Location Stop Response"
+	}
+	return fmt.Sprintf("Unknown (0x%02X)", uint8(t))
+}
+
+// Document is a *Request or a *Response.
+type Document interface {
+	// DocumentType returns the type of the document.
+	DocumentType() Type
+	// Encode returns the UDP payload that carries the document.
+	Encode() ([]byte, error)
+	// ToString returns a string representation of the document.
+	ToString() string
+
+	// addresses returns the radio IDs of the sender and the destination.
+	addresses() (source, destination int)
+	// setAddresses sets the radio IDs of the sender and the destination.
+	setAddresses(source, destination int)
+}
+
+const (
+	// headerOctets is the type and length octets before the tokens.
+	headerOctets = 2
+	// maxDocumentOctets is the most token octets a length octet counts.
+	maxDocumentOctets = 0xFF
+	// maxRequestIDOctets is the most octets a request ID length counts.
+	maxRequestIDOctets = 0xFF
+)
+
+// tokenRequestID is the request ID, a length octet and that many octets,
+// in every document type.
+const tokenRequestID = 0x22
+
+// Decode returns the document carried by a UDP payload, with Source and
+// Destination zero.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrInvalidLength for a
+// truncated document, elements.ErrInvalidEncoding for a malformed
+// parameter, or elements.ErrNotImplemented for an unknown document type or
+// token. A response with an unknown token is returned with the error,
+// holding the tokens before it.
+func Decode(payload []byte) (Document, error) {
+	if len(payload) < headerOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	n := int(payload[1])
+	if headerOctets+n > len(payload) {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	t := Type(payload[0])
+	r := &reader{data: payload[headerOctets : headerOctets+n]}
+	switch t {
+	case TypeImmediateRequest, TypeTriggeredStartRequest, TypeTriggeredStopRequest:
+		q, err := decodeRequest(t, r)
+		if q == nil {
+			return nil, err
+		}
+		return q, err
+	case TypeImmediateResponse, TypeTriggeredStartResponse, TypeTriggeredReport, TypeTriggeredStopResponse:
+		p, err := decodeResponse(t, r)
+		if p == nil {
+			return nil, err
+		}
+		return p, err
+	}
+	return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Type", Err: elements.ErrNotImplemented}
+}
+
+// DecodeDatagram returns the document carried by a UDP/IPv4 datagram to
+// Port, taking the radio IDs from its addresses on the networks of
+// addresses. Requests and responses are both sent between Port at each end.
+//
+// Errors are an *elements.PDUError: as for
+// IPv4AddressContext.DecodeRadioDatagram, or as for Decode, which may also
+// return a response.
+func DecodeDatagram(datagram []byte, addresses *layer2.IPv4AddressContext) (Document, error) {
+	d, err := addresses.DecodeRadioDatagram(datagram, Port)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Decode(d.Payload)
+	if doc == nil {
+		return nil, err
+	}
+	doc.setAddresses(d.Source, d.Destination)
+	return doc, err
+}
+
+// Datagram returns the UDP/IPv4 datagram that carries a document from its
+// Source to its Destination on the networks of addresses, between Port at
+// both ends, with the IPv4 identification given. Errors are as for
+// Document.Encode and IPv4AddressContext.EncodeRadioDatagram.
+func Datagram(doc Document, addresses *layer2.IPv4AddressContext, identification uint16) ([]byte, error) {
+	payload, err := doc.Encode()
+	if err != nil {
+		return nil, err
+	}
+	source, destination := doc.addresses()
+	d := layer2.RadioDatagram{Source: source, Destination: destination, Payload: payload}
+	return addresses.EncodeRadioDatagram(&d, Port, identification)
+}
+
+// encodeDocument returns a document of type t holding tokens.
+func encodeDocument(t Type, tokens []byte) ([]byte, error) {
+	if len(tokens) > maxDocumentOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	out := make([]byte, 0, headerOctets+len(tokens))
+	out = append(out, byte(t), byte(len(tokens)))
+	return append(out, tokens...), nil
+}
+
+// appendRequestID appends the request ID token, omitted for an empty ID.
+func appendRequestID(out, id []byte) ([]byte, error) {
+	if len(id) == 0 {
+		return out, nil
+	}
+	if len(id) > maxRequestIDOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "RequestID", Err: elements.ErrInvalidLength}
+	}
+	out = append(out, tokenRequestID, byte(len(id)))
+	return append(out, id...), nil
+}
+
+// Variable-length integers are 7 bits per octet, most significant first,
+// with the top bit set on every octet but the last.
+const (
+	varContinue = 0x80
+	varBits     = 7
+	varMask     = 0x7F
+	// maxVarOctets is the most octets of a 32-bit integer.
+	maxVarOctets = 5
+	// fractionScale is the value of a one-octet fraction of a float.
+	fractionScale = 1 << varBits
+)
+
+// appendUintvar appends v as a variable-length integer.
+func appendUintvar(out []byte, v uint32) []byte {
+	var buf [maxVarOctets]byte
+	i := len(buf) - 1
+	buf[i] = byte(v & varMask)
+	for v >>= varBits; v > 0; v >>= varBits {
+		i--
+		buf[i] = byte(v&varMask) | varContinue
+	}
+	return append(out, buf[i:]...)
+}
+
+// appendUfloatvar appends a non-negative v as a variable-length integer
+// part followed by a one-octet fraction in 1/128ths.
+func appendUfloatvar(out []byte, v float64) []byte {
+	whole := uint32(v)
+	fraction := int((v-float64(whole))*fractionScale + 0.5)
+	if fraction == fractionScale {
+		whole, fraction = whole+1, 0
+	}
+	out = appendUintvar(out, whole)
+	return append(out, byte(fraction))
+}
+
+// reader reads the parameters of tokens from a document.
+type reader struct {
+	data []byte
+}
+
+// done reports whether every token has been read.
+func (r *reader) done() bool {
+	return len(r.data) == 0
+}
+
+// octets returns the next n octets, or an error naming field.
+func (r *reader) octets(n int, field string) ([]byte, error) {
+	if n > len(r.data) {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: field, Err: elements.ErrInvalidLength}
+	}
+	out := r.data[:n]
+	r.data = r.data[n:]
+	return out, nil
+}
+
+// octet returns the next octet, or an error naming field.
+func (r *reader) octet(field string) (byte, error) {
+	b, err := r.octets(1, field)
+	if err != nil {
+		return 0, err
+	}
+	return b[0], nil
+}
+
+// uintvar returns the next variable-length integer and the number of
+// octets it took.
+func (r *reader) uintvar(field string) (uint32, int, error) {
+	var v uint32
+	for i := range maxVarOctets {
+		b, err := r.octet(field)
+		if err != nil {
+			return 0, 0, err
+		}
+		if i == maxVarOctets-1 && v>>(32-varBits) != 0 {
+			break
+		}
+		v = v<<varBits | uint32(b&varMask)
+		if b&varContinue == 0 {
+			return v, i + 1, nil
+		}
+	}
+	return 0, 0, &elements.PDUError{Layer: elements.LayerApplication, Field: field, Err: elements.ErrInvalidEncoding}
+}
+
+// ufloatvar returns the next variable-length float: an integer part and a
+// fraction in 1/128ths per octet.
+func (r *reader) ufloatvar(field string) (float64, error) {
+	whole, _, err := r.uintvar(field)
+	if err != nil {
+		return 0, err
+	}
+	fraction, n, err := r.uintvar(field)
+	if err != nil {
+		return 0, err
+	}
+	scale := 1.0
+	for range n {
+		scale *= fractionScale
+	}
+	return float64(whole) + float64(fraction)/scale, nil
+}
+
+// requestID returns the parameters of a request ID token.
+func (r *reader) requestID() ([]byte, error) {
+	n, err := r.octet("RequestID")
+	if err != nil {
+		return nil, err
+	}
+	id, err := r.octets(int(n), "RequestID")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), id...), nil
+}
+
+// unknownToken returns the error for a token that cannot be read.
+func unknownToken(token byte) error {
+	return &elements.PDUError{Layer: elements.LayerApplication, Field: fmt.Sprintf("Token[0x%02X]", token), Err: elements.ErrNotImplemented}
+}
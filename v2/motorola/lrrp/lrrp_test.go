@@ -0,0 +1,133 @@
+package lrrp_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/motorola/lrrp"
+)
+
+// A poll and its answer pass through UDP/IPv4 header compression (SAP 3)
+// and are read back from the decompressed datagrams.
+func TestDecodeDatagram_Compressed(t *testing.T) {
+	t.Parallel()
+	var addresses layer2.IPv4AddressContext
+	req := lrrp.NewImmediateRequest(9990, 3120001, []byte{0x12, 0x34})
+	resp := &lrrp.Response{
+		Source:      3120001,
+		Destination: 9990,
+		Type:        lrrp.TypeImmediateResponse,
+		RequestID:   []byte{0x12, 0x34},
+		Time:        time.Date(2025, time.July, 4, 18, 0, 0, 0, time.UTC),
+		Position:    &lrrp.Position{Latitude: 45, Longitude: -90},
+	}
+	for _, doc := range []lrrp.Document{req, resp} {
+		datagram, err := lrrp.Datagram(doc, &addresses, 7)
+		if err != nil {
+			t.Fatalf("Datagram: %v", err)
+		}
+		msg, err := addresses.CompressUDPIPv4(datagram)
+		if err != nil {
+			t.Fatalf("CompressUDPIPv4: %v", err)
+		}
+		out, err := addresses.DecompressUDPIPv4(msg)
+		if err != nil {
+			t.Fatalf("DecompressUDPIPv4: %v", err)
+		}
+		got, err := lrrp.DecodeDatagram(out, &addresses)
+		if err != nil {
+			t.Fatalf("DecodeDatagram: %v", err)
+		}
+		if !reflect.DeepEqual(got, doc) {
+			t.Errorf("got %s\nwant %s", got.ToString(), doc.ToString())
+		}
+	}
+}
+
+func TestDecodeDatagram_Errors(t *testing.T) {
+	t.Parallel()
+	var addresses layer2.IPv4AddressContext
+	datagram, err := lrrp.Datagram(lrrp.NewImmediateRequest(1, 2, nil), &addresses, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Move both ports off 4001; the UDP checksum is not checked.
+	wrongPort := append([]byte{}, datagram...)
+	wrongPort[21]++
+	wrongPort[23]++
+	_, err = lrrp.DecodeDatagram(wrongPort, &addresses)
+	testutil.AssertPDUError(t, err, elements.ErrDataTypeMismatch, elements.LayerApplication, "Port")
+
+	// Only the destination port is checked: a datagram from 4001 to
+	// another port is not LRRP.
+	fromPort := append([]byte{}, datagram...)
+	fromPort[23]++
+	_, err = lrrp.DecodeDatagram(fromPort, &addresses)
+	testutil.AssertPDUError(t, err, elements.ErrDataTypeMismatch, elements.LayerApplication, "Port")
+
+	_, err = lrrp.DecodeDatagram(datagram[:10], &addresses)
+	if !errors.Is(err, elements.ErrInvalidLength) {
+		t.Errorf("truncated datagram: err = %v", err)
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		payload  []byte
+		sentinel error
+		field    string
+	}{
+		{"no length", []byte{0x05}, elements.ErrInvalidLength, "Length"},
+		{"length past end", []byte{0x05, 0x02, 0x62}, elements.ErrInvalidLength, "Length"},
+		{"unknown type", []byte{0x13, 0x00}, elements.ErrNotImplemented, "Type"},
+		{"unknown request token", []byte{0x05, 0x01, 0x99}, elements.ErrNotImplemented, "Token[0x99]"},
+		{"unknown response token", []byte{0x07, 0x01, 0x62}, elements.ErrNotImplemented, "Token[0x62]"},
+		{"request ID past end", []byte{0x05, 0x03, 0x22, 0x04, 0x00}, elements.ErrInvalidLength, "RequestID"},
+		{"no ret-info flags", []byte{0x05, 0x01, 0x51}, elements.ErrInvalidLength, "RetInfo"},
+		{"trigger without interval", []byte{0x09, 0x02, 0x34, 0x32}, elements.ErrNotImplemented, "Token[0x32]"},
+		{"interval past end", []byte{0x09, 0x03, 0x34, 0x31, 0x81}, elements.ErrInvalidLength, "Interval"},
+		{"interval overflow", []byte{0x09, 0x07, 0x34, 0x31, 0x90, 0x80, 0x80, 0x80, 0x00}, elements.ErrInvalidEncoding, "Interval"},
+		{"interval too long", []byte{0x09, 0x08, 0x34, 0x31, 0x80, 0x80, 0x80, 0x80, 0x80, 0x00}, elements.ErrInvalidEncoding, "Interval"},
+		{"position past end", []byte{0x07, 0x05, 0x51, 0x00, 0x00, 0x00, 0x00}, elements.ErrInvalidLength, "Position"},
+		{"no radius", []byte{0x07, 0x09, 0x54, 0, 0, 0, 0, 0, 0, 0, 0}, elements.ErrInvalidLength, "Radius"},
+		{"no altitude", []byte{0x07, 0x09, 0x66, 0, 0, 0, 0, 0, 0, 0, 0}, elements.ErrInvalidLength, "Altitude"},
+		{"timestamp past end", []byte{0x07, 0x03, 0x34, 0x1F, 0xA0}, elements.ErrInvalidLength, "Time"},
+		// Month 13 of 2024.
+		{"timestamp month", []byte{0x07, 0x06, 0x34, 0x1F, 0xA3, 0x5E, 0xC8, 0xB8}, elements.ErrInvalidEncoding, "Time"},
+		{"no speed fraction", []byte{0x07, 0x02, 0x6C, 0x0C}, elements.ErrInvalidLength, "Speed"},
+		{"no direction", []byte{0x07, 0x01, 0x56}, elements.ErrInvalidLength, "Direction"},
+		{"result past end", []byte{0x11, 0x02, 0x37, 0x82}, elements.ErrInvalidLength, "Result"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := lrrp.Decode(tt.payload)
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerApplication, tt.field)
+		})
+	}
+}
+
+func TestTypeToName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		t    lrrp.Type
+		want string
+	}{
+		{lrrp.TypeImmediateRequest, "Immediate Location Request"},
+		{lrrp.TypeTriggeredReport, "Triggered Location Data"},
+		{lrrp.TypeTriggeredStopResponse, "Triggered Location Stop Response"},
+		{0x13, "Unknown (0x13)"},
+	}
+	for _, tt := range tests {
+		if got := lrrp.TypeToName(tt.t); got != tt.want {
+			t.Errorf("TypeToName(0x%02X) = %q, want %q", uint8(tt.t), got, tt.want)
+		}
+	}
+}
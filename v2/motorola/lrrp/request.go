@@ -0,0 +1,184 @@
+package lrrp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+)
+
+// Request tokens.
+const (
+	// tokenRetInfo asks for location information, with a flags octet.
+	tokenRetInfo = 0x51
+	// tokenRequestSpeed asks for the horizontal speed.
+	tokenRequestSpeed = 0x62
+	// tokenPeriodicTrigger starts a periodic trigger; it is followed by
+	// tokenInterval.
+	tokenPeriodicTrigger = 0x34
+	// tokenInterval is the trigger interval in seconds, a variable-length
+	// integer.
+	tokenInterval = 0x31
+)
+
+// RetInfoDefault is the ret-info flags octet location servers commonly
+// send.
+const RetInfoDefault = 0x40
+
+// maxInterval is the longest trigger interval a variable-length integer
+// holds.
+const maxInterval = time.Duration(^uint32(0)) * time.Second
+
+// Request is an immediate, triggered start or triggered stop location
+// request, sent by a location server to a radio.
+type Request struct {
+	// Source is the radio ID of the location server and Destination that
+	// of the radio, from the datagram's IPv4 addresses.
+	Source      int
+	Destination int
+	Type        Type
+	// RequestID is echoed in the responses; it may be empty.
+	RequestID []byte
+	// RetInfo is the flags octet of the ret-info token; zero omits the
+	// token. It is not sent in a stop request.
+	RetInfo uint8
+	// RequestSpeed asks for the horizontal speed. It is not sent in a stop
+	// request.
+	RequestSpeed bool
+	// Interval is the time between reports of a triggered start request,
+	// in whole seconds; zero omits the trigger.
+	Interval time.Duration
+}
+
+// NewImmediateRequest returns a request for the current location and
+// speed of a radio.
+func NewImmediateRequest(source, destination int, requestID []byte) *Request {
+	return &Request{
+		Source:       source,
+		Destination:  destination,
+		Type:         TypeImmediateRequest,
+		RequestID:    requestID,
+		RetInfo:      RetInfoDefault,
+		RequestSpeed: true,
+	}
+}
+
+// NewTriggeredRequest returns a request for the location and speed of a
+// radio every interval, until a stop request.
+func NewTriggeredRequest(source, destination int, requestID []byte, interval time.Duration) *Request {
+	return &Request{
+		Source:       source,
+		Destination:  destination,
+		Type:         TypeTriggeredStartRequest,
+		RequestID:    requestID,
+		RetInfo:      RetInfoDefault,
+		RequestSpeed: true,
+		Interval:     interval,
+	}
+}
+
+// Stop returns the request that ends a triggered request.
+func (q *Request) Stop() *Request {
+	return &Request{
+		Source:      q.Source,
+		Destination: q.Destination,
+		Type:        TypeTriggeredStopRequest,
+		RequestID:   q.RequestID,
+	}
+}
+
+// DocumentType returns the type of the request.
+func (q *Request) DocumentType() Type {
+	return q.Type
+}
+
+// ToString returns a string representation of the request.
+func (q *Request) ToString() string {
+	return fmt.Sprintf("Request{ Source: %d, Destination: %d, Type: %s, RequestID: % X, RetInfo: 0x%02X, RequestSpeed: %t, Interval: %s }",
+		q.Source, q.Destination, TypeToName(q.Type), q.RequestID, q.RetInfo, q.RequestSpeed, q.Interval)
+}
+
+// Encode returns the UDP payload that carries the request. Errors are an
+// *elements.PDUError wrapping elements.ErrDataTypeMismatch when Type is not
+// a request type, elements.ErrInvalidEncoding for an interval that is not
+// whole seconds or is set outside a triggered start request, or
+// elements.ErrInvalidLength for a request ID or document too long.
+func (q *Request) Encode() ([]byte, error) {
+	switch q.Type {
+	case TypeImmediateRequest, TypeTriggeredStartRequest, TypeTriggeredStopRequest:
+	default:
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Type", Err: elements.ErrDataTypeMismatch}
+	}
+	if q.Interval < 0 || q.Interval > maxInterval || q.Interval%time.Second != 0 ||
+		(q.Interval != 0 && q.Type != TypeTriggeredStartRequest) {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Interval", Err: elements.ErrInvalidEncoding}
+	}
+
+	tokens, err := appendRequestID(nil, q.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	if q.Type != TypeTriggeredStopRequest {
+		if q.RetInfo != 0 {
+			tokens = append(tokens, tokenRetInfo, q.RetInfo)
+		}
+		if q.RequestSpeed {
+			tokens = append(tokens, tokenRequestSpeed)
+		}
+	}
+	if q.Interval != 0 {
+		tokens = append(tokens, tokenPeriodicTrigger, tokenInterval)
+		tokens = appendUintvar(tokens, uint32(q.Interval/time.Second)) //nolint:gosec // checked against maxInterval
+	}
+	return encodeDocument(q.Type, tokens)
+}
+
+func (q *Request) addresses() (source, destination int) {
+	return q.Source, q.Destination
+}
+
+func (q *Request) setAddresses(source, destination int) {
+	q.Source, q.Destination = source, destination
+}
+
+// decodeRequest returns the request of type t holding the tokens of r.
+func decodeRequest(t Type, r *reader) (*Request, error) {
+	q := &Request{Type: t}
+	for !r.done() {
+		token, _ := r.octet("Token")
+		var err error
+		switch token {
+		case tokenRequestID:
+			q.RequestID, err = r.requestID()
+		case tokenRetInfo:
+			q.RetInfo, err = r.octet("RetInfo")
+		case tokenRequestSpeed:
+			q.RequestSpeed = true
+		case tokenPeriodicTrigger:
+			err = q.decodeTrigger(r)
+		default:
+			return nil, unknownToken(token)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+// decodeTrigger reads the parameters of a periodic trigger.
+func (q *Request) decodeTrigger(r *reader) error {
+	token, err := r.octet("Interval")
+	if err != nil {
+		return err
+	}
+	if token != tokenInterval {
+		return unknownToken(token)
+	}
+	seconds, _, err := r.uintvar("Interval")
+	if err != nil {
+		return err
+	}
+	q.Interval = time.Duration(seconds) * time.Second
+	return nil
+}
@@ -0,0 +1,91 @@
+package lrrp_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/motorola/lrrp"
+)
+
+func TestRequest_Vectors(t *testing.T) {
+	t.Parallel()
+	id := []byte{0x00, 0x00, 0x00, 0x01}
+	triggered := lrrp.NewTriggeredRequest(1, 3120001, id, 300*time.Second)
+	tests := []struct {
+		name    string
+		req     *lrrp.Request
+		payload []byte
+	}{
+		{
+			"immediate",
+			lrrp.NewImmediateRequest(1, 3120001, id),
+			[]byte{0x05, 0x09, 0x22, 0x04, 0x00, 0x00, 0x00, 0x01, 0x51, 0x40, 0x62},
+		},
+		{
+			// 300 seconds is 10 0101100: 0x82 0x2C.
+			"triggered start",
+			triggered,
+			[]byte{0x09, 0x0D, 0x22, 0x04, 0x00, 0x00, 0x00, 0x01, 0x51, 0x40, 0x62, 0x34, 0x31, 0x82, 0x2C},
+		},
+		{
+			"triggered stop",
+			triggered.Stop(),
+			[]byte{0x0F, 0x06, 0x22, 0x04, 0x00, 0x00, 0x00, 0x01},
+		},
+		{
+			"no request ID",
+			&lrrp.Request{Type: lrrp.TypeImmediateRequest, RetInfo: lrrp.RetInfoDefault},
+			[]byte{0x05, 0x02, 0x51, 0x40},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			payload, err := tt.req.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(payload, tt.payload) {
+				t.Errorf("Encode = % X\nwant     % X", payload, tt.payload)
+			}
+
+			doc, err := lrrp.Decode(tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := *tt.req
+			want.Source, want.Destination = 0, 0
+			if got, ok := doc.(*lrrp.Request); !ok || !reflect.DeepEqual(got, &want) {
+				t.Errorf("Decode = %s\nwant     %s", doc.ToString(), want.ToString())
+			}
+		})
+	}
+}
+
+func TestRequest_Encode_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		req      *lrrp.Request
+		sentinel error
+		field    string
+	}{
+		{"response type", &lrrp.Request{Type: lrrp.TypeImmediateResponse}, elements.ErrDataTypeMismatch, "Type"},
+		{"fractional interval", lrrp.NewTriggeredRequest(1, 2, nil, 1500*time.Millisecond), elements.ErrInvalidEncoding, "Interval"},
+		{"negative interval", lrrp.NewTriggeredRequest(1, 2, nil, -time.Second), elements.ErrInvalidEncoding, "Interval"},
+		{"immediate interval", &lrrp.Request{Type: lrrp.TypeImmediateRequest, Interval: time.Second}, elements.ErrInvalidEncoding, "Interval"},
+		{"long request ID", lrrp.NewImmediateRequest(1, 2, make([]byte, 256)), elements.ErrInvalidLength, "RequestID"},
+		{"long document", lrrp.NewImmediateRequest(1, 2, make([]byte, 255)), elements.ErrInvalidLength, "Length"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.req.Encode()
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerApplication, tt.field)
+		})
+	}
+}
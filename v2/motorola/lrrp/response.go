@@ -0,0 +1,316 @@
+package lrrp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+)
+
+// Response tokens.
+const (
+	// tokenTimestamp is the time of the location fix, 5 octets.
+	tokenTimestamp = 0x34
+	// tokenResult is the result code, a variable-length integer.
+	tokenResult = 0x37
+	// tokenPoint2D is a latitude and longitude.
+	tokenPoint2D = 0x51
+	// tokenCircle2D is a latitude, longitude and radius.
+	tokenCircle2D = 0x54
+	// tokenDirection is the horizontal direction in 2 degree steps, 1
+	// octet.
+	tokenDirection = 0x56
+	// tokenPoint3D is a latitude, longitude and altitude.
+	tokenPoint3D = 0x66
+	// tokenCircle3D is a latitude, longitude, radius and altitude.
+	tokenCircle3D = 0x69
+	// tokenSpeed is the horizontal speed, a variable-length float.
+	tokenSpeed = 0x6C
+)
+
+// Result is the result code of a response.
+type Result uint32
+
+// ResultSuccess reports a request that was carried out. A response without
+// a result code reports success.
+const ResultSuccess Result = 0
+
+// Coordinate and timestamp layout.
+const (
+	coordinateOctets = 4
+	timestampOctets  = 5
+	// latitudeScale and longitudeScale are the degrees of the largest
+	// coordinate, 2^31.
+	latitudeScale  = 90
+	longitudeScale = 180
+	coordinateUnit = 1 << 31
+
+	directionStep = 2
+	maxDirection  = 360
+	maxYear       = 1<<14 - 1
+)
+
+// Position is a location fix in WGS 84.
+type Position struct {
+	// Latitude and Longitude are in degrees, north and east positive.
+	Latitude  float64
+	Longitude float64
+	// Radius is the uncertainty of the fix in meters; zero for a point.
+	Radius float64
+	// Altitude is the altitude in meters, when HasAltitude is set.
+	Altitude    uint32
+	HasAltitude bool
+}
+
+// ToString returns a string representation of the position.
+func (p *Position) ToString() string {
+	return fmt.Sprintf("Position{ Latitude: %f, Longitude: %f, Radius: %g, Altitude: %d, HasAltitude: %t }",
+		p.Latitude, p.Longitude, p.Radius, p.Altitude, p.HasAltitude)
+}
+
+// Response is an immediate, triggered start or triggered stop location
+// response, or a triggered location report, sent by a radio to a location
+// server.
+type Response struct {
+	// Source is the radio ID of the radio and Destination that of the
+	// location server, from the datagram's IPv4 addresses.
+	Source      int
+	Destination int
+	Type        Type
+	// RequestID is the ID of the request answered.
+	RequestID []byte
+	// Result is ResultSuccess when the response carries no result code.
+	Result Result
+	// Time is the time of the fix in UTC, zero when not sent.
+	Time time.Time
+	// Position is nil when the response carries no location.
+	Position *Position
+	// Speed is the horizontal speed in km/h, when HasSpeed is set.
+	Speed    float64
+	HasSpeed bool
+	// Direction is the horizontal direction in degrees clockwise from
+	// north, in 2 degree steps, when HasDirection is set.
+	Direction    int
+	HasDirection bool
+}
+
+// DocumentType returns the type of the response.
+func (p *Response) DocumentType() Type {
+	return p.Type
+}
+
+// ToString returns a string representation of the response.
+func (p *Response) ToString() string {
+	position := "nil"
+	if p.Position != nil {
+		position = p.Position.ToString()
+	}
+	return fmt.Sprintf("Response{ Source: %d, Destination: %d, Type: %s, RequestID: % X, Result: %d, Time: %s, Position: %s, Speed: %g, HasSpeed: %t, Direction: %d, HasDirection: %t }",
+		p.Source, p.Destination, TypeToName(p.Type), p.RequestID, p.Result, p.Time.Format(time.RFC3339), position, p.Speed, p.HasSpeed, p.Direction, p.HasDirection)
+}
+
+// Encode returns the UDP payload that carries the response. A result of
+// ResultSuccess is not sent. Errors are an *elements.PDUError wrapping
+// elements.ErrDataTypeMismatch when Type is not a response type,
+// elements.ErrInvalidEncoding for a value the document cannot carry, or
+// elements.ErrInvalidLength for a request ID or document too long.
+func (p *Response) Encode() ([]byte, error) {
+	switch p.Type {
+	case TypeImmediateResponse, TypeTriggeredStartResponse, TypeTriggeredReport, TypeTriggeredStopResponse:
+	default:
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Type", Err: elements.ErrDataTypeMismatch}
+	}
+
+	tokens, err := appendRequestID(nil, p.RequestID)
+	if err != nil {
+		return nil, err
+	}
+	if p.Result != ResultSuccess {
+		tokens = append(tokens, tokenResult)
+		tokens = appendUintvar(tokens, uint32(p.Result))
+	}
+	if !p.Time.IsZero() {
+		if tokens, err = appendTimestamp(tokens, p.Time); err != nil {
+			return nil, err
+		}
+	}
+	if p.Position != nil {
+		if tokens, err = appendPosition(tokens, p.Position); err != nil {
+			return nil, err
+		}
+	}
+	if p.HasSpeed {
+		if p.Speed < 0 || p.Speed >= math.MaxUint32 || math.IsNaN(p.Speed) {
+			return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Speed", Err: elements.ErrInvalidEncoding}
+		}
+		tokens = append(tokens, tokenSpeed)
+		tokens = appendUfloatvar(tokens, p.Speed)
+	}
+	if p.HasDirection {
+		if p.Direction < 0 || p.Direction >= maxDirection {
+			return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Direction", Err: elements.ErrInvalidEncoding}
+		}
+		tokens = append(tokens, tokenDirection, byte(p.Direction/directionStep)) //nolint:gosec // below maxDirection
+	}
+	return encodeDocument(p.Type, tokens)
+}
+
+func (p *Response) addresses() (source, destination int) {
+	return p.Source, p.Destination
+}
+
+func (p *Response) setAddresses(source, destination int) {
+	p.Source, p.Destination = source, destination
+}
+
+// decodeResponse returns the response of type t holding the tokens of r.
+// An unknown token ends the response: the tokens before it are returned
+// with the error, so that a position is not lost to a token sent after it.
+func decodeResponse(t Type, r *reader) (*Response, error) {
+	p := &Response{Type: t}
+	for !r.done() {
+		token, _ := r.octet("Token")
+		var err error
+		switch token {
+		case tokenRequestID:
+			p.RequestID, err = r.requestID()
+		case tokenResult:
+			var v uint32
+			v, _, err = r.uintvar("Result")
+			p.Result = Result(v)
+		case tokenTimestamp:
+			p.Time, err = r.timestamp()
+		case tokenPoint2D, tokenCircle2D, tokenPoint3D, tokenCircle3D:
+			p.Position, err = r.position(token)
+		case tokenSpeed:
+			p.Speed, err = r.ufloatvar("Speed")
+			p.HasSpeed = true
+		case tokenDirection:
+			var d byte
+			d, err = r.octet("Direction")
+			p.Direction, p.HasDirection = int(d)*directionStep, true
+		default:
+			return p, unknownToken(token)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+// appendPosition appends the token of a position: a point or circle, with
+// or without altitude.
+func appendPosition(out []byte, p *Position) ([]byte, error) {
+	if p.Radius < 0 || p.Radius >= math.MaxUint32 || math.IsNaN(p.Radius) {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Radius", Err: elements.ErrInvalidEncoding}
+	}
+	lat, ok := encodeCoordinate(p.Latitude, latitudeScale)
+	if !ok {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Latitude", Err: elements.ErrInvalidEncoding}
+	}
+	lon, ok := encodeCoordinate(p.Longitude, longitudeScale)
+	if !ok {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Longitude", Err: elements.ErrInvalidEncoding}
+	}
+
+	token := byte(tokenPoint2D)
+	switch {
+	case p.Radius != 0 && p.HasAltitude:
+		token = tokenCircle3D
+	case p.Radius != 0:
+		token = tokenCircle2D
+	case p.HasAltitude:
+		token = tokenPoint3D
+	}
+	out = append(out, token)
+	out = binary.BigEndian.AppendUint32(out, lat)
+	out = binary.BigEndian.AppendUint32(out, lon)
+	if p.Radius != 0 {
+		out = appendUfloatvar(out, p.Radius)
+	}
+	if p.HasAltitude {
+		out = appendUintvar(out, p.Altitude)
+	}
+	return out, nil
+}
+
+// position returns the parameters of a position token.
+func (r *reader) position(token byte) (*Position, error) {
+	raw, err := r.octets(2*coordinateOctets, "Position")
+	if err != nil {
+		return nil, err
+	}
+	p := &Position{
+		Latitude:  decodeCoordinate(binary.BigEndian.Uint32(raw), latitudeScale),
+		Longitude: decodeCoordinate(binary.BigEndian.Uint32(raw[coordinateOctets:]), longitudeScale),
+	}
+	if token == tokenCircle2D || token == tokenCircle3D {
+		if p.Radius, err = r.ufloatvar("Radius"); err != nil {
+			return nil, err
+		}
+	}
+	if token == tokenPoint3D || token == tokenCircle3D {
+		if p.Altitude, _, err = r.uintvar("Altitude"); err != nil {
+			return nil, err
+		}
+		p.HasAltitude = true
+	}
+	return p, nil
+}
+
+// encodeCoordinate returns a coordinate of at most scale degrees as a
+// signed fraction of 2^31, the largest positive value standing for scale.
+func encodeCoordinate(degrees, scale float64) (uint32, bool) {
+	if !(degrees >= -scale && degrees <= scale) {
+		return 0, false
+	}
+	v := math.Round(degrees / scale * coordinateUnit)
+	v = min(v, math.MaxInt32)
+	return uint32(int32(v)), true //nolint:gosec // within int32 range
+}
+
+// decodeCoordinate returns the degrees of a coordinate.
+func decodeCoordinate(v uint32, scale float64) float64 {
+	return float64(int32(v)) * scale / coordinateUnit //nolint:gosec // two's complement reinterpretation
+}
+
+// appendTimestamp appends the timestamp token: a 14-bit year, 4-bit month,
+// 5-bit day, 5-bit hour, 6-bit minute and 6-bit second, in UTC.
+func appendTimestamp(out []byte, t time.Time) ([]byte, error) {
+	t = t.UTC()
+	if t.Year() < 0 || t.Year() > maxYear {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Time", Err: elements.ErrInvalidEncoding}
+	}
+	v := uint64(t.Year())<<26 | uint64(t.Month())<<22 | uint64(t.Day())<<17 | //nolint:gosec // year checked above
+		uint64(t.Hour())<<12 | uint64(t.Minute())<<6 | uint64(t.Second()) //nolint:gosec // clock fields are small
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	out = append(out, tokenTimestamp)
+	return append(out, buf[8-timestampOctets:]...), nil
+}
+
+// timestamp returns the parameters of a timestamp token.
+func (r *reader) timestamp() (time.Time, error) {
+	raw, err := r.octets(timestampOctets, "Time")
+	if err != nil {
+		return time.Time{}, err
+	}
+	var buf [8]byte
+	copy(buf[8-timestampOctets:], raw)
+	v := binary.BigEndian.Uint64(buf[:])
+	year := int(v >> 26)
+	month := time.Month(v >> 22 & 0x0F)
+	day := int(v >> 17 & 0x1F)
+	hour := int(v >> 12 & 0x1F)
+	minute := int(v >> 6 & 0x3F)
+	second := int(v & 0x3F)
+	t := time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+	// time.Date normalises out-of-range fields; reject them instead.
+	if t.Month() != month || t.Day() != day || t.Hour() != hour || t.Minute() != minute || t.Second() != second {
+		return time.Time{}, &elements.PDUError{Layer: elements.LayerApplication, Field: "Time", Err: elements.ErrInvalidEncoding}
+	}
+	return t, nil
+}
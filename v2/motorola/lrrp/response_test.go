@@ -0,0 +1,184 @@
+package lrrp_test
+
+import (
+	"bytes"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/motorola/lrrp"
+)
+
+func TestResponse_Vectors(t *testing.T) {
+	t.Parallel()
+	id := []byte{0x00, 0x00, 0x00, 0x01}
+	tests := []struct {
+		name    string
+		resp    *lrrp.Response
+		payload []byte
+	}{
+		{
+			// 2024-03-15 12:34:56 packs to 0x1FA0DEC8B8; 45°N and 90°W are
+			// 2^30 and -2^30; 12.5 km/h is 12 and 64/128; 90° is 45 steps.
+			"report",
+			&lrrp.Response{
+				Type:         lrrp.TypeTriggeredReport,
+				RequestID:    id,
+				Time:         time.Date(2024, time.March, 15, 12, 34, 56, 0, time.UTC),
+				Position:     &lrrp.Position{Latitude: 45, Longitude: -90},
+				Speed:        12.5,
+				HasSpeed:     true,
+				Direction:    90,
+				HasDirection: true,
+			},
+			[]byte{
+				0x0D, 0x1A,
+				0x22, 0x04, 0x00, 0x00, 0x00, 0x01,
+				0x34, 0x1F, 0xA0, 0xDE, 0xC8, 0xB8,
+				0x51, 0x40, 0x00, 0x00, 0x00, 0xC0, 0x00, 0x00, 0x00,
+				0x6C, 0x0C, 0x40,
+				0x56, 0x2D,
+			},
+		},
+		{
+			// -22.5° and 135° are -2^29 and 3 * 2^29; 7.25 m is 7 and
+			// 32/128; 1500 m is 1011 1011100.
+			"circle with altitude",
+			&lrrp.Response{
+				Type:      lrrp.TypeImmediateResponse,
+				RequestID: id,
+				Position:  &lrrp.Position{Latitude: -22.5, Longitude: 135, Radius: 7.25, Altitude: 1500, HasAltitude: true},
+			},
+			[]byte{
+				0x07, 0x13,
+				0x22, 0x04, 0x00, 0x00, 0x00, 0x01,
+				0x69, 0xE0, 0x00, 0x00, 0x00, 0x60, 0x00, 0x00, 0x00, 0x07, 0x20, 0x8B, 0x5C,
+			},
+		},
+		{
+			"point with altitude",
+			&lrrp.Response{Type: lrrp.TypeImmediateResponse, Position: &lrrp.Position{Altitude: 5, HasAltitude: true}},
+			[]byte{0x07, 0x0A, 0x66, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x05},
+		},
+		{
+			"circle",
+			&lrrp.Response{Type: lrrp.TypeImmediateResponse, Position: &lrrp.Position{Radius: 0.5}},
+			[]byte{0x07, 0x0B, 0x54, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40},
+		},
+		{
+			"start accepted",
+			&lrrp.Response{Type: lrrp.TypeTriggeredStartResponse, RequestID: id},
+			[]byte{0x0B, 0x06, 0x22, 0x04, 0x00, 0x00, 0x00, 0x01},
+		},
+		{
+			"stop refused",
+			&lrrp.Response{Type: lrrp.TypeTriggeredStopResponse, RequestID: id, Result: 300},
+			[]byte{0x11, 0x09, 0x22, 0x04, 0x00, 0x00, 0x00, 0x01, 0x37, 0x82, 0x2C},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			payload, err := tt.resp.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(payload, tt.payload) {
+				t.Errorf("Encode = % X\nwant     % X", payload, tt.payload)
+			}
+
+			doc, err := lrrp.Decode(tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, ok := doc.(*lrrp.Response); !ok || !reflect.DeepEqual(got, tt.resp) {
+				t.Errorf("Decode = %s\nwant     %s", doc.ToString(), tt.resp.ToString())
+			}
+		})
+	}
+}
+
+func TestDecode_ResponseUnknownToken(t *testing.T) {
+	t.Parallel()
+	// A position followed by a token this package does not know.
+	payload := []byte{
+		0x07, 0x0B,
+		0x51, 0x40, 0x00, 0x00, 0x00, 0xC0, 0x00, 0x00, 0x00,
+		0x62, 0x01,
+	}
+	doc, err := lrrp.Decode(payload)
+	testutil.AssertPDUError(t, err, elements.ErrNotImplemented, elements.LayerApplication, "Token[0x62]")
+	resp, ok := doc.(*lrrp.Response)
+	if !ok {
+		t.Fatalf("Decode = %T, want the response read before the token", doc)
+	}
+	want := &lrrp.Position{Latitude: 45, Longitude: -90}
+	if !reflect.DeepEqual(resp.Position, want) {
+		t.Errorf("Position = %+v, want %+v", resp.Position, want)
+	}
+}
+
+func TestResponse_Coordinates(t *testing.T) {
+	t.Parallel()
+	// One step is 90/2^31 degrees of latitude and 180/2^31 of longitude.
+	const step = 180.0 / (1 << 31)
+	tests := []struct {
+		name                string
+		latitude, longitude float64
+	}{
+		{"Dallas", 32.7767, -96.7970},
+		{"Sydney", -33.8688, 151.2093},
+		{"north pole", 90, 0},
+		{"antimeridian", -90, 180},
+		{"west antimeridian", 0, -180},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			resp := &lrrp.Response{
+				Type:     lrrp.TypeImmediateResponse,
+				Position: &lrrp.Position{Latitude: tt.latitude, Longitude: tt.longitude},
+			}
+			payload, err := resp.Encode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := lrrp.Decode(payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p := doc.(*lrrp.Response).Position
+			if math.Abs(p.Latitude-tt.latitude) > step || math.Abs(p.Longitude-tt.longitude) > step {
+				t.Errorf("Position = %f, %f, want %f, %f", p.Latitude, p.Longitude, tt.latitude, tt.longitude)
+			}
+		})
+	}
+}
+
+func TestResponse_Encode_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		resp     *lrrp.Response
+		sentinel error
+		field    string
+	}{
+		{"request type", &lrrp.Response{Type: lrrp.TypeImmediateRequest}, elements.ErrDataTypeMismatch, "Type"},
+		{"latitude", &lrrp.Response{Type: lrrp.TypeImmediateResponse, Position: &lrrp.Position{Latitude: 90.5}}, elements.ErrInvalidEncoding, "Latitude"},
+		{"longitude", &lrrp.Response{Type: lrrp.TypeImmediateResponse, Position: &lrrp.Position{Longitude: math.NaN()}}, elements.ErrInvalidEncoding, "Longitude"},
+		{"radius", &lrrp.Response{Type: lrrp.TypeImmediateResponse, Position: &lrrp.Position{Radius: -1}}, elements.ErrInvalidEncoding, "Radius"},
+		{"speed", &lrrp.Response{Type: lrrp.TypeImmediateResponse, Speed: -1, HasSpeed: true}, elements.ErrInvalidEncoding, "Speed"},
+		{"direction", &lrrp.Response{Type: lrrp.TypeImmediateResponse, Direction: 360, HasDirection: true}, elements.ErrInvalidEncoding, "Direction"},
+		{"year", &lrrp.Response{Type: lrrp.TypeImmediateResponse, Time: time.Date(20000, time.January, 1, 0, 0, 0, 0, time.UTC)}, elements.ErrInvalidEncoding, "Time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.resp.Encode()
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerApplication, tt.field)
+		})
+	}
+}
@@ -0,0 +1,297 @@
+// Package tms decodes and encodes Motorola text messages (TMS), carried as
+// UDP datagrams to port 4007 over DMR packet data.
+package tms
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/USA-RedDragon/dmrgo/v2/internal/utf16text"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+)
+
+const (
+	// Port is the UDP port of the text message service.
+	Port = 4007
+	// MaxSequenceNumber is the largest 7-bit sequence number.
+	MaxSequenceNumber = 0x7F
+)
+
+// Type is the PDU type of a message, from the low 5 bits of its first
+// header octet.
+type Type uint8
+
+const (
+	// TypeText is a text message.
+	TypeText Type = 0x00
+	// TypeAck acknowledges the text message with the same sequence number.
+	TypeAck Type = 0x1F
+)
+
+// Encoding is the text encoding of a message, from the low 5 bits of its
+// second header octet.
+type Encoding uint8
+
+const (
+	// EncodingUTF16LE is UTF-16, little endian, as sent by Motorola radios.
+	EncodingUTF16LE Encoding = 0x05
+)
+
+// Header layout. Each header octet has an extension bit announcing the
+// next one; the sequence number is split across the second and third.
+const (
+	lengthOctets = 2
+
+	header1AckRequired = 0x80
+	header1Control     = 0x40
+	header1Extension   = 0x20
+	header1TypeMask    = 0x1F
+
+	header2Extension    = 0x80
+	header2SequenceMask = 0x60
+	header2EncodingMask = 0x1F
+
+	header3Extension    = 0x80
+	header3SequenceMask = 0x7C
+
+	// sequenceLowBits is the number of sequence number bits carried in the
+	// third header octet.
+	sequenceLowBits = 5
+
+	maxAddressOctets = 0xFF
+	maxLengthOctets  = 0xFFFF
+)
+
+// textPrefix starts the text of every message Motorola radios send; it is
+// removed when decoding and added when encoding.
+const textPrefix = "\r\n"
+
+// Message is a text message or acknowledgement.
+type Message struct {
+	// Source is the sender's radio ID and Destination the radio ID or
+	// talkgroup the datagram is addressed to, from its IPv4 addresses.
+	Source      int
+	Destination int
+	// Group reports a message addressed to a talkgroup.
+	Group bool
+	Type  Type
+	// AckRequired asks the destination to acknowledge the message.
+	AckRequired bool
+	// Control is the control flag of the first header octet, carried as
+	// received.
+	Control bool
+	// SequenceNumber matches an acknowledgement to its message.
+	SequenceNumber uint8
+	// Encoding is the encoding of Text. Only EncodingUTF16LE is supported.
+	Encoding Encoding
+	// Address is the address field, empty for messages between radios.
+	Address []byte
+	// Text is the message text, empty for an acknowledgement.
+	Text string
+}
+
+// NewText returns a text message from source to an individual destination
+// that asks to be acknowledged.
+func NewText(source, destination int, sequenceNumber uint8, text string) *Message {
+	return &Message{
+		Source:         source,
+		Destination:    destination,
+		Type:           TypeText,
+		AckRequired:    true,
+		SequenceNumber: sequenceNumber,
+		Encoding:       EncodingUTF16LE,
+		Text:           text,
+	}
+}
+
+// ToString returns a string representation of the message.
+func (m *Message) ToString() string {
+	return fmt.Sprintf("Message{ Source: %d, Destination: %d, Group: %t, Type: %d, AckRequired: %t, Control: %t, SequenceNumber: %d, Encoding: %d, Address: % X, Text: %q }",
+		m.Source, m.Destination, m.Group, m.Type, m.AckRequired, m.Control, m.SequenceNumber, m.Encoding, m.Address, m.Text)
+}
+
+// Ack returns the acknowledgement of the message, sent back to its source.
+func (m *Message) Ack() *Message {
+	return &Message{
+		Source:         m.Destination,
+		Destination:    m.Source,
+		Type:           TypeAck,
+		SequenceNumber: m.SequenceNumber,
+	}
+}
+
+// DecodeDatagram returns the message carried by a UDP/IPv4 datagram to
+// Port, taking the radio IDs from its addresses on the networks of
+// addresses.
+//
+// Errors are an *elements.PDUError: as for
+// IPv4AddressContext.DecodeRadioDatagram, or as for Decode.
+func DecodeDatagram(datagram []byte, addresses *layer2.IPv4AddressContext) (*Message, error) {
+	d, err := addresses.DecodeRadioDatagram(datagram, Port)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Decode(d.Payload)
+	if err != nil {
+		return nil, err
+	}
+	m.Source = d.Source
+	m.Destination = d.Destination
+	m.Group = d.Group
+	return m, nil
+}
+
+// Decode returns the message carried by a UDP payload, with Source and
+// Destination zero.
+//
+// Errors are an *elements.PDUError wrapping elements.ErrInvalidLength for a
+// truncated message, elements.ErrInvalidEncoding for text that is not
+// whole UTF-16 code units, or elements.ErrNotImplemented for another text
+// encoding.
+func Decode(payload []byte) (*Message, error) {
+	if len(payload) < lengthOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	n := int(binary.BigEndian.Uint16(payload))
+	if n == 0 || lengthOctets+n > len(payload) {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Length", Err: elements.ErrInvalidLength}
+	}
+	data := payload[lengthOctets : lengthOctets+n]
+
+	h := data[0]
+	m := &Message{
+		Type:        Type(h & header1TypeMask),
+		AckRequired: h&header1AckRequired != 0,
+		Control:     h&header1Control != 0,
+		Encoding:    EncodingUTF16LE,
+	}
+	extended := h&header1Extension != 0
+	data = data[1:]
+
+	if len(data) < 1 || 1+int(data[0]) > len(data) {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Address", Err: elements.ErrInvalidLength}
+	}
+	if a := int(data[0]); a > 0 {
+		m.Address = append([]byte(nil), data[1:1+a]...)
+	}
+	data = data[1+int(data[0]):]
+
+	if extended {
+		if len(data) < 1 {
+			return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Header", Err: elements.ErrInvalidLength}
+		}
+		h = data[0]
+		m.SequenceNumber = (h & header2SequenceMask) >> 5 << sequenceLowBits
+		m.Encoding = Encoding(h & header2EncodingMask)
+		extended = h&header2Extension != 0
+		data = data[1:]
+	}
+	if extended {
+		if len(data) < 1 {
+			return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Header", Err: elements.ErrInvalidLength}
+		}
+		h = data[0]
+		m.SequenceNumber |= (h & header3SequenceMask) >> 2
+		extended = h&header3Extension != 0
+		data = data[1:]
+	}
+	// Further header octets are not defined; skip them.
+	for extended {
+		if len(data) < 1 {
+			return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Header", Err: elements.ErrInvalidLength}
+		}
+		extended = data[0]&header3Extension != 0
+		data = data[1:]
+	}
+
+	if m.Type == TypeAck {
+		return m, nil
+	}
+	if m.Encoding != EncodingUTF16LE {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Encoding", Err: elements.ErrNotImplemented}
+	}
+	text, err := decodeUTF16LE(data)
+	if err != nil {
+		return nil, err
+	}
+	m.Text = text
+	return m, nil
+}
+
+// Encode returns the UDP payload that carries the message. A sequence
+// number above 127 or text in another encoding returns an
+// *elements.PDUError wrapping elements.ErrInvalidEncoding or
+// elements.ErrNotImplemented, and an address or text too long for the
+// message one wrapping elements.ErrInvalidLength.
+func (m *Message) Encode() ([]byte, error) {
+	switch {
+	case m.SequenceNumber > MaxSequenceNumber:
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "SequenceNumber", Err: elements.ErrInvalidEncoding}
+	case uint8(m.Type) > header1TypeMask:
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Type", Err: elements.ErrInvalidEncoding}
+	case len(m.Address) > maxAddressOctets:
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Address", Err: elements.ErrInvalidLength}
+	}
+
+	var text []byte
+	encoding := m.Encoding
+	if m.Type != TypeAck {
+		if encoding != EncodingUTF16LE {
+			return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Encoding", Err: elements.ErrNotImplemented}
+		}
+		text = encodeUTF16LE(m.Text)
+	}
+
+	h1 := byte(m.Type) | header1Extension
+	if m.AckRequired {
+		h1 |= header1AckRequired
+	}
+	if m.Control {
+		h1 |= header1Control
+	}
+	h2 := header2Extension | m.SequenceNumber>>sequenceLowBits<<5 | byte(encoding)&header2EncodingMask
+	h3 := m.SequenceNumber << 2 & header3SequenceMask
+
+	n := 1 + 1 + len(m.Address) + 2 + len(text)
+	if n > maxLengthOctets {
+		return nil, &elements.PDUError{Layer: elements.LayerApplication, Field: "Text", Err: elements.ErrInvalidLength}
+	}
+	payload := make([]byte, 0, lengthOctets+n)
+	payload = binary.BigEndian.AppendUint16(payload, uint16(n)) //nolint:gosec // checked against maxLengthOctets
+	payload = append(payload, h1, byte(len(m.Address)))
+	payload = append(payload, m.Address...)
+	payload = append(payload, h2, h3)
+	return append(payload, text...), nil
+}
+
+// Datagram returns the UDP/IPv4 datagram that carries the message from
+// Source to Destination on the networks of addresses, between Port at both
+// ends, with the IPv4 identification given. Errors are as for Encode and
+// IPv4AddressContext.EncodeRadioDatagram.
+func (m *Message) Datagram(addresses *layer2.IPv4AddressContext, identification uint16) ([]byte, error) {
+	payload, err := m.Encode()
+	if err != nil {
+		return nil, err
+	}
+	d := layer2.RadioDatagram{Source: m.Source, Destination: m.Destination, Group: m.Group, Payload: payload}
+	return addresses.EncodeRadioDatagram(&d, Port, identification)
+}
+
+// decodeUTF16LE returns the text of a message, without the prefix Motorola
+// radios add and the NUL terminator.
+func decodeUTF16LE(data []byte) (string, error) {
+	text, ok := utf16text.DecodeTerminated(data, binary.LittleEndian)
+	if !ok {
+		return "", &elements.PDUError{Layer: elements.LayerApplication, Field: "Text", Err: elements.ErrInvalidEncoding}
+	}
+	return strings.TrimPrefix(text, textPrefix), nil
+}
+
+// encodeUTF16LE returns the text of a message with the prefix and NUL
+// terminator Motorola radios send.
+func encodeUTF16LE(text string) []byte {
+	data := utf16text.Append(nil, textPrefix+text, binary.LittleEndian)
+	return append(data, 0, 0)
+}
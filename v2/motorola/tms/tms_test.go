@@ -0,0 +1,222 @@
+package tms_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/USA-RedDragon/dmrgo/v2/internal/testutil"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2"
+	"github.com/USA-RedDragon/dmrgo/v2/layer2/elements"
+	"github.com/USA-RedDragon/dmrgo/v2/motorola/tms"
+)
+
+// captureDatagram returns the IPv4 datagram reassembled from a capture of
+// 33-byte bursts.
+func captureDatagram(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read file %s: %v", path, err)
+	}
+	var a layer2.UnconfirmedDataAssembler
+	var msgs []*layer2.PacketData
+	for i := 0; i+33 <= len(data); i += 33 {
+		var raw [33]byte
+		copy(raw[:], data[i:])
+		burst, err := layer2.NewBurstFromBytes(raw)
+		if err != nil {
+			t.Fatalf("burst %d: %v", i/33, err)
+		}
+		msg, err := a.AddBurst(burst, time.Unix(0, 0))
+		if err != nil {
+			t.Fatalf("burst %d: %v", i/33, err)
+		}
+		if msg != nil {
+			msgs = append(msgs, msg)
+		}
+	}
+	if len(msgs) != 1 {
+		t.Fatalf("%s: reassembled %d messages, want 1", path, len(msgs))
+	}
+	return msgs[0].Payload
+}
+
+func TestDecodeDatagram_Capture(t *testing.T) {
+	t.Parallel()
+	datagram := captureDatagram(t, "../../layer2/testdata/m-sms.bin")
+	var addresses layer2.IPv4AddressContext
+
+	m, err := tms.DecodeDatagram(datagram, &addresses)
+	if err != nil {
+		t.Fatalf("DecodeDatagram: %v", err)
+	}
+	want := &tms.Message{
+		Source:         3191868,
+		Destination:    0x7A3906,
+		Type:           tms.TypeText,
+		AckRequired:    true,
+		SequenceNumber: 1,
+		Encoding:       tms.EncodingUTF16LE,
+		Text:           "TEST KI5VMF",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Fatalf("got %s\nwant %s", m.ToString(), want.ToString())
+	}
+
+	d, err := layer2.ParseUDPDatagram(datagram)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := m.Encode()
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	if !bytes.Equal(payload, d.Payload) {
+		t.Errorf("Encode = % X\nwant     % X", payload, d.Payload)
+	}
+	out, err := m.Datagram(&addresses, d.Identification)
+	if err != nil {
+		t.Fatalf("Datagram: %v", err)
+	}
+	if !bytes.Equal(out, datagram) {
+		t.Errorf("Datagram = % X\nwant       % X", out, datagram)
+	}
+}
+
+func TestMessage_Ack(t *testing.T) {
+	t.Parallel()
+	m := tms.NewText(3120001, 3120002, 100, "hi")
+	ack := m.Ack()
+	want := &tms.Message{Source: 3120002, Destination: 3120001, Type: tms.TypeAck, SequenceNumber: 100}
+	if !reflect.DeepEqual(ack, want) {
+		t.Fatalf("Ack = %s, want %s", ack.ToString(), want.ToString())
+	}
+
+	payload, err := ack.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Sequence number 100 is 11 00100: 11 in the second header octet and
+	// 00100 in the third.
+	if wantPayload := []byte{0x00, 0x04, 0x3F, 0x00, 0xE0, 0x10}; !bytes.Equal(payload, wantPayload) {
+		t.Errorf("Encode = % X, want % X", payload, wantPayload)
+	}
+	got, err := tms.Decode(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Type != tms.TypeAck || got.AckRequired || got.SequenceNumber != 100 || got.Text != "" {
+		t.Errorf("Decode = %s", got.ToString())
+	}
+}
+
+func TestMessage_RoundTrip(t *testing.T) {
+	t.Parallel()
+	var addresses layer2.IPv4AddressContext
+	tests := []struct {
+		name string
+		msg  *tms.Message
+	}{
+		{"individual", tms.NewText(3120001, 3120002, 0, "Hello, world")},
+		{"empty", tms.NewText(3120001, 3120002, tms.MaxSequenceNumber, "")},
+		{"unicode", tms.NewText(3120001, 3120002, 42, "Grüße 📡")},
+		{"group", &tms.Message{
+			Source: 3120001, Destination: 91, Group: true, Type: tms.TypeText,
+			SequenceNumber: 7, Encoding: tms.EncodingUTF16LE, Text: "net check",
+		}},
+		{"address", &tms.Message{
+			Source: 3120001, Destination: 3120002, Type: tms.TypeText, AckRequired: true, Control: true,
+			SequenceNumber: 33, Encoding: tms.EncodingUTF16LE, Address: []byte("5551234"), Text: "relay",
+		}},
+		{"ack", &tms.Message{Source: 3120002, Destination: 3120001, Type: tms.TypeAck, SequenceNumber: 33}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			datagram, err := tt.msg.Datagram(&addresses, 1)
+			if err != nil {
+				t.Fatalf("Datagram: %v", err)
+			}
+			got, err := tms.DecodeDatagram(datagram, &addresses)
+			if err != nil {
+				t.Fatalf("DecodeDatagram: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.msg) {
+				t.Errorf("got %s\nwant %s", got.ToString(), tt.msg.ToString())
+			}
+		})
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		payload  []byte
+		sentinel error
+		field    string
+	}{
+		{"no length", []byte{0x00}, elements.ErrInvalidLength, "Length"},
+		{"zero length", []byte{0x00, 0x00}, elements.ErrInvalidLength, "Length"},
+		{"length past end", []byte{0x00, 0x05, 0xA0, 0x00}, elements.ErrInvalidLength, "Length"},
+		{"no address length", []byte{0x00, 0x01, 0xA0}, elements.ErrInvalidLength, "Address"},
+		{"address past end", []byte{0x00, 0x03, 0xA0, 0x02, 0x31}, elements.ErrInvalidLength, "Address"},
+		{"no second header", []byte{0x00, 0x02, 0xA0, 0x00}, elements.ErrInvalidLength, "Header"},
+		{"no third header", []byte{0x00, 0x03, 0xA0, 0x00, 0x85}, elements.ErrInvalidLength, "Header"},
+		{"odd text", []byte{0x00, 0x05, 0xA0, 0x00, 0x85, 0x04, 0x41}, elements.ErrInvalidEncoding, "Text"},
+		{"other encoding", []byte{0x00, 0x06, 0xA0, 0x00, 0x81, 0x04, 0x41, 0x00}, elements.ErrNotImplemented, "Encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tms.Decode(tt.payload)
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerApplication, tt.field)
+		})
+	}
+}
+
+func TestDecodeDatagram_Errors(t *testing.T) {
+	t.Parallel()
+	var addresses layer2.IPv4AddressContext
+	m := tms.NewText(3120001, 3120002, 1, "hi")
+	datagram, err := m.Datagram(&addresses, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Move the destination port off 4007; the UDP checksum is not checked.
+	wrongPort := append([]byte{}, datagram...)
+	wrongPort[23]++
+	_, err = tms.DecodeDatagram(wrongPort, &addresses)
+	testutil.AssertPDUError(t, err, elements.ErrDataTypeMismatch, elements.LayerApplication, "Port")
+
+	_, err = tms.DecodeDatagram(datagram[:10], &addresses)
+	if !errors.Is(err, elements.ErrInvalidLength) {
+		t.Errorf("truncated datagram: error = %v, want %v", err, elements.ErrInvalidLength)
+	}
+}
+
+func TestMessage_EncodeErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		msg      *tms.Message
+		sentinel error
+		field    string
+	}{
+		{"sequence number", tms.NewText(1, 2, tms.MaxSequenceNumber+1, "hi"), elements.ErrInvalidEncoding, "SequenceNumber"},
+		{"type", &tms.Message{Type: 0x20, Encoding: tms.EncodingUTF16LE}, elements.ErrInvalidEncoding, "Type"},
+		{"address", &tms.Message{Encoding: tms.EncodingUTF16LE, Address: make([]byte, 256)}, elements.ErrInvalidLength, "Address"},
+		{"encoding", &tms.Message{Type: tms.TypeText, Text: "hi"}, elements.ErrNotImplemented, "Encoding"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.msg.Encode()
+			testutil.AssertPDUError(t, err, tt.sentinel, elements.LayerApplication, tt.field)
+		})
+	}
+}